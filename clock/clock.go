@@ -0,0 +1,227 @@
+// Package clock is the source of time shared by ds and exbo, the time package's by default, or a
+// Manual clock moved by hand in tests.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a source of time, timers and tickers.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a time.Timer made by a Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a time.Ticker made by a Clock.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the Clock of the time package.
+type Real struct{}
+
+func (Real) Now() time.Time { return time.Now() }
+
+func (Real) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+func (Real) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+func (Real) AfterFunc(d time.Duration, f func()) Timer { return realTimer{time.AfterFunc(d, f)} }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// Manual is a Clock whose time only moves when Advance is called, firing the timers and
+// tickers due on the way in order, so tests need not sleep.
+type Manual struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+// NewManual returns a Manual clock reading start.
+func NewManual(start time.Time) *Manual {
+	return &Manual{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *Manual) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d, firing whatever falls due.
+func (c *Manual) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+
+	for {
+		t := c.next(end)
+		if t == nil {
+			break
+		}
+
+		c.now = t.when
+		now := c.now
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			t.active = false
+		}
+
+		c.mu.Unlock()
+		t.fire(now)
+		c.mu.Lock()
+	}
+
+	c.now = end
+	c.mu.Unlock()
+}
+
+// next returns the earliest active timer due by end. The caller must hold mu.
+func (c *Manual) next(end time.Time) *manualTimer {
+	live := c.timers[:0]
+	for _, t := range c.timers {
+		if t.active {
+			live = append(live, t)
+		}
+	}
+	c.timers = live
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+
+	if len(c.timers) == 0 || c.timers[0].when.After(end) {
+		return nil
+	}
+
+	return c.timers[0]
+}
+
+// Waiters reports how many timers and tickers are waiting on the clock, so a test can tell when
+// the code under test has armed the ones it expects before advancing.
+func (c *Manual) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, t := range c.timers {
+		if t.active {
+			n++
+		}
+	}
+
+	return n
+}
+
+// NewTimer returns a Timer firing once the clock has advanced by d.
+func (c *Manual) NewTimer(d time.Duration) Timer {
+	return c.start(d, 0, nil)
+}
+
+// NewTicker returns a Ticker firing each time the clock advances by a further d.
+func (c *Manual) NewTicker(d time.Duration) Ticker {
+	return manualTicker{c.start(d, d, nil)}
+}
+
+// AfterFunc calls f in a goroutine of its own once the clock has advanced by d.
+func (c *Manual) AfterFunc(d time.Duration, f func()) Timer {
+	return c.start(d, 0, f)
+}
+
+func (c *Manual) start(d, period time.Duration, f func()) *manualTimer {
+	t := &manualTimer{clock: c, c: make(chan time.Time, 1), period: period, f: f}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.when, t.active = c.now.Add(d), true
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+type manualTimer struct {
+	clock  *Manual
+	c      chan time.Time
+	period time.Duration
+	f      func()
+
+	// when and active are guarded by the clock's mu.
+	when   time.Time
+	active bool
+}
+
+// fire delivers now as time.Timer does: to f, or to the channel unless a tick is already waiting.
+func (t *manualTimer) fire(now time.Time) {
+	if t.f != nil {
+		go t.f()
+		return
+	}
+
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+type manualTicker struct{ *manualTimer }
+
+func (t manualTicker) Stop() { t.manualTimer.Stop() }
+
+// drain discards a tick not yet received, as a stopped or reset time.Timer does.
+func (t *manualTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	t.active = false
+	t.drain()
+	return wasActive
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	t.when, t.active = t.clock.now.Add(d), true
+	t.drain()
+	for _, waiting := range t.clock.timers {
+		if waiting == t {
+			return wasActive
+		}
+	}
+
+	t.clock.timers = append(t.clock.timers, t)
+	return wasActive
+}
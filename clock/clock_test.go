@@ -0,0 +1,98 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManual(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManual(start)
+
+	timer := clock.NewTimer(time.Second)
+	ticker := clock.NewTicker(time.Second)
+	called := make(chan struct{})
+	clock.AfterFunc(2*time.Second, func() { close(called) })
+
+	if clock.Waiters() != 3 {
+		t.Errorf("Expected 3 waiters, found %d", clock.Waiters())
+	}
+
+	clock.Advance(time.Second / 2)
+	select {
+	case <-timer.C():
+		t.Errorf("Timer fired early.")
+	default:
+	}
+
+	clock.Advance(time.Second / 2)
+	if now := <-timer.C(); !now.Equal(start.Add(time.Second)) {
+		t.Errorf("Expected the timer to pass its due time, passed %s", now)
+	}
+	<-ticker.C()
+
+	clock.Advance(time.Second)
+	<-ticker.C()
+	<-called
+
+	if timer.Reset(time.Second) {
+		t.Errorf("Expected a fired timer to be inactive.")
+	}
+	if !timer.Stop() {
+		t.Errorf("Expected a reset timer to be active.")
+	}
+
+	ticker.Stop()
+	clock.Advance(time.Minute)
+	select {
+	case <-timer.C():
+		t.Errorf("Stopped timer fired.")
+	case <-ticker.C():
+		t.Errorf("Stopped ticker ticked.")
+	default:
+	}
+
+	if !clock.Now().Equal(start.Add(time.Minute + 2*time.Second)) {
+		t.Errorf("Unexpected time %s", clock.Now())
+	}
+}
+
+func TestManualReset(t *testing.T) {
+	start := time.Now()
+	clock := NewManual(start)
+
+	early, late := clock.NewTimer(time.Second), clock.NewTimer(time.Minute)
+	if clock.Waiters() != 2 {
+		t.Errorf("Expected 2 waiters, found %d", clock.Waiters())
+	}
+
+	clock.Advance(2 * time.Second)
+	select {
+	case at := <-early.C():
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("Expected the timer to fire when due, fired at %s", at.Sub(start))
+		}
+	default:
+		t.Errorf("Expected the due timer to fire")
+	}
+
+	select {
+	case <-late.C():
+		t.Errorf("Expected the later timer not to fire")
+	default:
+	}
+
+	if !late.Stop() || clock.Waiters() != 0 {
+		t.Errorf("Expected the later timer stopped")
+	}
+
+	early.Reset(time.Second)
+	clock.Advance(time.Hour)
+	if at := <-early.C(); !at.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("Expected the reset timer to fire a second on, fired at %s", at.Sub(start))
+	}
+
+	if !clock.Now().Equal(start.Add(time.Hour + 2*time.Second)) {
+		t.Errorf("Unexpected time after advancing, %s", clock.Now().Sub(start))
+	}
+}
@@ -0,0 +1,109 @@
+// conquerctl renders the live state of a DynamicSelect served with ListenInspector.
+//
+//	conquerctl -socket /tmp/app.sock -interval 1s
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/krhoda/goconquer/ds"
+)
+
+func main() {
+	socket := flag.String("socket", "", "path of the unix socket the select is served on")
+	interval := flag.Duration("interval", time.Second, "time between refreshes")
+	once := flag.Bool("once", false, "print the state once and exit")
+	flag.Parse()
+
+	if *socket == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	last, err := fetch(*socket)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *once {
+		render(last, nil, *interval)
+		return
+	}
+
+	for {
+		time.Sleep(*interval)
+
+		s, err := fetch(*socket)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Clear the screen before redrawing.
+		fmt.Print("\033[H\033[2J")
+		render(s, &last, *interval)
+		last = s
+	}
+}
+
+func fetch(socket string) (ds.State, error) {
+	s := ds.State{}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return s, fmt.Errorf("could not reach select at %s: %w", socket, err)
+	}
+	defer conn.Close()
+
+	if err := json.NewDecoder(conn).Decode(&s); err != nil {
+		return s, fmt.Errorf("could not read select state: %w", err)
+	}
+
+	return s, nil
+}
+
+// render prints s, with throughput worked out against the previous state when there is one.
+func render(s ds.State, previous *ds.State, interval time.Duration) {
+	fmt.Printf("alive: %t  running: %t  goroutines: %d  buffered: %d  entries: %d\n",
+		s.Alive, s.Running, s.Footprint.Goroutines, s.Footprint.BufferedMessages, s.Footprint.Entries)
+	fmt.Printf("lifecycle: %s  tier: %s  pending loads: %d\n\n", s.Lifecycle, s.Tier, s.PendingLoads)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ENTRY\tSTATE\tMODE\tQUEUE\tHANDLING\tHANDLED\tMSG/S")
+
+	for _, e := range s.Entries {
+		state := "open"
+		if e.Closed {
+			state = "closed"
+		}
+
+		mode := "non-blocking"
+		if e.Blocking && e.Level > 0 {
+			mode = fmt.Sprintf("priority %d", e.Level)
+		} else if e.Blocking {
+			mode = "blocking"
+		}
+
+		rate := "-"
+		if previous != nil && e.Index < len(previous.Entries) {
+			handled := e.Handled - previous.Entries[e.Index].Handled
+			rate = fmt.Sprintf("%.1f", float64(handled)/interval.Seconds())
+		}
+
+		entry := strconv.Itoa(e.Index)
+		if e.Name != "" {
+			entry = e.Name
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%d\t%d\t%s\n", entry, state, mode, e.Buffered, e.Capacity, e.Handling, e.Handled, rate)
+	}
+
+	w.Flush()
+}
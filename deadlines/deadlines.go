@@ -0,0 +1,203 @@
+package deadlines
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Manager tracks deadlines for many keys on a single timer, sending each key on C once its deadline passes.
+// C is a chan interface{} so it can be loaded into a DynamicSelect as a ChannelEntry's Channel.
+// Stop does not close C.
+type Manager struct {
+	C chan interface{}
+
+	mu    sync.Mutex
+	items map[interface{}]*deadline
+	queue deadlineHeap
+
+	// wake tells Run the earliest deadline may have changed.
+	wake chan struct{}
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+type deadline struct {
+	key   interface{}
+	at    time.Time
+	index int
+}
+
+// New creates a Manager whose expiration channel is buffered by buffer.
+func New(buffer int) *Manager {
+	return &Manager{
+		C:     make(chan interface{}, buffer),
+		items: map[interface{}]*deadline{},
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+}
+
+// Run waits on the earliest deadline until Stop is called.
+// Expired keys are sent on C in deadline order; while C is full, later expirations wait.
+func (m *Manager) Run() {
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+
+	for {
+		var fire <-chan time.Time
+
+		m.mu.Lock()
+		if len(m.queue) > 0 {
+			timer.Reset(time.Until(m.queue[0].at))
+			fire = timer.C
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-m.done:
+			return
+		case <-m.wake:
+			timer.Stop()
+		case <-fire:
+			for _, key := range m.expired() {
+				select {
+				case <-m.done:
+					return
+				case m.C <- key:
+				}
+			}
+		}
+	}
+}
+
+// expired removes and returns the keys whose deadlines have passed.
+func (m *Manager) expired() []interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	keys := []interface{}{}
+	for len(m.queue) > 0 && !m.queue[0].at.After(now) {
+		d := heap.Pop(&m.queue).(*deadline)
+		delete(m.items, d.key)
+		keys = append(keys, d.key)
+	}
+
+	return keys
+}
+
+// Stop halts Run, after which keys still tracked never expire.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.done)
+	})
+}
+
+// Track sets the deadline of key to at, replacing any it already had.
+func (m *Manager) Track(key interface{}, at time.Time) {
+	m.mu.Lock()
+	if d, ok := m.items[key]; ok {
+		d.at = at
+		heap.Fix(&m.queue, d.index)
+	} else {
+		d = &deadline{key: key, at: at}
+		m.items[key] = d
+		heap.Push(&m.queue, d)
+	}
+	m.mu.Unlock()
+
+	m.signal()
+}
+
+// Extend moves the deadline of key later by by, reporting false if key is not tracked.
+func (m *Manager) Extend(key interface{}, by time.Duration) bool {
+	m.mu.Lock()
+	d, ok := m.items[key]
+	if ok {
+		d.at = d.at.Add(by)
+		heap.Fix(&m.queue, d.index)
+	}
+	m.mu.Unlock()
+
+	if ok {
+		m.signal()
+	}
+
+	return ok
+}
+
+// Cancel stops tracking key, reporting false if it was not tracked.
+// A key already sent on C cannot be cancelled.
+func (m *Manager) Cancel(key interface{}) bool {
+	m.mu.Lock()
+	d, ok := m.items[key]
+	if ok {
+		heap.Remove(&m.queue, d.index)
+		delete(m.items, key)
+	}
+	m.mu.Unlock()
+
+	if ok {
+		m.signal()
+	}
+
+	return ok
+}
+
+// Deadline reports the deadline of key, if tracked.
+func (m *Manager) Deadline(key interface{}) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, ok := m.items[key]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return d.at, true
+}
+
+// Len reports the number of keys tracked.
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.queue)
+}
+
+func (m *Manager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deadlineHeap orders deadlines earliest first, implementing heap.Interface.
+type deadlineHeap []*deadline
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h deadlineHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *deadlineHeap) Push(x interface{}) {
+	d := x.(*deadline)
+	d.index = len(*h)
+	*h = append(*h, d)
+}
+
+func (h *deadlineHeap) Pop() interface{} {
+	old := *h
+	d := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return d
+}
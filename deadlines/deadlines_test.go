@@ -0,0 +1,82 @@
+package deadlines
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeadlinesOrder(t *testing.T) {
+	m := New(3)
+	go m.Run()
+	defer m.Stop()
+
+	now := time.Now()
+	m.Track("c", now.Add(60*time.Millisecond))
+	m.Track("a", now.Add(20*time.Millisecond))
+	m.Track("b", now.Add(40*time.Millisecond))
+
+	for _, expected := range []string{"a", "b", "c"} {
+		select {
+		case key := <-m.C:
+			if key != expected {
+				t.Errorf("Expected %s to expire, found %v", expected, key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Deadline for %s never expired", expected)
+		}
+	}
+
+	if m.Len() != 0 {
+		t.Errorf("Expired deadlines are still tracked.")
+	}
+}
+
+func TestDeadlinesCancelExtend(t *testing.T) {
+	m := New(2)
+	go m.Run()
+	defer m.Stop()
+
+	now := time.Now()
+	m.Track("cancelled", now.Add(20*time.Millisecond))
+	m.Track("extended", now.Add(30*time.Millisecond))
+	m.Track("kept", now.Add(50*time.Millisecond))
+
+	if !m.Cancel("cancelled") {
+		t.Errorf("Could not cancel a tracked key.")
+	}
+
+	if !m.Extend("extended", 50*time.Millisecond) {
+		t.Errorf("Could not extend a tracked key.")
+	}
+
+	if m.Cancel("missing") || m.Extend("missing", time.Second) {
+		t.Errorf("Untracked keys reported as tracked.")
+	}
+
+	for _, expected := range []string{"kept", "extended"} {
+		select {
+		case key := <-m.C:
+			if key != expected {
+				t.Errorf("Expected %s to expire, found %v", expected, key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Deadline for %s never expired", expected)
+		}
+	}
+
+	select {
+	case key := <-m.C:
+		t.Errorf("Unexpected expiration of %v", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func BenchmarkTrack(b *testing.B) {
+	m := New(0)
+	at := time.Now().Add(time.Hour)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.Track(i, at)
+	}
+}
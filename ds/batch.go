@@ -0,0 +1,111 @@
+package ds
+
+import (
+	"time"
+)
+
+// BatchHandlerEntry is the batching counterpart of HandlerEntry, see HandlerEntry for the
+// meaning of Blocking, Priority and Level.
+type BatchHandlerEntry struct {
+	Func func(batch []interface{})
+
+	// Size is the most messages in a batch, FlushInterval the longest the first message of a
+	// batch waits for the rest. A batch is delivered once either is reached. At least one is needed.
+	Size          int
+	FlushInterval time.Duration
+
+	Blocking bool
+	Priority bool
+	Level    int
+}
+
+// batching holds the limits of an entry built by Batched.
+type batching struct {
+	size     int
+	interval time.Duration
+}
+
+// Batched builds a ChannelEntry whose handler receives the messages heard on c in batches,
+// amortizing the cost of a handler call over many messages. A partial batch is delivered when c
+// closes, and discarded if the select halts. The entry's Credit, if any, is released once per batch.
+// A nil handler Func discards messages.
+func Batched(c chan interface{}, handler BatchHandlerEntry, onClose OnCloseEntry) ChannelEntry {
+	if handler.Func == nil {
+		handler.Func = func(batch []interface{}) {}
+	}
+
+	return ChannelEntry{
+		Channel: c,
+		Handler: HandlerEntry{
+			Func: func(i interface{}) {
+				handler.Func(i.([]interface{}))
+			},
+			Blocking: handler.Blocking,
+			Priority: handler.Priority,
+			Level:    handler.Level,
+		},
+		OnClose: onClose,
+		batch:   &batching{size: handler.Size, interval: handler.FlushInterval},
+	}
+}
+
+// listenBatched listens to the batched entry at index i until its channel closes or the select halts,
+// reporting whether the channel closed.
+func (d *DynamicSelect) listenBatched(i int, e ChannelEntry) bool {
+	b := e.batch
+	pending := []interface{}{}
+
+	// flush is only set while a partial batch waits on the timer.
+	var timer Timer
+	var flush <-chan time.Time
+	if b.interval > 0 {
+		timer = d.clock.NewTimer(b.interval)
+		timer.Stop()
+		defer timer.Stop()
+	}
+
+	deliver := func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		flush = nil
+
+		if len(pending) > 0 {
+			d.route(i, e, pending)
+			pending = make([]interface{}, 0, len(pending))
+		}
+	}
+
+	for {
+		if !d.IsAlive() {
+			return false
+		}
+
+		select {
+		case <-d.done:
+			return false
+
+		case <-e.stop:
+			return false
+
+		case <-flush:
+			deliver()
+
+		case x, ok := <-e.Channel:
+			if !ok {
+				deliver()
+				return true
+			}
+
+			pending = append(pending, x)
+			if len(pending) == 1 && timer != nil {
+				timer.Reset(b.interval)
+				flush = timer.C()
+			}
+
+			if b.size > 0 && len(pending) >= b.size {
+				deliver()
+			}
+		}
+	}
+}
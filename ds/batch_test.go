@@ -0,0 +1,58 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatched(t *testing.T) {
+	c := make(chan interface{})
+	batches := make(chan []interface{}, 4)
+
+	entry := Batched(c, BatchHandlerEntry{
+		Func:          func(batch []interface{}) { batches <- batch },
+		Size:          3,
+		FlushInterval: time.Second / 20,
+		Blocking:      true,
+	}, OnCloseEntry{})
+
+	batching := NewDynamicSelect(func() {}, []ChannelEntry{entry})
+	batchingReady := make(chan interface{})
+	go batching.Forever(batchingReady)
+	<-batchingReady
+	defer batching.Kill()
+
+	expect := func(size int, why string) {
+		select {
+		case batch := <-batches:
+			if len(batch) != size {
+				t.Errorf("Expected a batch of %d %s, found %d", size, why, len(batch))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a batch %s.", why)
+		}
+	}
+
+	// A full batch is delivered at once.
+	for i := 0; i < 3; i++ {
+		c <- i
+	}
+	expect(3, "once full")
+
+	// A partial one once the first message has waited FlushInterval.
+	c <- 3
+	expect(1, "on the flush interval")
+
+	// And what's left when the channel closes.
+	c <- 4
+	c <- 5
+	close(c)
+	expect(2, "when closed")
+}
+
+func TestBatchedValidate(t *testing.T) {
+	entry := Batched(make(chan interface{}), BatchHandlerEntry{Func: func(batch []interface{}) {}}, OnCloseEntry{})
+	if entry.Validate() == nil {
+		t.Errorf("A batch without a Size or FlushInterval was valid.")
+	}
+}
@@ -0,0 +1,54 @@
+package ds
+
+import (
+	"fmt"
+)
+
+// Broadcast passes msg to the handler of every open entry, as if each had heard it, respecting
+// Blocking and Priority. Entries built by Typed only receive a msg of their type, and entries built
+// by Batched receive it as a batch of one. It suits config reloads or flush signals every consumer
+// must see. Broadcast reports how many handlers msg was passed to. Like Load, it must not be
+// called from a Blocking handler, which would wait on itself.
+func (d *DynamicSelect) Broadcast(msg interface{}) (int, error) {
+	// Counted before checking, so shutting down either waits on this call or it sees the select halted.
+	d.startSend()
+	defer d.endSend()
+
+	if err := d.runningErr(); err != nil {
+		return 0, fmt.Errorf("%w, nothing can be broadcast", err)
+	}
+
+	delivered := 0
+	for i, e := range d.Channels() {
+		if e.IsClosed || e.killed() {
+			continue
+		}
+
+		switch {
+		case e.typed != nil:
+			if !e.typed.route(d, i, e, msg) {
+				continue
+			}
+
+		case e.batch != nil:
+			d.route(i, e, []interface{}{msg})
+
+		default:
+			d.route(i, e, msg)
+		}
+
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// killed reports whether the entry was killed by KillNamed.
+func (e ChannelEntry) killed() bool {
+	select {
+	case <-e.stop:
+		return true
+	default:
+		return false
+	}
+}
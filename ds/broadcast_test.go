@@ -0,0 +1,126 @@
+package ds
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBroadcast(t *testing.T) {
+	heard := make(chan interface{}, 8)
+	record := func(i interface{}) { heard <- i }
+
+	entries := []ChannelEntry{
+		{Channel: make(chan interface{}), Handler: HandlerEntry{Func: record, Blocking: true}},
+		{Channel: make(chan interface{}), Handler: HandlerEntry{Func: record}},
+		{Channel: make(chan interface{}), Handler: HandlerEntry{Func: record, Blocking: true, Priority: true}},
+		Batched(make(chan interface{}), BatchHandlerEntry{Func: func(b []interface{}) { heard <- b[0] }, Size: 4}, OnCloseEntry{}),
+		Typed(make(chan int), TypedHandlerEntry[int]{Func: func(x int) { heard <- x }}, OnCloseEntry{}),
+	}
+
+	broadcasting := NewDynamicSelect(func() {}, entries)
+	broadcastingReady := make(chan interface{})
+	go broadcasting.Forever(broadcastingReady)
+	<-broadcastingReady
+	defer broadcasting.Kill()
+
+	// The typed entry doesn't take strings.
+	n, err := broadcasting.Broadcast("reload")
+	if err != nil || n != 4 {
+		t.Fatalf("Expected the broadcast to reach 4 handlers, reached %d: %v", n, err)
+	}
+
+	for k := 0; k < 4; k++ {
+		select {
+		case msg := <-heard:
+			if msg != "reload" {
+				t.Errorf("Expected reload, heard %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Only %d handlers heard the broadcast.", k)
+		}
+	}
+
+	// Closed entries are skipped.
+	close(entries[0].Channel)
+	time.Sleep(time.Second / 20)
+	if n, _ := broadcasting.Broadcast(1); n != 4 {
+		t.Errorf("Expected the broadcast to reach 4 handlers, reached %d", n)
+	}
+}
+
+func TestBroadcastDuringKill(t *testing.T) {
+	for run := 0; run < 20; run++ {
+		broadcasting := NewDynamicSelect(func() {}, []ChannelEntry{
+			{Channel: make(chan interface{}), Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true}},
+			{Channel: make(chan interface{}), Handler: HandlerEntry{Func: func(i interface{}) {}}},
+			{Channel: make(chan interface{}), Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true, Priority: true}},
+		})
+		broadcastingReady := make(chan interface{})
+		go broadcasting.Forever(broadcastingReady)
+		<-broadcastingReady
+
+		// Broadcasts racing the kill either land or are turned away, never sent on a closed channel.
+		var wg sync.WaitGroup
+		for n := 0; n < 32; n++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					if _, err := broadcasting.Broadcast(n); err != nil {
+						if !errors.Is(err, ErrHalted) && !errors.Is(err, ErrNotRunning) {
+							t.Errorf("Expected the broadcast turned away as halted, got %v", err)
+						}
+						return
+					}
+				}
+			}()
+		}
+
+		time.Sleep(time.Millisecond)
+		broadcasting.Kill()
+		wg.Wait()
+		broadcasting.Wait()
+	}
+}
+
+func TestBroadcastHeldOverKill(t *testing.T) {
+	inside, gate := make(chan struct{}), make(chan struct{})
+	broadcasting := NewDynamicSelect(func() {}, []ChannelEntry{
+		{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+			Transform: func(x interface{}) interface{} {
+				close(inside)
+				<-gate
+				return x
+			},
+		},
+		{Channel: make(chan interface{}), Handler: HandlerEntry{Func: func(i interface{}) {}}},
+	}, WithDrainGrace(time.Minute))
+	broadcastingReady := make(chan interface{})
+	go broadcasting.Forever(broadcastingReady)
+	<-broadcastingReady
+
+	broadcast := make(chan error)
+	go func() {
+		_, err := broadcasting.Broadcast("held")
+		broadcast <- err
+	}()
+	<-inside
+
+	// The select waits on the broadcast under way before closing the channels it routes to.
+	broadcasting.Kill()
+	select {
+	case <-broadcasting.Done():
+		t.Errorf("Expected shutting down to wait on the broadcast under way")
+	case <-time.After(time.Second / 20):
+	}
+
+	close(gate)
+	if err := <-broadcast; err != nil {
+		t.Errorf("Expected the broadcast under way to finish, got %v", err)
+	}
+	broadcasting.Wait()
+}
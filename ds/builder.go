@@ -0,0 +1,175 @@
+package ds
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Builder assembles a DynamicSelect without spelling out ChannelEntry, HandlerEntry and OnCloseEntry
+// literals. Each method returns the Builder, so calls chain:
+//
+//	d, err := ds.NewBuilder().
+//		OnKill(cleanup).
+//		Add(orders, handleOrder, ds.Blocking(), ds.Named("orders")).
+//		AddTicker(time.Minute, flush).
+//		Build()
+type Builder struct {
+	onKill  func()
+	entries []ChannelEntry
+	options []Option
+	tickers []builderTicker
+	errs    []error
+}
+
+type builderTicker struct {
+	interval time.Duration
+	handler  HandlerEntry
+}
+
+// EntryOption configures an entry added through a Builder.
+type EntryOption func(e *ChannelEntry)
+
+// NewBuilder starts a Builder for a select with no entries and nothing to do when killed.
+func NewBuilder() *Builder {
+	return &Builder{onKill: func() {}}
+}
+
+// OnKill sets the action called once the select is killed.
+func (b *Builder) OnKill(f func()) *Builder {
+	b.onKill = f
+	return b
+}
+
+// With adds options for the select, as passed to NewDynamicSelect.
+func (b *Builder) With(opts ...Option) *Builder {
+	b.options = append(b.options, opts...)
+	return b
+}
+
+// Add adds an entry calling handler with each message heard on c, non-Blocking unless opts say otherwise.
+func (b *Builder) Add(c chan interface{}, handler func(i interface{}), opts ...EntryOption) *Builder {
+	return b.AddEntry(ChannelEntry{Channel: c, Handler: HandlerEntry{Func: handler}}, opts...)
+}
+
+// AddEntry adds an entry built some other way, by Typed or Batched say, after applying opts to it.
+func (b *Builder) AddEntry(e ChannelEntry, opts ...EntryOption) *Builder {
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	b.entries = append(b.entries, e)
+	return b
+}
+
+// AddTicker adds an entry whose handler is passed the time every interval, see DynamicSelect.AddTicker.
+// Only the options shaping the handler, Blocking, Priority and Level, apply.
+func (b *Builder) AddTicker(interval time.Duration, handler func(i interface{}), opts ...EntryOption) *Builder {
+	e := ChannelEntry{Channel: make(chan interface{}), Handler: HandlerEntry{Func: handler}}
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	if interval <= 0 {
+		b.errs = append(b.errs, fmt.Errorf("ticker %d: %w, was %s", len(b.tickers), ErrBadInterval, interval))
+	}
+
+	if err := e.Validate(); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("ticker %d: %w", len(b.tickers), err))
+	}
+
+	b.tickers = append(b.tickers, builderTicker{interval: interval, handler: e.Handler})
+	return b
+}
+
+// Build constructs the select, reporting every problem found with the entries added together.
+// Tickers follow the other entries in Channels.
+func (b *Builder) Build() (*DynamicSelect, error) {
+	errs := append([]error(nil), b.errs...)
+	if err := ValidateEntries(b.entries); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	d := NewDynamicSelect(b.onKill, b.entries, b.options...)
+	for _, t := range b.tickers {
+		if err := d.AddTicker(t.interval, t.handler); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// Named names the entry, see ChannelEntry.Name.
+func Named(name string) EntryOption {
+	return func(e *ChannelEntry) {
+		e.Name = name
+	}
+}
+
+// Blocking runs the entry's handler in the main loop, see HandlerEntry.Blocking.
+func Blocking() EntryOption {
+	return func(e *ChannelEntry) {
+		e.Handler.Blocking = true
+	}
+}
+
+// Priority makes the entry's handler Blocking and handles it in the priority tier, see HandlerEntry.Priority.
+func Priority() EntryOption {
+	return func(e *ChannelEntry) {
+		e.Handler.Blocking, e.Handler.Priority = true, true
+	}
+}
+
+// Level makes the entry's handler Blocking at the given priority level, see HandlerEntry.Level.
+func Level(n int) EntryOption {
+	return func(e *ChannelEntry) {
+		e.Handler.Blocking, e.Handler.Level = true, n
+	}
+}
+
+// OnClose calls f once the entry's channel closes, in the main loop if blocking is set, see OnCloseEntry.
+func OnClose(f func(), blocking bool) EntryOption {
+	return func(e *ChannelEntry) {
+		e.OnClose = OnCloseEntry{Func: f, Blocking: blocking}
+	}
+}
+
+// OnStart calls f once a listener is listening to the entry, see ChannelEntry.OnStart.
+func OnStart(f func()) EntryOption {
+	return func(e *ChannelEntry) {
+		e.OnStart = f
+	}
+}
+
+// RateLimited hears at most perSecond messages a second from the entry, see ChannelEntry.RateLimit.
+func RateLimited(perSecond float64) EntryOption {
+	return func(e *ChannelEntry) {
+		e.RateLimit = perSecond
+	}
+}
+
+// Coalesced collapses bursts of messages for the entry with f, see ChannelEntry.Coalesce.
+func Coalesced(f func(pending []interface{}) interface{}) EntryOption {
+	return func(e *ChannelEntry) {
+		e.Coalesce = f
+	}
+}
+
+// Transformed maps each message for the entry with f before it is handled, see ChannelEntry.Transform.
+func Transformed(f func(x interface{}) interface{}) EntryOption {
+	return func(e *ChannelEntry) {
+		e.Transform = f
+	}
+}
+
+// Credited returns a credit to c each time a message for the entry has been handled, see ChannelEntry.Credit.
+func Credited(c *Credit) EntryOption {
+	return func(e *ChannelEntry) {
+		e.Credit = c
+	}
+}
@@ -0,0 +1,69 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	heard := make(chan string, 8)
+	orders, alerts := make(chan interface{}), make(chan interface{})
+	closed := make(chan interface{})
+
+	built, err := NewBuilder().
+		OnKill(func() { close(closed) }).
+		With(WithBatchSize(2)).
+		Add(orders, func(i interface{}) { heard <- "order" }, Blocking(), Named("orders")).
+		Add(alerts, func(i interface{}) { heard <- "alert" }, Priority()).
+		AddTicker(time.Second/100, func(i interface{}) {
+			select {
+			case heard <- "tick":
+			default:
+			}
+		}, Blocking()).
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build: %v", err)
+	}
+
+	chs := built.Channels()
+	if len(chs) != 3 {
+		t.Fatalf("Expected 3 entries, found %d", len(chs))
+	}
+
+	if chs[0].Name != "orders" || !chs[0].Handler.Blocking || !chs[1].Handler.Priority || !chs[2].Handler.Blocking {
+		t.Errorf("Expected the entry options to be applied")
+	}
+
+	builtReady := make(chan interface{})
+	go built.Forever(builtReady)
+	<-builtReady
+
+	orders <- 1
+	alerts <- 1
+
+	want := map[string]bool{"order": true, "alert": true, "tick": true}
+	for len(want) > 0 {
+		select {
+		case h := <-heard:
+			delete(want, h)
+		case <-time.After(time.Second):
+			t.Fatalf("Never heard %v", want)
+		}
+	}
+
+	built.Kill()
+	<-closed
+}
+
+func TestBuilderErrors(t *testing.T) {
+	_, err := NewBuilder().
+		Add(nil, nil).
+		Add(make(chan interface{}), noopHandler, Level(-1)).
+		AddTicker(0, noopHandler).
+		Build()
+	if !errors.Is(err, ErrBadInterval) {
+		t.Fatalf("Expected the builder to report its problems, got: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package ds
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Chaos describes the faults WithChaos injects, for testing how handlers cope with the select's
+// edge cases. Each rate is the chance, from 0 to 1, of the fault striking any one message.
+type Chaos struct {
+	// Seed makes a run's faults reproducible, given messages arriving in the same order. Each
+	// kind of fault is rolled from its own stream, so the same seed drops the same messages
+	// however the handler calls' delays fall.
+	Seed uint64
+
+	// DelayRate delays a handler call by up to MaxDelay, timed by the select's Clock.
+	DelayRate float64
+	MaxDelay  time.Duration
+
+	// DropRate drops a message before it reaches its handler, as DropChaos.
+	DropRate float64
+
+	// CloseRate treats an entry's channel as closed on hearing a message, which is dropped,
+	// as if its producer closed it early. Its channel is left open, only entries with listeners
+	// of their own, without WithListenerGroups, are closed so.
+	CloseRate float64
+}
+
+// chaos rolls the dice for the faults of a Chaos, each kind from a stream of its own seeded by
+// Seed, so the handler goroutines drawing delays can't shift which messages the listeners drop.
+type chaos struct {
+	Chaos
+
+	drops  *chaosStream
+	closes *chaosStream
+	delays *chaosStream
+}
+
+// The streams of each fault kind, mixed into Seed.
+const (
+	streamDrops uint64 = iota + 1
+	streamCloses
+	streamDelays
+)
+
+func newChaos(c Chaos) *chaos {
+	return &chaos{
+		Chaos:  c,
+		drops:  newChaosStream(c.Seed, streamDrops),
+		closes: newChaosStream(c.Seed, streamCloses),
+		delays: newChaosStream(c.Seed, streamDelays),
+	}
+}
+
+// chaosStream is a seeded source of rolls, safe for concurrent use.
+type chaosStream struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newChaosStream(seed, stream uint64) *chaosStream {
+	return &chaosStream{rnd: rand.New(rand.NewPCG(seed, stream))}
+}
+
+// strikes reports whether a fault of the given rate strikes.
+func (s *chaosStream) strikes(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rnd.Float64() < rate
+}
+
+// delay returns how long to hold up a handler call, zero if not at all.
+func (c *chaos) delay() time.Duration {
+	if !c.delays.strikes(c.DelayRate) || c.MaxDelay <= 0 {
+		return 0
+	}
+
+	c.delays.mu.Lock()
+	defer c.delays.mu.Unlock()
+	return time.Duration(c.delays.rnd.Int64N(int64(c.MaxDelay))) + 1
+}
+
+// delayed wraps f to be held up by chaos, if any.
+func (d *DynamicSelect) delayed(f HandlerFunc) HandlerFunc {
+	if d.chaos == nil || d.chaos.DelayRate <= 0 {
+		return f
+	}
+
+	return func(x interface{}) {
+		if wait := d.chaos.delay(); wait > 0 {
+			timer := d.clock.NewTimer(wait)
+			<-timer.C()
+		}
+
+		f(x)
+	}
+}
+
+// chaosDrop reports whether chaos drops x, recording the drop and returning its credit if so.
+func (d *DynamicSelect) chaosDrop(i int, e ChannelEntry, x interface{}) bool {
+	if d.chaos == nil || !d.chaos.drops.strikes(d.chaos.DropRate) {
+		return false
+	}
+
+	e.Credit.release()
+	d.shed(i, e, x, DropChaos)
+	return true
+}
+
+// chaosClose reports whether chaos closes the entry on hearing x, recording x as dropped if so.
+func (d *DynamicSelect) chaosClose(i int, e ChannelEntry, x interface{}) bool {
+	if d.chaos == nil || !d.chaos.closes.strikes(d.chaos.CloseRate) {
+		return false
+	}
+
+	e.Credit.release()
+	d.shed(i, e, x, DropChaos)
+	return true
+}
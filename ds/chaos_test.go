@@ -0,0 +1,115 @@
+package ds
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChaosDrops(t *testing.T) {
+	run := func(seed uint64) []interface{} {
+		var mu sync.Mutex
+		handled := []interface{}{}
+		c := make(chan interface{})
+
+		chaotic := NewDynamicSelect(func() {}, []ChannelEntry{{
+			Channel: c,
+			Handler: HandlerEntry{Func: func(i interface{}) {
+				mu.Lock()
+				defer mu.Unlock()
+				handled = append(handled, i)
+			}, Blocking: true},
+		}}, WithChaos(Chaos{Seed: seed, DropRate: 0.5, DelayRate: 0.5, MaxDelay: time.Millisecond}))
+		chaoticReady := make(chan interface{})
+		go chaotic.Forever(chaoticReady)
+		<-chaoticReady
+
+		for i := 0; i < 100; i++ {
+			c <- i
+		}
+
+		// The last message may still be on its way to the handler.
+		for k := 0; k < 1000; k++ {
+			mu.Lock()
+			settled := len(handled)+len(chaotic.Drops()) == 100
+			mu.Unlock()
+			if settled {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		chaotic.Kill()
+		chaotic.Wait()
+
+		dropped := len(chaotic.Drops())
+		for len(chaotic.Drops()) > 0 {
+			if drop := <-chaotic.Drops(); drop.Reason != DropChaos {
+				t.Errorf("Expected chaos drops only, found %s", drop.Reason)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(handled)+dropped != 100 {
+			t.Errorf("Expected every message handled or dropped, handled %d and dropped %d", len(handled), dropped)
+		}
+
+		return handled
+	}
+
+	// The drops are those of the seed's stream, whatever the delays.
+	want := []interface{}{}
+	drops := newChaos(Chaos{Seed: 7}).drops
+	for i := 0; i < 100; i++ {
+		if !drops.strikes(0.5) {
+			want = append(want, i)
+		}
+	}
+
+	first := run(7)
+	if !slices.Equal(first, want) {
+		t.Errorf("Expected seed 7 to pass exactly %v, handled %v", want, first)
+	}
+
+	if len(first) < 20 || len(first) > 80 {
+		t.Errorf("Expected about half the messages dropped, handled %d", len(first))
+	}
+
+	if again := run(7); !slices.Equal(first, again) {
+		t.Errorf("Expected the same seed to drop the same messages")
+	}
+
+	if DropChaos.String() != "chaos" {
+		t.Errorf("Unexpected drop reason name")
+	}
+}
+
+func TestChaosClose(t *testing.T) {
+	c := make(chan interface{})
+	closed := make(chan CloseReason, 1)
+
+	chaotic := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{Func: func(i interface{}) { t.Errorf("Handled %v on a closed entry", i) }, Blocking: true},
+		OnClose: OnCloseEntry{FuncReason: func(reason CloseReason) { closed <- reason }},
+	}}, WithChaos(Chaos{CloseRate: 1}))
+	chaoticReady := make(chan interface{})
+	go chaotic.Forever(chaoticReady)
+	<-chaoticReady
+	defer chaotic.Kill()
+
+	c <- "last"
+
+	select {
+	case reason := <-closed:
+		if reason != ClosedChannel {
+			t.Errorf("Expected the entry closed as if its channel was, found %s", reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the entry to be closed early.")
+	}
+
+	expectDrops(t, chaotic, []Drop{{Reason: DropChaos, Index: 0, Message: "last"}})
+}
@@ -0,0 +1,61 @@
+package ds
+
+// Child builds a DynamicSelect, as NewDynamicSelect does, whose lifetime is bounded by d's.
+// Once d is killed its children are killed, with d's KillReason, and shut down before d's own
+// listeners are stopped, its shutdown hooks run or its OnClose handlers called. The children's
+// children go first in turn, so a tree of selects tears down from the leaves. The child is run
+// by the caller, as any other select. A child that shuts down is let go of by d until it is
+// Reset, so children killed on their own don't pile up in a long lived parent.
+func (d *DynamicSelect) Child(onKillAction func(), channels []ChannelEntry, opts ...Option) *DynamicSelect {
+	child := NewDynamicSelect(onKillAction, channels, opts...)
+	child.parent = d
+	child.OnShutdown(0, func() { d.release(child) })
+
+	d.adopt(child)
+	return child
+}
+
+// adopt bounds child's lifetime by d's, unless it already is.
+func (d *DynamicSelect) adopt(child *DynamicSelect) {
+	d.childrenMu.Lock()
+	defer d.childrenMu.Unlock()
+
+	for _, c := range d.children {
+		if c == child {
+			return
+		}
+	}
+
+	d.children = append(d.children, child)
+}
+
+// release lets go of child, which has shut down.
+func (d *DynamicSelect) release(child *DynamicSelect) {
+	d.childrenMu.Lock()
+	defer d.childrenMu.Unlock()
+
+	for k, c := range d.children {
+		if c == child {
+			d.children = append(d.children[:k], d.children[k+1:]...)
+			return
+		}
+	}
+}
+
+// killChildren kills every child select, then waits for each to finish shutting down and lets
+// go of it.
+func (d *DynamicSelect) killChildren() {
+	d.childrenMu.Lock()
+	children := append([]*DynamicSelect(nil), d.children...)
+	d.childrenMu.Unlock()
+
+	reason := d.KillReason()
+	for _, child := range children {
+		child.KillWithReason(reason)
+	}
+
+	for _, child := range children {
+		child.Wait()
+		d.release(child)
+	}
+}
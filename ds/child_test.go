@@ -0,0 +1,99 @@
+package ds
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestChild(t *testing.T) {
+	var mu sync.Mutex
+	order := []string{}
+	record := func(s string) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, s)
+			mu.Unlock()
+		}
+	}
+
+	entry := func(s string) []ChannelEntry {
+		return []ChannelEntry{{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}},
+			OnClose: OnCloseEntry{Func: record(s + " closed"), Blocking: true},
+		}}
+	}
+
+	parent := NewDynamicSelect(record("parent killed"), entry("parent"))
+	child := parent.Child(record("child killed"), entry("child"))
+	grandchild := child.Child(record("grandchild killed"), entry("grandchild"))
+
+	for _, d := range []*DynamicSelect{parent, child, grandchild} {
+		dReady := make(chan interface{})
+		go d.Forever(dReady)
+		<-dReady
+	}
+
+	reason := errors.New("shutting down")
+	parent.KillWithReason(reason)
+	parent.Wait()
+
+	expected := "[grandchild killed grandchild closed child killed child closed parent killed parent closed]"
+	if fmt.Sprint(order) != expected {
+		t.Errorf("Expected the tree to tear down from the leaves, %s, got %v", expected, order)
+	}
+
+	if !errors.Is(grandchild.KillReason(), reason) {
+		t.Errorf("Expected the parent's kill reason to reach the grandchild, got %v", grandchild.KillReason())
+	}
+}
+
+func TestChildReleased(t *testing.T) {
+	parent := NewDynamicSelect(func() {}, []ChannelEntry{})
+	parentReady := make(chan interface{})
+	go parent.Forever(parentReady)
+	<-parentReady
+	defer parent.Kill()
+
+	children := func() int {
+		parent.childrenMu.Lock()
+		defer parent.childrenMu.Unlock()
+
+		return len(parent.children)
+	}
+
+	child := parent.Child(func() {}, []ChannelEntry{})
+	childReady := make(chan interface{})
+	go child.Forever(childReady)
+	<-childReady
+
+	// A child killed on its own is let go of.
+	child.Kill()
+	child.Wait()
+	if n := children(); n != 0 {
+		t.Fatalf("Expected the parent to let go of a child killed on its own, it holds %d", n)
+	}
+
+	// Once reset, it is bounded by its parent again.
+	if err := child.Reset(); err != nil {
+		t.Fatalf("Failed to reset the child: %v", err)
+	}
+	if n := children(); n != 1 {
+		t.Fatalf("Expected the parent to hold the reset child, it holds %d", n)
+	}
+
+	childReady = make(chan interface{})
+	go child.Forever(childReady)
+	<-childReady
+
+	parent.Kill()
+	parent.Wait()
+	if child.IsAlive() {
+		t.Errorf("Expected the reset child to be killed with its parent")
+	}
+	if n := children(); n != 0 {
+		t.Errorf("Expected the parent to let go of its children once killed, it holds %d", n)
+	}
+}
@@ -0,0 +1,28 @@
+package ds
+
+import (
+	"time"
+
+	"github.com/krhoda/goconquer/clock"
+)
+
+// Clock is the source of time for a DynamicSelect's timing: ticker and timer entries, heartbeats,
+// rate limits, batch flushes, dedupe windows, journal times, the slow handler watchdog and the
+// shutdown timeout and drain grace period, and supervisors' backoffs. Latency stats and busy polling keep to the wall clock.
+// See WithClock, and ManualClock for tests.
+type Clock = clock.Clock
+
+// Timer is a time.Timer made by a Clock.
+type Timer = clock.Timer
+
+// Ticker is a time.Ticker made by a Clock.
+type Ticker = clock.Ticker
+
+// ManualClock is a Clock whose time only moves when Advance is called, firing the timers and
+// tickers due on the way in order, so tests need not sleep.
+type ManualClock = clock.Manual
+
+// NewManualClock returns a ManualClock reading start.
+func NewManualClock(start time.Time) *ManualClock {
+	return clock.NewManual(start)
+}
@@ -0,0 +1,60 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	ticks := make(chan interface{}, 8)
+	stalls := make(chan time.Duration, 1)
+
+	clocked := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel:   make(chan interface{}),
+		Handler:   HandlerEntry{Func: func(i interface{}) {}},
+		Heartbeat: time.Hour,
+		OnStall:   func(silence time.Duration) { stalls <- silence },
+	}}, WithClock(clock))
+
+	err := clocked.AddTicker(time.Minute, HandlerEntry{Func: func(i interface{}) {
+		select {
+		case ticks <- i:
+		default:
+		}
+	}, Blocking: true})
+	if err != nil {
+		t.Fatalf("Could not add ticker: %v", err)
+	}
+
+	clockedReady := make(chan interface{})
+	go clocked.Forever(clockedReady)
+	<-clockedReady
+
+	// The ticker and the heartbeat.
+	for clock.Waiters() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for k := 1; k <= 3; k++ {
+		clock.Advance(time.Minute)
+		if tick := (<-ticks).(time.Time); !tick.Equal(start.Add(time.Duration(k) * time.Minute)) {
+			t.Errorf("Expected tick %d at %s, found %s", k, start.Add(time.Duration(k)*time.Minute), tick)
+		}
+	}
+
+	clock.Advance(time.Hour)
+	select {
+	case silence := <-stalls:
+		// Silent since it was first listened to, before the ticks.
+		if silence != time.Hour+3*time.Minute {
+			t.Errorf("Expected an hour and three minutes of silence, found %s", silence)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the entry to stall on the clock's time.")
+	}
+
+	clocked.Kill()
+	clocked.Wait()
+}
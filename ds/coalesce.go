@@ -0,0 +1,40 @@
+package ds
+
+// coalesceWaiting collapses x and up to waiting further messages from tryRecv into one message
+// with the entry's Coalesce, reporting whether the channel was found closed along the way.
+// tryRecv reports a message, whether anything was received, and whether the channel was closed.
+func (e ChannelEntry) coalesceWaiting(x interface{}, waiting int, tryRecv func() (interface{}, bool, bool)) (interface{}, bool) {
+	pending := []interface{}{x}
+	closed := false
+
+	for ; waiting > 0; waiting-- {
+		y, received, isClosed := tryRecv()
+		if isClosed {
+			closed = true
+			break
+		}
+
+		if !received {
+			break
+		}
+
+		pending = append(pending, y)
+	}
+
+	// Only one handler call will return a credit.
+	for range pending[1:] {
+		e.Credit.release()
+	}
+
+	return e.Coalesce(pending), closed
+}
+
+// tryRecv receives from the entry's channel without blocking, see coalesceWaiting.
+func (e ChannelEntry) tryRecv() (interface{}, bool, bool) {
+	select {
+	case y, ok := <-e.Channel:
+		return y, ok, !ok
+	default:
+		return nil, false, false
+	}
+}
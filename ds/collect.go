@@ -0,0 +1,125 @@
+package ds
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// collector is a Results call in progress.
+type collector struct {
+	in   chan interface{}
+	done chan struct{}
+}
+
+// Results streams the results of every entry's FuncOut handler without an Output, see
+// HandlerEntry.FuncOut, until ctx is done or the select halts, when the channel is closed.
+// Only results returned once Results is called are streamed, so call it before scattering the
+// work. A handler waits on its result being read, as on an Output. It errors if the select is
+// not running.
+func (d *DynamicSelect) Results(ctx context.Context) (<-chan interface{}, error) {
+	if err := d.runningErr(); err != nil {
+		return nil, err
+	}
+
+	done := d.done
+	c := &collector{in: make(chan interface{}), done: make(chan struct{})}
+
+	d.collectMu.Lock()
+	if d.collectors == nil {
+		d.collectors = map[*collector]struct{}{}
+	}
+	d.collectors[c] = struct{}{}
+	d.collectMu.Unlock()
+
+	out := make(chan interface{})
+	d.spawn(func() {
+		defer close(out)
+		defer d.uncollect(c)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case result := <-c.in:
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				}
+			}
+		}
+	})
+
+	return out, nil
+}
+
+// Collect gathers the results streamed by Results into a slice, until it has n, if n is
+// positive, or timeout, if positive, has passed, or the select halts. It errors, with the
+// results gathered so far, if the select halts or the timeout passes before n are gathered.
+//
+//	results, err := d.Collect(len(shards), time.Second)
+func (d *DynamicSelect) Collect(n int, timeout time.Duration) ([]interface{}, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	results, err := d.Results(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gathered := []interface{}{}
+	for result := range results {
+		gathered = append(gathered, result)
+		if n > 0 && len(gathered) >= n {
+			return gathered, nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		if n <= 0 {
+			return gathered, nil
+		}
+
+		return gathered, fmt.Errorf("collected %d of %d results: %w", len(gathered), n, err)
+	}
+
+	return gathered, fmt.Errorf("collected %d results: %w", len(gathered), ErrHalted)
+}
+
+// uncollect ends a Results call.
+func (d *DynamicSelect) uncollect(c *collector) {
+	d.collectMu.Lock()
+	delete(d.collectors, c)
+	d.collectMu.Unlock()
+
+	close(c.done)
+}
+
+// collect passes a result returned by a FuncOut handler without an Output to every Results call
+// in progress.
+func (d *DynamicSelect) collect(result interface{}) {
+	d.collectMu.Lock()
+	collectors := make([]*collector, 0, len(d.collectors))
+	for c := range d.collectors {
+		collectors = append(collectors, c)
+	}
+	d.collectMu.Unlock()
+
+	for _, c := range collectors {
+		select {
+		case c.in <- result:
+		case <-c.done:
+		case <-d.done:
+		}
+	}
+}
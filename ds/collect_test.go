@@ -0,0 +1,92 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCollect(t *testing.T) {
+	shards := []chan interface{}{make(chan interface{}), make(chan interface{}), make(chan interface{})}
+
+	entries := []ChannelEntry{}
+	for _, c := range shards {
+		entries = append(entries, ChannelEntry{
+			Channel: c,
+			Handler: HandlerEntry{FuncOut: func(i interface{}) interface{} { return i.(int) * i.(int) }},
+		})
+	}
+
+	collecting := NewDynamicSelect(func() {}, entries)
+	if _, err := collecting.Collect(1, time.Second); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected collecting from a select not yet run to fail with ErrNotRunning, found %v", err)
+	}
+
+	collectingReady := make(chan interface{})
+	go collecting.Forever(collectingReady)
+	<-collectingReady
+	defer collecting.Kill()
+
+	// Scatter once the collector is listening.
+	go func() {
+		time.Sleep(time.Second / 50)
+		for i, c := range shards {
+			c <- i + 1
+		}
+	}()
+
+	results, err := collecting.Collect(len(shards), time.Second)
+	if err != nil {
+		t.Fatalf("Failed to collect: %v", err)
+	}
+
+	squares := []int{}
+	for _, r := range results {
+		squares = append(squares, r.(int))
+	}
+
+	sort.Ints(squares)
+	if len(squares) != 3 || squares[0] != 1 || squares[1] != 4 || squares[2] != 9 {
+		t.Errorf("Expected to collect [1 4 9], collected %v", squares)
+	}
+
+	// Nothing more is coming.
+	results, err = collecting.Collect(1, time.Second/20)
+	if !errors.Is(err, context.DeadlineExceeded) || len(results) != 0 {
+		t.Errorf("Expected collecting to time out empty handed, collected %v: %v", results, err)
+	}
+}
+
+func TestResultsHalted(t *testing.T) {
+	c := make(chan interface{})
+
+	streaming := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{FuncOut: func(i interface{}) interface{} { return i }, Blocking: true},
+	}})
+	streamingReady := make(chan interface{})
+	go streaming.Forever(streamingReady)
+	<-streamingReady
+
+	results, err := streaming.Results(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to stream results: %v", err)
+	}
+
+	c <- "first"
+	if got := <-results; got != "first" {
+		t.Errorf("Expected to stream the result first, streamed %v", got)
+	}
+
+	streaming.Kill()
+	select {
+	case _, ok := <-results:
+		if ok {
+			t.Errorf("Expected no further results once the select halted")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the results to close once the select halted")
+	}
+}
@@ -0,0 +1,22 @@
+package ds
+
+import (
+	"context"
+)
+
+// ContextEntry returns a ChannelEntry that closes once ctx is done, calling onDone as its Blocking
+// OnClose. It ties a child workflow's lifetime into the select like any other case.
+// The entry never carries a message. A goroutine waits on ctx, so it should eventually be cancelled.
+func ContextEntry(ctx context.Context, onDone func()) ChannelEntry {
+	c := make(chan interface{})
+	go func() {
+		<-ctx.Done()
+		close(c)
+	}()
+
+	return ChannelEntry{
+		Channel: c,
+		Handler: HandlerEntry{Func: noopHandler},
+		OnClose: OnCloseEntry{Func: onDone, Blocking: true},
+	}
+}
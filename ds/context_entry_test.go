@@ -0,0 +1,76 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestContextEntry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan interface{})
+
+	watching := NewDynamicSelect(func() {}, []ChannelEntry{ContextEntry(ctx, func() { close(cancelled) })})
+	watchingReady := make(chan interface{})
+	go watching.Forever(watchingReady)
+	<-watchingReady
+	defer watching.Kill()
+
+	cancel()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatalf("Context cancellation was not heard.")
+	}
+
+	time.Sleep(time.Second / 20)
+	if !watching.Channels()[0].IsClosed {
+		t.Errorf("Expected the context's entry to be closed.")
+	}
+
+	if !watching.IsAlive() {
+		t.Errorf("A context entry closing killed the select.")
+	}
+}
+
+func TestFuncContextEntryKilled(t *testing.T) {
+	started := make(chan interface{})
+	aborted := make(chan error, 1)
+	c := make(chan interface{})
+
+	cooperative := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Name:    "long",
+		Channel: c,
+		Handler: HandlerEntry{FuncContext: func(ctx context.Context, i interface{}) {
+			close(started)
+			<-ctx.Done()
+			aborted <- ctx.Err()
+		}},
+	}})
+	cooperativeReady := make(chan interface{})
+	go cooperative.Forever(cooperativeReady)
+	<-cooperativeReady
+	defer cooperative.Kill()
+
+	c <- 1
+	<-started
+
+	if err := cooperative.KillNamed("long"); err != nil {
+		t.Fatalf("Failed to kill the entry: %v", err)
+	}
+
+	select {
+	case err := <-aborted:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the handler's context to be cancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected killing the entry to cancel its handler's context")
+	}
+
+	if !cooperative.IsAlive() {
+		t.Errorf("Expected the select to outlive the entry")
+	}
+}
@@ -0,0 +1,64 @@
+package ds
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// availableCPUs is the number of CPUs the process may use: GOMAXPROCS, lowered to the
+// cgroup CPU quota when one can be read. Newer runtimes already fold the quota into
+// GOMAXPROCS, older ones do not.
+func availableCPUs() int {
+	n := runtime.GOMAXPROCS(0)
+	if quota := cgroupCPUQuota(); quota > 0 && quota < n {
+		n = quota
+	}
+
+	if n < 1 {
+		n = 1
+	}
+
+	return n
+}
+
+// cgroupCPUQuota reads the CPU quota of a cgroup v2 or v1 hierarchy, rounded up,
+// returning 0 when there is none or it can't be read.
+func cgroupCPUQuota() int {
+	// v2: "$quota $period" or "max $period".
+	if b, err := os.ReadFile("/sys/fs/cgroup/cpu.max"); err == nil {
+		fields := strings.Fields(string(b))
+		if len(fields) == 2 {
+			return quotaOver(fields[0], fields[1])
+		}
+		return 0
+	}
+
+	// v1: quota and period in separate files, a quota of -1 is unlimited.
+	q, err := os.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_quota_us")
+	if err != nil {
+		return 0
+	}
+
+	p, err := os.ReadFile("/sys/fs/cgroup/cpu/cpu.cfs_period_us")
+	if err != nil {
+		return 0
+	}
+
+	return quotaOver(strings.TrimSpace(string(q)), strings.TrimSpace(string(p)))
+}
+
+func quotaOver(quota, period string) int {
+	q, err := strconv.ParseInt(quota, 10, 64)
+	if err != nil || q <= 0 {
+		return 0
+	}
+
+	p, err := strconv.ParseInt(period, 10, 64)
+	if err != nil || p <= 0 {
+		return 0
+	}
+
+	return int((q + p - 1) / p)
+}
@@ -0,0 +1,33 @@
+package ds
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestAvailableCPUs(t *testing.T) {
+	n := availableCPUs()
+	if n < 1 || n > runtime.GOMAXPROCS(0) {
+		t.Errorf("Expected between 1 and GOMAXPROCS CPUs, found %d", n)
+	}
+}
+
+func TestQuotaOver(t *testing.T) {
+	cases := []struct {
+		quota, period string
+		expected      int
+	}{
+		{"max", "100000", 0},
+		{"-1", "100000", 0},
+		{"200000", "100000", 2},
+		{"150000", "100000", 2},
+		{"50000", "100000", 1},
+		{"junk", "100000", 0},
+	}
+
+	for _, c := range cases {
+		if n := quotaOver(c.quota, c.period); n != c.expected {
+			t.Errorf("quotaOver(%q, %q) = %d, expected %d", c.quota, c.period, n, c.expected)
+		}
+	}
+}
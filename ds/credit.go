@@ -0,0 +1,62 @@
+package ds
+
+// Credit is a window of messages a producer may have in flight to an entry.
+// A producer takes a credit before each send and the DynamicSelect returns it once the
+// message's handler has finished, so a producer which finds no credit left knows the entry
+// is backed up and can shed load or slow down instead of blocking on the send.
+// Producers that never take credits are unaffected.
+type Credit struct {
+	tokens chan struct{}
+}
+
+// NewCredit returns a Credit allowing n messages in flight.
+func NewCredit(n int) *Credit {
+	if n < 1 {
+		n = 1
+	}
+
+	c := &Credit{tokens: make(chan struct{}, n)}
+	for i := 0; i < n; i++ {
+		c.tokens <- struct{}{}
+	}
+
+	return c
+}
+
+// C is a channel yielding a credit per receive, for producers using it in a select.
+func (c *Credit) C() <-chan struct{} {
+	return c.tokens
+}
+
+// Acquire blocks until a credit is available and takes it.
+func (c *Credit) Acquire() {
+	<-c.tokens
+}
+
+// TryAcquire takes a credit if one is available, reporting whether it did.
+func (c *Credit) TryAcquire() bool {
+	select {
+	case <-c.tokens:
+		return true
+	default:
+		return false
+	}
+}
+
+// Available reports the number of credits not yet taken.
+func (c *Credit) Available() int {
+	return len(c.tokens)
+}
+
+// release returns a credit, ignoring a window that is already full.
+// A nil Credit is valid, so entries without one need no checks.
+func (c *Credit) release() {
+	if c == nil {
+		return
+	}
+
+	select {
+	case c.tokens <- struct{}{}:
+	default:
+	}
+}
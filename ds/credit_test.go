@@ -0,0 +1,49 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCredit(t *testing.T) {
+	release := make(chan struct{})
+	credit := NewCredit(2)
+	entry := ChannelEntry{
+		Channel: make(chan interface{}, 2),
+		Handler: HandlerEntry{
+			Func: func(i interface{}) {
+				<-release
+			},
+			Blocking: true,
+		},
+		OnClose: OnCloseEntry{
+			Func: func() {},
+		},
+		Credit: credit,
+	}
+
+	creditReady := make(chan interface{})
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{entry})
+	go selectMgr.Forever(creditReady)
+	<-creditReady
+
+	for i := 0; i < 2; i++ {
+		if !credit.TryAcquire() {
+			t.Fatalf("Credit %d was unavailable.", i)
+		}
+		entry.Channel <- i
+	}
+
+	if credit.TryAcquire() {
+		t.Errorf("Credit was available beyond the window.")
+	}
+
+	close(release)
+	time.Sleep(time.Second / 10)
+
+	if credit.Available() != 2 {
+		t.Errorf("Expected handled messages to return 2 credits, %d available", credit.Available())
+	}
+
+	selectMgr.Kill()
+}
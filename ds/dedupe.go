@@ -0,0 +1,63 @@
+package ds
+
+import (
+	"sync"
+	"time"
+)
+
+// deduper remembers the keys heard for an entry within a window, see ChannelEntry.DedupeKey.
+type deduper struct {
+	ttl time.Duration
+
+	mu   sync.Mutex
+	seen map[interface{}]time.Time
+
+	// sweep is when expired keys are next forgotten.
+	sweep time.Time
+}
+
+func newDeduper(ttl time.Duration) *deduper {
+	return &deduper{ttl: ttl, seen: map[interface{}]time.Time{}}
+}
+
+// repeat reports whether key was heard within the window before now, remembering it if not.
+func (w *deduper) repeat(key interface{}, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if now.After(w.sweep) {
+		for k, expires := range w.seen {
+			if !now.Before(expires) {
+				delete(w.seen, k)
+			}
+		}
+
+		w.sweep = now.Add(w.ttl)
+	}
+
+	if expires, ok := w.seen[key]; ok && now.Before(expires) {
+		return true
+	}
+
+	w.seen[key] = now.Add(w.ttl)
+	return false
+}
+
+// duplicate reports whether x repeats a message recently heard for the entry, counting it and
+// returning its credit if so.
+func (e ChannelEntry) duplicate(x interface{}, now time.Time) bool {
+	if e.dedupe == nil || e.DedupeTTL <= 0 {
+		return false
+	}
+
+	if !e.dedupe.repeat(e.DedupeKey(x), now) {
+		return false
+	}
+
+	if e.stats != nil {
+		e.stats.repeats.Add(1)
+	}
+
+	e.Credit.release()
+	return true
+}
@@ -0,0 +1,77 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeduper(t *testing.T) {
+	w := newDeduper(time.Second)
+	now := time.Now()
+
+	if w.repeat("a", now) {
+		t.Errorf("Expected the first key not to be a repeat")
+	}
+
+	if !w.repeat("a", now.Add(time.Second/2)) {
+		t.Errorf("Expected the key to repeat within the window")
+	}
+
+	if w.repeat("b", now.Add(time.Second/2)) {
+		t.Errorf("Expected another key not to be a repeat")
+	}
+
+	if w.repeat("a", now.Add(2*time.Second)) {
+		t.Errorf("Expected the key to be forgotten once the window passed")
+	}
+
+	if _, ok := w.seen["b"]; ok {
+		t.Errorf("Expected expired keys to be swept")
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	heard := make(chan interface{}, 10)
+	c := make(chan interface{})
+
+	type event struct {
+		ID   int
+		Body string
+	}
+
+	deduping := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel:   c,
+		Handler:   HandlerEntry{Func: func(i interface{}) { heard <- i }, Blocking: true},
+		DedupeKey: func(x interface{}) interface{} { return x.(event).ID },
+		DedupeTTL: time.Minute,
+	}})
+	dedupingReady := make(chan interface{})
+	go deduping.Forever(dedupingReady)
+	<-dedupingReady
+	defer deduping.Kill()
+
+	// A reconnecting upstream retransmits the first two.
+	for _, id := range []int{1, 2, 1, 2, 3} {
+		c <- event{ID: id, Body: "retransmitted"}
+	}
+
+	for _, want := range []int{1, 2, 3} {
+		select {
+		case got := <-heard:
+			if got.(event).ID != want {
+				t.Errorf("Expected to handle event %d, handled %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected to handle event %d", want)
+		}
+	}
+
+	time.Sleep(time.Second / 20)
+	if stats := deduping.Stats()[0]; stats.Duplicates != 2 {
+		t.Errorf("Expected 2 duplicates to be counted, found %d", stats.Duplicates)
+	}
+
+	if err := (ChannelEntry{Channel: c, Handler: HandlerEntry{Func: func(i interface{}) {}}, DedupeKey: func(x interface{}) interface{} { return x }}).Validate(); err == nil {
+		t.Errorf("Expected a DedupeKey without a DedupeTTL to fail validation")
+	}
+}
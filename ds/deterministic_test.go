@@ -0,0 +1,64 @@
+package ds
+
+import (
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+func TestDeterministic(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var mu sync.Mutex
+		heard := []interface{}{}
+		hear := func(i interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			heard = append(heard, i)
+		}
+
+		orders, refunds := make(chan interface{}), make(chan interface{})
+		deterministic := NewDynamicSelect(func() {}, []ChannelEntry{
+			{Channel: orders, Handler: HandlerEntry{Func: hear}},
+			{Channel: refunds, Handler: HandlerEntry{Func: hear, Blocking: true}},
+		}, WithDeterministic(), WithShards(4), WithBusyPoll(time.Millisecond), WithUnboundedDispatch())
+
+		err := deterministic.AddTicker(time.Minute, HandlerEntry{Func: func(i interface{}) { hear("tick") }})
+		if err != nil {
+			t.Fatalf("Could not add ticker: %v", err)
+		}
+
+		deterministicReady := make(chan interface{})
+		go deterministic.Forever(deterministicReady)
+		<-deterministicReady
+
+		for _, send := range []func(){
+			func() { orders <- "order 1" },
+			func() { refunds <- "refund 1" },
+			func() { time.Sleep(time.Minute) },
+			func() { orders <- "order 2" },
+		} {
+			send()
+			synctest.Wait()
+		}
+
+		deterministic.Kill()
+		deterministic.Wait()
+		synctest.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		expected := []interface{}{"order 1", "refund 1", "tick", "order 2"}
+		if len(heard) != len(expected) {
+			t.Fatalf("Expected %v, heard %v", expected, heard)
+		}
+
+		for k := range expected {
+			if heard[k] != expected[k] {
+				t.Errorf("Expected %v, heard %v", expected, heard)
+				break
+			}
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package ds
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens to a message when its destination is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for room, pushing back on the listener.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop discards the message.
+	OverflowDrop
+
+	// OverflowSpawn runs the handler in a goroutine of its own, as an unbounded dispatcher would.
+	// Aggregators, whose handlers must not run concurrently, block instead.
+	OverflowSpawn
+
+	// OverflowDropOldest discards the oldest message waiting to make room. Dispatchers drop the newest instead.
+	OverflowDropOldest
+
+	// OverflowError discards the message, reporting ErrAggregatorFull to the error sink.
+	// Dispatchers simply drop it.
+	OverflowError
+)
+
+// Dispatcher defaults scale with the CPUs available, non-Blocking handlers often wait on I/O
+// so there are several workers per CPU.
+const (
+	dispatchWorkersPerCPU  = 8
+	dispatchQueuePerWorker = 16
+)
+
+// dispatcher runs non-Blocking handlers on a bounded set of worker goroutines.
+type dispatcher struct {
+	// unbounded restores the goroutine per message behavior.
+	unbounded bool
+
+	workers int32
+	running atomic.Int32
+	policy  OverflowPolicy
+	work    chan dispatchJob
+
+	// done aborts a blocked submit once the select halts.
+	done chan interface{}
+
+	// handlers counts the workers and spawned handlers yet to return.
+	handlers sync.WaitGroup
+
+	// dropped and spawned count the jobs the overflow policy applied to,
+	// inflight the spawned jobs yet to return.
+	dropped  atomic.Uint64
+	spawned  atomic.Uint64
+	inflight atomic.Int32
+}
+
+// DispatchStats describes the dispatcher running non-Blocking handlers, see WithDispatcher.
+type DispatchStats struct {
+	// Workers is the number of worker goroutines running, of at most MaxWorkers.
+	Workers    int
+	MaxWorkers int
+
+	// Queued is the number of messages waiting for a worker.
+	Queued int
+
+	// Dropped and Spawned count the messages OverflowDrop discarded and OverflowSpawn, or an
+	// unbounded dispatcher, ran in a goroutine of their own.
+	Dropped uint64
+	Spawned uint64
+}
+
+type dispatchJob struct {
+	f        func(i interface{})
+	x        interface{}
+	credit   *Credit
+	stats    *entryStats
+	enqueued time.Time
+
+	// slots, if set, caps the entry's handlers running at once, see HandlerEntry.MaxConcurrency.
+	// The listener took one before submitting the job.
+	slots *Credit
+}
+
+// release returns what the job holds once it has run or is dropped.
+func (j dispatchJob) release() {
+	j.credit.release()
+	j.slots.release()
+}
+
+func (j dispatchJob) run() {
+	defer j.release()
+
+	start := j.stats.begin(j.enqueued)
+	defer j.stats.end(start)
+
+	j.f(j.x)
+}
+
+func newDispatcher(workers, queue int, policy OverflowPolicy, unbounded bool, done chan interface{}) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+
+	if queue < 0 {
+		queue = 0
+	}
+
+	return &dispatcher{
+		unbounded: unbounded,
+		workers:   int32(workers),
+		policy:    policy,
+		work:      make(chan dispatchJob, queue),
+		done:      done,
+	}
+}
+
+// submit schedules the job, reporting false if it was dropped.
+// The job's credit is released once it has run or is dropped.
+func (p *dispatcher) submit(j dispatchJob) bool {
+	if p.unbounded {
+		p.spawn(j)
+		return true
+	}
+
+	// Grow the pool while work is backing up.
+	if running := p.running.Load(); running == 0 || (running < p.workers && len(p.work) > 0) {
+		p.startWorker()
+	}
+
+	select {
+	case p.work <- j:
+		return true
+	default:
+	}
+
+	switch p.policy {
+	case OverflowDrop, OverflowDropOldest, OverflowError:
+		p.dropped.Add(1)
+		j.release()
+		return false
+
+	case OverflowSpawn:
+		p.spawn(j)
+		return true
+	}
+
+	select {
+	case p.work <- j:
+		return true
+	case <-p.done:
+		j.release()
+		return false
+	}
+}
+
+func (p *dispatcher) startWorker() {
+	if p.running.Add(1) > p.workers {
+		p.running.Add(-1)
+		return
+	}
+
+	p.handlers.Add(1)
+	go func() {
+		defer p.handlers.Done()
+		defer p.running.Add(-1)
+		for j := range p.work {
+			j.run()
+		}
+	}()
+}
+
+// spawn runs the job in a goroutine of its own.
+func (p *dispatcher) spawn(j dispatchJob) {
+	p.spawned.Add(1)
+	p.inflight.Add(1)
+	p.handlers.Add(1)
+	go func() {
+		defer p.handlers.Done()
+		defer p.inflight.Add(-1)
+		j.run()
+	}()
+}
+
+// close lets the workers exit once the queued work is done. Nothing may submit afterwards.
+func (p *dispatcher) close() {
+	close(p.work)
+}
+
+// wait blocks until every handler submitted has returned, once closed.
+func (p *dispatcher) wait() {
+	p.handlers.Wait()
+}
+
+// DispatchStats reports the state of the dispatcher of the current run.
+func (d *DynamicSelect) DispatchStats() DispatchStats {
+	d.resetMu.RLock()
+	defer d.resetMu.RUnlock()
+
+	return DispatchStats{
+		Workers:    int(d.dispatch.running.Load()),
+		MaxWorkers: int(d.dispatch.workers),
+		Queued:     len(d.dispatch.work),
+		Dropped:    d.dispatch.dropped.Load(),
+		Spawned:    d.dispatch.spawned.Load(),
+	}
+}
@@ -0,0 +1,165 @@
+package ds
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcherBounded(t *testing.T) {
+	done := make(chan interface{})
+	p := newDispatcher(2, 0, OverflowBlock, false, done)
+
+	var current, peak int32
+	var wg sync.WaitGroup
+	wg.Add(20)
+
+	f := func(i interface{}) {
+		defer wg.Done()
+		n := atomic.AddInt32(&current, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&current, -1)
+	}
+
+	for i := 0; i < 20; i++ {
+		if !p.submit(dispatchJob{f: f, x: i}) {
+			t.Errorf("Blocking dispatcher dropped a message.")
+		}
+	}
+
+	wg.Wait()
+	p.close()
+
+	if peak > 2 {
+		t.Errorf("Dispatcher ran %d handlers at once, expected at most 2", peak)
+	}
+}
+
+func TestDispatcherDrop(t *testing.T) {
+	done := make(chan interface{})
+	p := newDispatcher(1, 1, OverflowDrop, false, done)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.submit(dispatchJob{f: func(i interface{}) {
+		close(started)
+		<-release
+	}})
+	<-started
+
+	if !p.submit(dispatchJob{f: func(i interface{}) {}}) {
+		t.Errorf("Dispatcher dropped a message with room in its queue.")
+	}
+
+	if p.submit(dispatchJob{f: func(i interface{}) {}}) {
+		t.Errorf("Dispatcher accepted a message with no room for it.")
+	}
+
+	if p.dropped.Load() != 1 {
+		t.Errorf("Expected one dropped message to be counted, found %d", p.dropped.Load())
+	}
+
+	close(release)
+	p.close()
+}
+
+func TestUnboundedDispatch(t *testing.T) {
+	var heard atomic.Int64
+	c := make(chan interface{})
+	entries := []ChannelEntry{{Channel: c, Handler: HandlerEntry{Func: func(i interface{}) { heard.Add(1) }}}}
+
+	selectMgr := NewDynamicSelect(func() {}, entries, WithUnboundedDispatch())
+	unboundedReady := make(chan interface{})
+	go selectMgr.Forever(unboundedReady)
+	<-unboundedReady
+	defer selectMgr.Kill()
+
+	for i := 0; i < 10; i++ {
+		c <- i
+	}
+
+	for k := 0; k < 100 && heard.Load() < 10; k++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := heard.Load(); n != 10 {
+		t.Errorf("Expected 10 messages heard through the unbounded dispatcher, heard %d", n)
+	}
+}
+
+func TestDispatchStats(t *testing.T) {
+	gate := make(chan interface{})
+	c := make(chan interface{})
+	entries := []ChannelEntry{{Channel: c, Handler: HandlerEntry{Func: func(i interface{}) { <-gate }}}}
+
+	pooled := NewDynamicSelect(func() {}, entries, WithDispatcher(1, 1, OverflowSpawn))
+	pooledReady := make(chan interface{})
+	go pooled.Forever(pooledReady)
+	<-pooledReady
+
+	// One message runs, one waits, the rest spawn.
+	for i := 0; i < 4; i++ {
+		c <- i
+	}
+	time.Sleep(time.Second / 20)
+
+	stats := pooled.DispatchStats()
+	if stats.MaxWorkers != 1 || stats.Workers != 1 || stats.Spawned == 0 {
+		t.Errorf("Unexpected dispatch stats: %+v", stats)
+	}
+
+	close(gate)
+	pooled.Kill()
+	pooled.Wait()
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	c := make(chan interface{})
+	done := make(chan interface{}, 12)
+
+	capped := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{Func: func(i interface{}) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+
+			time.Sleep(time.Second / 50)
+			running.Add(-1)
+			done <- i
+		}, MaxConcurrency: 2},
+	}}, WithUnboundedDispatch())
+	cappedReady := make(chan interface{})
+	go capped.Forever(cappedReady)
+	<-cappedReady
+	defer capped.Kill()
+
+	for k := 0; k < 12; k++ {
+		c <- k
+	}
+
+	for k := 0; k < 12; k++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("Only %d messages were handled", k)
+		}
+	}
+
+	if p := peak.Load(); p != 2 {
+		t.Errorf("Expected at most 2 handler calls at once, and to reach it, peaked at %d", p)
+	}
+}
@@ -0,0 +1,74 @@
+package ds
+
+import "time"
+
+// DropReason is why the select dropped a message rather than handle it.
+type DropReason int
+
+const (
+	// DropOverflow is a message discarded by an overflow policy, see WithAggregatorOverflow and
+	// WithDispatcher.
+	DropOverflow DropReason = iota
+
+	// DropSampled is a message skipped by sampling, see ChannelEntry.SampleEvery.
+	DropSampled
+
+	// DropDuplicate is a message suppressed as a repeat, see ChannelEntry.DedupeKey.
+	DropDuplicate
+
+	// DropSpill is a message a spilling entry lost, to a disk error or to being killed while it
+	// waited, see ChannelEntry.Spill.
+	DropSpill
+
+	// DropChaos is a message dropped, or an entry closed early on hearing it, by WithChaos.
+	DropChaos
+)
+
+func (r DropReason) String() string {
+	switch r {
+	case DropOverflow:
+		return "overflow"
+	case DropSampled:
+		return "sampled"
+	case DropDuplicate:
+		return "duplicate"
+	case DropSpill:
+		return "spill"
+	case DropChaos:
+		return "chaos"
+	default:
+		return "unknown"
+	}
+}
+
+// Drop records a message the select dropped.
+type Drop struct {
+	Reason DropReason
+	Time   time.Time
+
+	// Index and Name identify the entry the message was for.
+	Index int
+	Name  string
+
+	// Message is the message dropped, nil if it was lost unread from a spill file, or was for an
+	// entry built by Typed.
+	Message interface{}
+}
+
+// dropBuffer is how many drops wait for a reader before further drops go unrecorded.
+const dropBuffer = 256
+
+// Drops returns the channel a Drop is sent on for each message the select drops, across every
+// run, so data loss can be measured rather than discovered. Drops go unrecorded rather than
+// hold up the select if nobody reads them, the counts in Stats are kept regardless.
+func (d *DynamicSelect) Drops() <-chan Drop {
+	return d.drops
+}
+
+// shed records the message x, for the entry at index i, as dropped if there is room.
+func (d *DynamicSelect) shed(i int, e ChannelEntry, x interface{}, reason DropReason) {
+	select {
+	case d.drops <- Drop{Reason: reason, Time: d.clock.Now(), Index: i, Name: e.Name, Message: x}:
+	default:
+	}
+}
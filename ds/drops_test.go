@@ -0,0 +1,59 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrops(t *testing.T) {
+	sampled := make(chan interface{})
+	deduped := make(chan interface{})
+	h := HandlerEntry{Func: func(i interface{}) {}, Blocking: true}
+
+	shedding := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Name: "sampled", Channel: sampled, Handler: h, SampleEvery: 2},
+		{Channel: deduped, Handler: h, DedupeKey: func(x interface{}) interface{} { return x }, DedupeTTL: time.Minute},
+	})
+	sheddingReady := make(chan interface{})
+	go shedding.Forever(sheddingReady)
+	<-sheddingReady
+	defer shedding.Kill()
+
+	for i := 0; i < 4; i++ {
+		sampled <- i
+	}
+
+	expectDrops(t, shedding, []Drop{
+		{Reason: DropSampled, Index: 0, Name: "sampled", Message: 1},
+		{Reason: DropSampled, Index: 0, Name: "sampled", Message: 3},
+	})
+
+	deduped <- "again"
+	deduped <- "again"
+
+	expectDrops(t, shedding, []Drop{{Reason: DropDuplicate, Index: 1, Message: "again"}})
+
+	if DropSpill.String() != "spill" || DropReason(-1).String() != "unknown" {
+		t.Errorf("Unexpected drop reason names")
+	}
+}
+
+// expectDrops reads the drops expected from d, in order.
+func expectDrops(t *testing.T, d *DynamicSelect, expected []Drop) {
+	t.Helper()
+
+	for _, want := range expected {
+		select {
+		case got := <-d.Drops():
+			if got.Reason != want.Reason || got.Index != want.Index || got.Name != want.Name || got.Message != want.Message {
+				t.Errorf("Expected the drop %+v, found %+v", want, got)
+			}
+
+			if got.Time.IsZero() {
+				t.Errorf("Expected the drop to be timed")
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the %s drop of %v", want.Reason, want.Message)
+		}
+	}
+}
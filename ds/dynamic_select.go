@@ -1,10 +1,18 @@
 package ds
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"hash/fnv"
+	"os"
+	"runtime"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/krhoda/goconquer/clock"
 )
 
 // DynamicSelect is a concurrency control structure likenable to a dynamic generic select statement with sane defaults.
@@ -16,67 +24,416 @@ import (
 // This may seem overly cautious, but a stream reading a message every few milliseconds will noisly ignore numerous kill commands if all channels are in a flat select.
 // Note issueing a kill command will not close the channels being listened to.
 type DynamicSelect struct {
-	// Callback used when Kill is closed/has a message.
-	onKillAction func()
+	// Callbacks used when Kill is closed/has a message, see OnShutdown.
+	hooksMu sync.Mutex
+	hooks   []shutdownHook
+
+	// children are killed ahead of the select, see Child.
+	childrenMu sync.Mutex
+	children   []*DynamicSelect
+
+	// parent is the select this one is a Child of, if any.
+	parent *DynamicSelect
+
+	// collectors receive the results of FuncOut handlers without an Output, see Results.
+	collectMu  sync.Mutex
+	collectors map[*collector]struct{}
 
 	// A list of channels to manange and how to manage them
 	channels []ChannelEntry
 
 	// Aggregator used to pass through only one message at a time.
-	aggregator chan dsWrapper
+	aggregator chan *dsWrapper
 
 	// A channel used to load additional cases into the DynamicSelect during runtime.
 	load chan []ChannelEntry
 
-	// Load guard ensures callers to DynamicSelect.Channels() get a snapshot and don't read/write the same thing.
-	loadGuard chan interface{}
+	// loadMu ensures callers to DynamicSelect.Channels() get a snapshot and don't read/write the same thing.
+	loadMu sync.Mutex
+
+	// reserved holds the Names of entries sent to be loaded but not yet taken in, guarded by loadMu,
+	// so concurrent Loads can't both claim a Name.
+	reserved map[string]struct{}
 
 	// kill is used to signal DynamicSelect to halt.
 	// Internal operation ensures that once issued, a kill
 	// command will be the next message processed.
 	kill chan interface{}
 
-	// Used to ensure kill isn't called multiple times.
-	killGuard chan interface{}
+	// Used to ensure kill is issued once a run, however many callers race to Kill.
+	killOnce *sync.Once
 
 	// Prevents multiple kill commands, and alive getting breifly overriden by a race condition.
-	killHeard bool
+	killHeard atomic.Bool
+
+	// killSignals kill the select when caught while running, see WithSignalKill.
+	killSignals []os.Signal
+
+	// killReason is why the current run was killed, passed to onKillReason.
+	// It is guarded by reasonMu, so it may be read while a kill is being issued.
+	reasonMu     sync.Mutex
+	killReason   error
+	onKillReason func(reason error)
 
 	// done is an internal kill chan;
 	done chan interface{}
 
+	// ready is closed once the current run is listening, see Ready.
+	ready chan struct{}
+
+	// events carries lifecycle events, see Events.
+	events chan Event
+
+	// drops carries a record of each message dropped, see Drops.
+	drops chan Drop
+
+	// tier is the state machine tier the main loop last entered, see DumpState.
+	tier atomic.Int32
+
+	// lifecycle is the select's LifecycleState, whose transitions are sent on stateChanges.
+	lifecycle    atomic.Int32
+	stateChanges chan LifecycleState
+
 	// Aggregator used to pass through priority messages.
-	priorityAggregator chan dsWrapper
+	priorityAggregator chan *dsWrapper
 
 	// Aggregator used to pass through close notifications.
-	onClose chan closeWrapper
+	onClose chan *closeWrapper
 
 	// alive is used to inform listeners if the main routine has exited.
-	alive bool
+	alive atomic.Bool
 
 	// running is used to accept loads to prevent client deadlocks.
-	running bool
+	running atomic.Bool
+
+	// started is set once Forever is called, until a Reset.
+	started atomic.Bool
+
+	// ctx is the Context of the current run, cancel cancels it during shut down.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// shutdownTimeout bounds how long shutDown waits on listeners and shard loops, if positive.
+	shutdownTimeout time.Duration
+
+	// clock times everything but latency stats and busy polling, see WithClock.
+	clock Clock
+
+	// logger and logLevel are set by WithLogger and WithLogLevel.
+	logger   Logger
+	logLevel LogLevel
+
+	// drainGrace bounds how long shutting down waits on Kill, Load and Broadcast calls still sending
+	// once the listeners have exited, sending counts those calls and idle is closed once none are.
+	drainGrace time.Duration
+	sendMu     sync.Mutex
+	sending    int
+	idle       chan struct{}
+
+	// shutdownErr records a shut down that timed out, until a Reset.
+	shutdownErr error
+
+	// lastID is the id last given to an entry, see Handle.
+	lastID atomic.Uint64
+
+	// stopped and drained are closed once shutDown and drainChannels are done with the
+	// internal channels of a run, letting Reset replace them.
+	stopped chan struct{}
+	drained chan struct{}
+
+	// closesDrained is closed once drainChannels has handled the last close notification,
+	// and finished once every OnClose and dispatched handler of the run has returned.
+	closesDrained chan struct{}
+	finished      chan struct{}
+
+	// resetMu guards the fields init replaces while Reset replaces them, against Kill,
+	// DumpState, Footprint and DispatchStats reading them from other goroutines.
+	resetMu sync.RWMutex
+
+	// onCloseWG counts the non-Blocking OnClose handlers running.
+	onCloseWG sync.WaitGroup
 
 	// listenerWG is used in clean up to make sure all children process have exited.
 	listenerWG sync.WaitGroup
+
+	// goroutines counts the running main loop, shard loops and listeners,
+	// and helpers the other goroutines started through spawn.
+	goroutines atomic.Int32
+	helpers    atomic.Int32
+
+	// goroutineLimit and reportGoroutines are set by WithGoroutineWatch,
+	// overLimit is set while GoroutineCount is above the limit.
+	goroutineLimit   int
+	reportGoroutines func(error)
+	overLimit        atomic.Bool
+
+	// groupSize is the most entries multiplexed onto one listener goroutine.
+	groupSize int
+
+	// lazyListeners parks entries in listener groups until their first message.
+	lazyListeners bool
+
+	// latencyStats enables the latency histograms of each entry's stats.
+	latencyStats bool
+
+	// middleware wraps every entry's handler, see Use.
+	middleware []Middleware
+
+	// aggregatorPolicy applies when an aggregator is full, see WithAggregatorOverflow.
+	aggregatorPolicy OverflowPolicy
+
+	// errorSink, if set, receives the errors returned by FuncErr handlers.
+	errorSink func(err *HandlerError)
+
+	// closeHook, if set, is told of each entry's listener finishing while running.
+	closeHook func(index int, name string)
+
+	// watchdog, if set, flags slow Blocking handlers, see WithSlowHandlerWatchdog.
+	watchdog *watchdog
+
+	// reverseClose defers the OnClose handlers of a shut down to reverse load order, see WithReverseClose.
+	reverseClose bool
+
+	// buffers sizes the internal channels, all unbuffered by default.
+	buffers Buffers
+
+	// batchSize is the most aggregator messages handled per pass of the state machine.
+	batchSize int
+
+	// lockFreePriority backs each shard's priority path with an mpscQueue.
+	lockFreePriority bool
+
+	// priorityLevels is the number of handler priority levels, 2 unless set by WithPriorityLevels.
+	priorityLevels int
+
+	// busyPoll is how long the main loop spins before parking, enabling low latency mode when set.
+	busyPoll time.Duration
+
+	// chaos injects faults, see WithChaos.
+	chaos *chaos
+
+	// deterministic overrides the options that would make handler order or timing vary, see WithDeterministic.
+	deterministic bool
+
+	// shards holds an aggregator pair per consumer loop, shards[0] is served by the main loop.
+	shards     []*shard
+	shardCount int
+
+	// shardWG is used in clean up to make sure all shard loops have exited.
+	shardWG sync.WaitGroup
+
+	// dispatch runs non-Blocking handlers, sized by the dispatch options.
+	dispatch          *dispatcher
+	dispatchWorkers   int
+	dispatchQueue     int
+	dispatchPolicy    OverflowPolicy
+	dispatchUnbounded bool
+}
+
+// shard is a pair of aggregators consumed by a single loop.
+// Entries are assigned to a shard by index, so messages of one entry are always handled in order.
+type shard struct {
+	aggregator         chan *dsWrapper
+	priorityAggregator chan *dsWrapper
+
+	// handlerMu serializes Blocking handlers in low latency mode,
+	// where listeners of priority entries call them directly.
+	handlerMu sync.Mutex
+
+	// priorityQueue replaces priorityAggregator for listeners when set,
+	// with priorityWake signalling the consumer that it may be non-empty.
+	priorityQueue *mpscQueue
+	priorityWake  chan struct{}
+
+	// levels holds an aggregator for each priority level above 1, lowest first, with
+	// levelWake signalling the consumer that one may be non-empty.
+	levels    []chan *dsWrapper
+	levelWake chan struct{}
+}
+
+// wakeLevels signals the shard's consumer to check its level aggregators.
+func (s *shard) wakeLevels() {
+	select {
+	case s.levelWake <- struct{}{}:
+	default:
+	}
+}
+
+// wakePriority signals the shard's consumer to check its priority queue.
+func (s *shard) wakePriority() {
+	select {
+	case s.priorityWake <- struct{}{}:
+	default:
+	}
 }
 
 // ChannelEntry is utilized to handle writes to and closure of the channel.
 // It is assumed the handler accepts the messages written to the channel.
 // The OnClose handler is expected to have no arguments.
 type ChannelEntry struct {
+	// Name, if set, identifies the entry to KillNamed and Entry, and in close notifications.
+	// It must be unique within the select. Indices shift meaning as entries come and go, names don't.
+	Name string
+
 	Channel  chan interface{}
 	Handler  HandlerEntry
 	OnClose  OnCloseEntry
 	IsClosed bool
+
+	// Reopen, if set, is called once Channel closes for a fresh channel to listen to in its place,
+	// as a network source reconnects. The entry only closes if Reopen returns nil, or the select
+	// is halting. Reopen is called on the listener's goroutine, so it may block while reconnecting.
+	// Reopen is not applied to entries built by Typed or Batched.
+	Reopen func() chan interface{}
+
+	// Heartbeat, if positive, is the longest Channel is expected to go without a message.
+	// Once it has been silent for longer, OnStall is called with how long, and an EventEntryStalled
+	// emitted, once per silent spell. A wedged upstream then shows itself.
+	Heartbeat time.Duration
+	OnStall   func(silence time.Duration)
+
+	// OnStart, if set, is called once a listener is listening to Channel, each time the select runs
+	// and for entries loaded while it runs. It is called on the listener's goroutine, which it holds up,
+	// along with any entries sharing the listener, see WithListenerGroups.
+	OnStart func()
+
+	// LoadedAt is when the entry joined the select, and Handled how many of its messages have been
+	// handled. Both are filled in by the select, and ignored if set by the caller.
+	LoadedAt time.Time
+	Handled  uint64
+
+	// Affinity, if set, pins the entry to the same shard as every other entry with the same
+	// Affinity, so their Blocking handlers never run concurrently, see WithShards. Entries without
+	// one are assigned to shards by index.
+	Affinity string
+
+	// Credit, if set, is returned a credit each time a message from Channel has been handled.
+	Credit *Credit
+
+	// RateLimit, if positive, is the most messages per second heard from Channel.
+	// The listener waits between reads, so a faster producer blocks on its sends.
+	RateLimit float64
+
+	// Coalesce, if set, collapses a burst of messages into one handler call.
+	// When a message is heard with more already waiting in Channel's buffer, the waiting ones are
+	// read too and Coalesce receives them all, oldest first. It might keep only the latest, or merge deltas.
+	// Coalesce is not applied to entries built by Typed.
+	Coalesce func(pending []interface{}) interface{}
+
+	// Transform, if set, maps each message heard from Channel before it is routed to the handler,
+	// decoding bytes into a struct once, say, rather than in every handler. It is applied after
+	// Coalesce, on the listener's goroutine. Entries built by Batched pass it each batch, and
+	// Transform is not applied to entries built by Typed.
+	Transform func(x interface{}) interface{}
+
+	// SampleEvery, if above 1, handles only the first of every SampleEvery messages heard from
+	// Channel, and SampleRate, if below 1, only that fraction of them, chosen at random. The rest
+	// are skipped and counted in Stats, so a firehose, of debug telemetry say, cannot crowd out
+	// the other entries. Neither is applied to entries built by Typed or Batched.
+	SampleEvery int
+	SampleRate  float64
+
+	// DedupeKey, if set, suppresses a message whose key was already heard from Channel within the
+	// last DedupeTTL, as when an upstream retransmits on reconnect. Keys must be comparable, and are
+	// taken after Transform. Suppressed messages are counted in Stats. Neither is applied to
+	// entries built by Typed or Batched.
+	DedupeKey func(x interface{}) interface{}
+	DedupeTTL time.Duration
+
+	// Spill, if set, keeps the listener reading from Channel while the handler falls behind,
+	// queueing messages in memory, then on disk, see Spill. It is not applied to entries built by
+	// Typed or Batched.
+	Spill *Spill
+
+	// Journal, if positive, is how many of the last messages routed to the handler are kept in
+	// memory, after sampling, Transform and DedupeKey, for Replay. Entries built by Batched
+	// journal each batch, and Journal is not applied to entries built by Typed.
+	Journal int
+
+	// typed is set for entries built by Typed, whose messages bypass Channel.
+	typed typedSource
+
+	// batch is set for entries built by Batched.
+	batch *batching
+
+	// stats is attached when the entry is loaded.
+	stats *entryStats
+
+	// dedupe remembers recent keys, attached when loaded if DedupeKey is set.
+	dedupe *deduper
+
+	// journal keeps recent messages, attached when loaded if Journal is positive.
+	journal *journal
+
+	// stop is closed by KillNamed and Handle.Kill, it is attached to every entry when loaded.
+	stop chan struct{}
+
+	// signals is set for entries built by SignalEntry, its relay starts when first listened to.
+	signals *signalRelay
+
+	// trace holds the runtime/trace task of the entry's listener, attached when loaded.
+	trace *entryTrace
+
+	// id identifies the entry to its Handle, set when loaded.
+	id uint64
+
+	// shardKey picks the entry's shard, from its Affinity or index, set when loaded.
+	shardKey uint32
+
+	// started, if set, is called once a listener is listening to the entry, see LoadWait.
+	started func()
+
+	// handler is Handler.Func wrapped in the select's middleware, set when loaded.
+	handler HandlerFunc
+
+	// slots caps the handler calls running at once, set when loaded if Handler.MaxConcurrency is.
+	slots *Credit
+
+	// bound holds the handler last bound to the entry, shared by every copy of the entry so
+	// ReplaceHandler reaches listeners, set when loaded.
+	bound *atomic.Pointer[binding]
+}
+
+// binding is a handler bound to a loaded entry.
+type binding struct {
+	Handler HandlerEntry
+	handler HandlerFunc
+}
+
+// current returns the entry with the handler last bound to it.
+func (e ChannelEntry) current() ChannelEntry {
+	if e.bound == nil {
+		return e
+	}
+
+	b := e.bound.Load()
+	e.Handler, e.handler = b.Handler, b.handler
+	return e
 }
 
 // HandlerEntry is a function that will be called with the message emitted
-// by the associated channel.
+// by the associated channel. A nil Func discards messages.
 type HandlerEntry struct {
 	Func func(i interface{})
 
-	// Blocking determines whether it will be run in a goroutine (Blocking = false)
+	// FuncContext, used when Func is nil, is also passed the select's Context, cancelled once
+	// the select is killed, or the entry is, by KillNamed or its Handle. A long running handler
+	// may then give up cooperatively.
+	FuncContext func(ctx context.Context, i interface{})
+
+	// FuncErr, used when Func and FuncContext are nil, may fail.
+	// Its errors are passed to the select's error sink, see WithErrorSink.
+	FuncErr func(i interface{}) error
+
+	// FuncOut, used when Func, FuncContext and FuncErr are nil, returns a result. Results that are
+	// not nil are sent to Output, so the select may feed another as a stage of a pipeline.
+	// The handler waits on the send until Output is read, or the select is killed and the result
+	// dropped. If Output is nil results are passed to Results and Collect calls in progress, or
+	// dropped if there are none.
+	FuncOut func(i interface{}) interface{}
+	Output  chan<- interface{}
+
+	// Blocking determines whether it will be run by the dispatcher's workers (Blocking = false)
 	// or synchronously (Blocking = true), the latter blocking reading other messages
 	// set to Blocking from the queue.
 	// A non-Blocking call may occur duing a Blocking call.
@@ -86,406 +443,1769 @@ type HandlerEntry struct {
 	// If priority is set to true. will be checked for during the priority phase.
 	// Non-blocking calls are processed faster than Priority calls. Setting both to
 	// true will result in Non-blocking behavior.
+	// Priority is equivalent to Level 1, and ignored when Level is set.
 	Priority bool
+
+	// MaxConcurrency, if positive, is the most calls of a non-Blocking handler run at once for the
+	// entry. Further messages wait in the listener, holding up the entry's producer, until a call
+	// returns. It is fixed once the entry is loaded.
+	MaxConcurrency int
+
+	// Level is the priority level of a Blocking handler, messages of higher levels are handled first.
+	// Level 0 is the regular tier and level 1 the priority tier. Levels above 1 need WithPriorityLevels,
+	// without it they share level 1.
+	Level int
+}
+
+// level is the effective priority level of the handler.
+func (h HandlerEntry) level() int {
+	if h.Level == 0 && h.Priority {
+		return 1
+	}
+
+	return h.Level
 }
 
 // OnCloseEntry is a function that will be called the associated channel closes.
 // Blocking determines whether it will be run in a goroutine (Blocking = false) or
 // synchronously (Blocking = true), the latter blocking reading other Blocking
 // messages from the queue. If not Blocking, is read during the priority tier.
-// It will be called during the shut down of DynamicSelect.
+// It will be called during the shut down of DynamicSelect. A nil Func does nothing.
 type OnCloseEntry struct {
-	Func     func()
+	Func func()
+
+	// FuncReason, used when Func is nil, is also passed why the entry closed.
+	FuncReason func(reason CloseReason)
+
 	Blocking bool
 }
 
+// call calls the OnClose handler of an entry that closed for the given reason.
+func (o OnCloseEntry) call(reason CloseReason) {
+	if o.Func == nil && o.FuncReason != nil {
+		o.FuncReason(reason)
+		return
+	}
+
+	o.Func()
+}
+
+// CloseReason is why an entry closed, see OnCloseEntry.FuncReason.
+type CloseReason int
+
+const (
+	// ClosedChannel is an entry whose channel was closed, the orderly end of its producer.
+	ClosedChannel CloseReason = iota
+
+	// ClosedKilled is an entry closed as its select was killed.
+	ClosedKilled
+
+	// ClosedEntryKilled is an entry killed alone, by KillNamed or its Handle.
+	ClosedEntryKilled
+
+	// ClosedPanicked is an entry whose listener panicked, a failure upstream of its handler.
+	ClosedPanicked
+)
+
+func (r CloseReason) String() string {
+	switch r {
+	case ClosedChannel:
+		return "channel closed"
+	case ClosedKilled:
+		return "select killed"
+	case ClosedEntryKilled:
+		return "entry killed"
+	case ClosedPanicked:
+		return "listener panicked"
+	default:
+		return "unknown"
+	}
+}
+
 // Simple way to track channels to handlers.
 type dsWrapper struct {
 	Index  int
 	Target interface{}
+
+	// Enqueued is when the listener heard Target, set if latency is measured.
+	Enqueued time.Time
+
+	// next links wrappers in an mpscQueue.
+	next atomic.Pointer[dsWrapper]
 }
 
 type closeWrapper struct {
-	Index int
-	Entry ChannelEntry
+	Index  int
+	Entry  ChannelEntry
+	Reason CloseReason
+
+	// Called is set once the listener has called a non-Blocking OnClose itself.
+	Called bool
 }
 
-// NewDynamicSelect uses an action to take on kill command, along with a list of channels to manage and returns a fully initialize DynamicSelect.
-func NewDynamicSelect(onKillAction func(), channels []ChannelEntry) *DynamicSelect {
-	// both aggregators, on close notifier, and internal kill chan.
-	a := make(chan dsWrapper)
-	p := make(chan dsWrapper)
-	o := make(chan closeWrapper)
-	d := make(chan interface{})
+// attach prepares an entry joining the select: missing handlers become no-ops, so partially
+// filled entries degrade gracefully rather than panicking inside the run loop, and stats are attached.
+func (d *DynamicSelect) attach(i int, e ChannelEntry) ChannelEntry {
+	if e.stop == nil {
+		e.stop = make(chan struct{})
+	}
 
-	// guarded channels
-	k := make(chan interface{}, 1)
-	kg := make(chan interface{}, 1)
-	l := make(chan []ChannelEntry)
-	lg := make(chan interface{}, 1)
-
-	// prime the guards.
-	kg <- unit
-	lg <- unit
-
-	return &DynamicSelect{
-		onKillAction:       onKillAction,
-		load:               l,
-		loadGuard:          lg,
-		channels:           channels,
-		aggregator:         a,
-		alive:              true,
-		done:               d,
-		kill:               k,
-		killGuard:          kg,
-		killHeard:          false,
-		priorityAggregator: p,
-		onClose:            o,
+	if e.trace == nil {
+		e.trace = newEntryTrace(i, e.Name)
 	}
-}
 
-// Forever runs the DynamicSelect with its current Channels.
-// Will close ready once initialized.
-// For each channel listed, it will call handlers when messages are received
-// and call onClose functions for when they are closed.
-// If a message is heard on the DynamicSelect's Kill channel, the select is halted and
-// all contained channels are closed.
-func (d *DynamicSelect) Forever(ready chan interface{}) {
-	// Set up defer for clean up:
-	defer d.shutDown()
+	e.Handler, e.handler = d.bind(i, e, e.Handler)
+	e.shardKey = uint32(i)
+	if e.Affinity != "" {
+		h := fnv.New32a()
+		h.Write([]byte(e.Affinity))
+		e.shardKey = h.Sum32()
+	}
+	if e.id == 0 {
+		e.id = d.lastID.Add(1)
+	}
 
-	d.running = true
+	e.LoadedAt = d.clock.Now()
 
-	// Start funneling messages into aggregator.
-	d.startListeners()
-	close(ready)
+	e.bound = &atomic.Pointer[binding]{}
+	e.bound.Store(&binding{Handler: e.Handler, handler: e.handler})
 
-	for {
-		// If a kill command is heard in any of the operations...
-		d.alive = d.stateMachine()
-		if !d.alive {
-			// ...bail out!
-			return
-		}
+	if e.OnClose.Func == nil && e.OnClose.FuncReason == nil {
+		e.OnClose.Func = noopOnClose
 	}
-}
 
-// IsAlive reports if the DynamicSelect is running.
-func (d *DynamicSelect) IsAlive() bool {
-	return d.alive && !d.killHeard
-}
+	if e.DedupeKey != nil && e.dedupe == nil {
+		e.dedupe = newDeduper(e.DedupeTTL)
+	}
 
-// Kill issues a non-blocking, safe kill command to the dynamic select.
-func (d *DynamicSelect) Kill() {
-	if !d.IsAlive() {
-		return
+	if e.Journal > 0 && e.journal == nil {
+		e.journal = newJournal(e.Journal)
 	}
 
-	<-d.killGuard
-	if d.IsAlive() {
-		d.killHeard = true
-		d.kill <- unit
+	if e.Handler.MaxConcurrency > 0 {
+		e.slots = NewCredit(e.Handler.MaxConcurrency)
 	}
-	d.killGuard <- unit
+
+	e.stats = newEntryStats(d.latencyStats)
+	return e
 }
 
-// Load either blocks until the given ChannelEntry is loaded into a running DynamicSelect
-// or informs via error that the DynamicSelect has halted.
-func (d *DynamicSelect) Load(c []ChannelEntry) error {
-	if !d.IsAlive() {
-		return fmt.Errorf("DynamicSelect has either halted or is uninitialized")
+// bind fills in the Func of a handler for the entry e at index i, returning it with Func wrapped
+// in the select's middleware, and traced while runtime/trace is enabled.
+func (d *DynamicSelect) bind(i int, e ChannelEntry, h HandlerEntry) (HandlerEntry, HandlerFunc) {
+	name := e.Name
+	if h.Func == nil && h.FuncContext != nil {
+		f, ctx := h.FuncContext, &entryContext{d: d, stop: e.stop}
+		h.Func = func(i interface{}) {
+			f(ctx.get(), i)
+		}
 	}
 
-	if !d.running {
-		return fmt.Errorf("DynamicSelect has not been started, this could otherwise deadlock")
+	if h.Func == nil && h.FuncErr != nil {
+		f := h.FuncErr
+		h.Func = func(x interface{}) {
+			if err := f(x); err != nil {
+				d.reportError(&HandlerError{Index: i, Name: name, Message: x, Err: err})
+			}
+		}
 	}
 
-	d.load <- c
-	return nil
+	if h.Func == nil && h.FuncOut != nil {
+		f, out := h.FuncOut, h.Output
+		h.Func = func(x interface{}) {
+			d.output(out, f(x))
+		}
+	}
+
+	if h.Func == nil {
+		h.Func = noopHandler
+	}
+
+	return h, d.traced(e.trace, d.chain(d.delayed(h.Func)))
 }
 
-// global empty var.
-var unit interface{}
+// output sends a result other than nil, returned by a FuncOut handler, to out, or to any Results
+// calls if out is nil.
+func (d *DynamicSelect) output(out chan<- interface{}, result interface{}) {
+	if result == nil {
+		return
+	}
 
-// Once all listeners hit done, exit.
-func (d *DynamicSelect) shutDown() {
-	if r := recover(); r != nil {
-		log.Printf("Recovered from panic in main DynamicSelect: %v\n", r)
-		log.Println("Attempting normal shutdown.")
+	if out == nil {
+		d.collect(result)
+		return
 	}
 
-	// just making sure.
-	d.killHeard = true
-	d.alive = false
-	d.running = false
-	close(d.done)
+	select {
+	case out <- result:
+	case <-d.done:
+	}
+}
 
-	// Tell the outside world we're done.
-	d.onKillAction()
+func noopHandler(i interface{}) {}
 
-	// Handle outstanding requests / a flood of closed messages.
-	go d.drainChannels()
+func noopOnClose() {}
 
-	// Wait for internal listeners to halt.
-	d.listenerWG.Wait()
+// Wrappers are recycled so the steady-state path from listener to handler does not allocate.
+var (
+	wrapperPool      = sync.Pool{New: func() interface{} { return new(dsWrapper) }}
+	closeWrapperPool = sync.Pool{New: func() interface{} { return new(closeWrapper) }}
+)
 
-	// Make it painfully clear to the GC.
-	close(d.aggregator)
-	close(d.priorityAggregator)
-	close(d.onClose)
+func getWrapper(index int, target interface{}) *dsWrapper {
+	w := wrapperPool.Get().(*dsWrapper)
+	w.Index = index
+	w.Target = target
+	return w
 }
 
-// First, check if a kill command was heard during the previous process...
-func (d *DynamicSelect) stateMachine() bool {
-	select {
-	case <-d.kill:
-		return false
+// putWrapper returns w to the pool, the caller must not touch w afterwards.
+func putWrapper(w *dsWrapper) {
+	w.Target = nil
+	wrapperPool.Put(w)
+}
 
-	default:
-		return d.priorityMessageState()
-	}
+func getCloseWrapper(index int, entry ChannelEntry, reason CloseReason) *closeWrapper {
+	w := closeWrapperPool.Get().(*closeWrapper)
+	w.Index = index
+	w.Entry = entry
+	w.Reason = reason
+	return w
 }
 
-// Then, check if any channel closed (a one-time event) in addition to priority events and the kill command.
-func (d *DynamicSelect) priorityMessageState() bool {
-	select {
-	case ocw := <-d.onClose:
-		go d.updateChannels(ocw)
-		d.handleOnClose(ocw.Index)
-		return true
+// putCloseWrapper returns w to the pool, the caller must not touch w afterwards.
+func putCloseWrapper(w *closeWrapper) {
+	w.Entry = ChannelEntry{}
+	w.Called = false
+	closeWrapperPool.Put(w)
+}
 
-	case dsw := <-d.priorityAggregator:
-		d.handleInternal(dsw)
-		return true
+// NewDynamicSelect uses an action to take on kill command, along with a list of channels to manage and returns a fully initialize DynamicSelect.
+// Options may be supplied to tune its behavior, the defaults match a plain tiered select.
+func NewDynamicSelect(onKillAction func(), channels []ChannelEntry, opts ...Option) *DynamicSelect {
+	d := &DynamicSelect{
+		batchSize:       1,
+		shardCount:      1,
+		priorityLevels:  2,
+		dispatchWorkers: availableCPUs() * dispatchWorkersPerCPU,
+		dispatchQueue:   availableCPUs() * dispatchWorkersPerCPU * dispatchQueuePerWorker,
+		drainGrace:      time.Second,
+		logLevel:        LogWarn,
+		clock:           clock.Real{},
+	}
 
-	case <-d.kill:
-		return false
+	d.OnShutdown(0, onKillAction)
 
-	default:
-		return d.allMessageState()
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	// Whatever other options asked for, see WithDeterministic.
+	if d.deterministic {
+		d.shardCount = 1
+		d.busyPoll = 0
+		d.dispatchWorkers = 1
+		d.dispatchPolicy = OverflowBlock
+		d.dispatchUnbounded = false
+	}
+
+	// Take a copy, the select annotates its entries.
+	d.channels = make([]ChannelEntry, len(channels))
+	copy(d.channels, channels)
+	for i := range d.channels {
+		d.channels[i] = d.attach(i, d.channels[i])
 	}
+
+	d.alive.Store(true)
+
+	d.events = make(chan Event, eventBuffer)
+	d.drops = make(chan Drop, dropBuffer)
+	d.stateChanges = make(chan LifecycleState, stateBuffer)
+	d.init()
+
+	return d
 }
 
-// Finally, react to any event FIFO.
-func (d *DynamicSelect) allMessageState() bool {
-	select {
+// init creates the internal channels used by a single run of the DynamicSelect.
+func (d *DynamicSelect) init() {
+	// both aggregators, on close notifier, and internal kill chan.
+	d.aggregator = make(chan *dsWrapper, d.buffers.Aggregator)
+	d.priorityAggregator = make(chan *dsWrapper, d.buffers.PriorityAggregator)
+	d.onClose = make(chan *closeWrapper, d.buffers.OnClose)
+	d.done = make(chan interface{})
+	d.ready = make(chan struct{})
 
-	case dsw := <-d.priorityAggregator:
-		d.handleInternal(dsw)
-		return true
+	// guarded channels
+	d.kill = make(chan interface{}, 1)
+	d.killOnce = &sync.Once{}
+	d.load = make(chan []ChannelEntry, d.buffers.Load)
+
+	// closed once shutDown and drainChannels have finished with the above.
+	d.stopped = make(chan struct{})
+	d.drained = make(chan struct{})
+	d.closesDrained = make(chan struct{})
+	d.finished = make(chan struct{})
+
+	d.shards = []*shard{{aggregator: d.aggregator, priorityAggregator: d.priorityAggregator}}
+	for len(d.shards) < d.shardCount {
+		d.shards = append(d.shards, &shard{
+			aggregator:         make(chan *dsWrapper, d.buffers.Aggregator),
+			priorityAggregator: make(chan *dsWrapper, d.buffers.PriorityAggregator),
+		})
+	}
 
-	case dsw := <-d.aggregator:
-		d.handleInternal(dsw)
-		return true
+	// The level aggregators are buffered, as listeners wake the consumer only once their send is done.
+	levelBuffer := d.buffers.PriorityAggregator
+	if levelBuffer < 1 {
+		levelBuffer = 1
+	}
 
-	case nextList := <-d.load:
-		for _, next := range nextList {
-			<-d.loadGuard
-			// Grab the current len, and thus next index.
-			nextIndex := len(d.channels)
-			// Add next
-			d.channels = append(d.channels, next)
-			d.loadGuard <- unit
-			// Create New Listener
-			d.listenerWG.Add(1)
-			go d.startListener(nextIndex, next)
+	for _, s := range d.shards {
+		for l := 2; l < d.priorityLevels; l++ {
+			s.levels = append(s.levels, make(chan *dsWrapper, levelBuffer))
 		}
 
-		return true
+		if len(s.levels) > 0 {
+			s.levelWake = make(chan struct{}, 1)
+		}
+	}
 
-	case ocw := <-d.onClose:
-		go d.updateChannels(ocw)
-		d.handleOnClose(ocw.Index)
-		return true
+	if d.lockFreePriority {
+		for _, s := range d.shards {
+			s.priorityQueue = newMPSCQueue()
+			s.priorityWake = make(chan struct{}, 1)
+		}
+	}
 
-	case <-d.kill:
-		return false
+	d.dispatch = newDispatcher(d.dispatchWorkers, d.dispatchQueue, d.dispatchPolicy, d.dispatchUnbounded, d.done)
+}
+
+// NewValidatedDynamicSelect is NewDynamicSelect for callers that would rather fail fast:
+// the channels are validated first, see ValidateEntries, returning every problem found.
+func NewValidatedDynamicSelect(onKillAction func(), channels []ChannelEntry, opts ...Option) (*DynamicSelect, error) {
+	if err := ValidateEntries(channels); err != nil {
+		return nil, err
 	}
+
+	return NewDynamicSelect(onKillAction, channels, opts...), nil
 }
 
-func (d *DynamicSelect) updateChannels(ocw closeWrapper) {
-	<-d.loadGuard
-	d.channels[ocw.Index] = ocw.Entry
-	d.loadGuard <- unit
+// Forever runs the DynamicSelect with its current Channels.
+// Will close ready once initialized.
+// For each channel listed, it will call handlers when messages are received
+// and call onClose functions for when they are closed.
+// If a message is heard on the DynamicSelect's Kill channel, the select is halted and
+// all contained channels are closed.
+//
+// Deprecated: use Run, which reports why the select stopped, and Ready.
+func (d *DynamicSelect) Forever(ready chan interface{}) {
+	d.RunContext(context.Background(), ready)
 }
 
-func (d *DynamicSelect) startListeners() {
-	// For each channel and handler
-	for index, entry := range d.channels {
-		// Start a go routine with the current channel
-		d.listenerWG.Add(1)
-		go d.startListener(index, entry)
-		<-d.loadGuard
-		d.channels[index].IsClosed = false
-		d.loadGuard <- unit
+// Run runs the DynamicSelect as RunContext does, blocking until it has finished shutting down,
+// see Wait. Ready is closed once it is running. Run reports why the select stopped: ErrKilled
+// after Kill, the context's cause once ctx is done, a *PanicError, or whatever was passed to
+// KillWithReason, joined with a *ShutdownTimeoutError if shutting down timed out.
+func (d *DynamicSelect) Run(ctx context.Context) error {
+	if !d.started.CompareAndSwap(false, true) {
+		return ErrAlreadyRun
 	}
+
+	d.run(ctx, make(chan interface{}))
+	d.Wait()
+
+	return errors.Join(d.KillReason(), d.Err())
 }
 
-func (d *DynamicSelect) Channels() []ChannelEntry {
-	<-d.loadGuard
-	c := d.channels
-	d.loadGuard <- unit
-	return c
+// Ready returns a channel closed once the current run is listening to its Channels, as the ready
+// channel passed to Forever is. A Reset replaces it.
+func (d *DynamicSelect) Ready() <-chan struct{} {
+	return d.ready
 }
 
-// Start listener either passes messages to the aggregator channels or calls handlers locally
-// Depending on the entry supplied.
-func (d *DynamicSelect) startListener(i int, e ChannelEntry) {
-	e.IsClosed = false
+// RunContext runs the DynamicSelect as Forever does, additionally treating the cancellation
+// of ctx as a Kill. Handlers set with FuncContext are passed a Context derived from ctx.
+func (d *DynamicSelect) RunContext(ctx context.Context, ready chan interface{}) {
+	d.started.Store(true)
+	d.run(ctx, ready)
+}
 
-	// Clean up on close.
-	defer func() {
-		// We don't control the channels passed in. We may hit a runtime panic if they are closed.
-		if r := recover(); r != nil {
-			log.Printf("Recovered but exiting in DynamicSelect select listener. Likely attempted to read on a closed channel, error: %v\n", r)
+// run is RunContext, once started is set.
+func (d *DynamicSelect) run(ctx context.Context, ready chan interface{}) {
+	d.ctx, d.cancel = context.WithCancel(ctx)
 
-			// This is likely true, but a panic in a handler may trip this.
-			e.IsClosed = true
-		}
+	d.watchSignals(d.done)
 
-		// check for Blocking
-		if !e.OnClose.Blocking {
-			go e.OnClose.Func()
-		}
+	if ctx.Done() != nil {
+		done := d.done
+		d.spawn(func() {
+			select {
+			case <-ctx.Done():
+				d.KillWithReason(context.Cause(ctx))
+			case <-done:
+			}
+		})
+	}
 
-		// Otherwise pass to main handler
-		lastMessage := closeWrapper{
-			Index: i,
-			Entry: e,
-		}
-		d.onClose <- lastMessage
+	d.goroutines.Add(1)
+	defer d.goroutines.Add(-1)
 
-		// Free up the waitgroup for shutdown.
-		d.listenerWG.Done()
-	}()
+	// Set up defer for clean up:
+	defer d.shutDown()
+
+	d.running.Store(true)
+	d.transition(StateStarting)
+
+	// Start the loops for any additional shards, then funnel messages into the aggregators.
+	d.startShards()
+	d.startListeners()
+	close(ready)
+	close(d.ready)
+	d.transition(StateRunning)
+	d.emit(EventStarted, -1, "", nil)
 
 	for {
-		// If using non-blocking handlers, we must check the select
+		// If a kill command is heard in any of the operations...
+		alive := d.stateMachine()
+		d.alive.Store(alive)
+		if !alive {
+			// ...bail out!
+			return
+		}
+	}
+}
+
+// IsAlive reports if the DynamicSelect is running.
+func (d *DynamicSelect) IsAlive() bool {
+	return d.alive.Load() && !d.killHeard.Load()
+}
+
+// Kill issues a non-blocking, safe kill command to the dynamic select.
+func (d *DynamicSelect) Kill() {
+	d.KillWithReason(ErrKilled)
+}
+
+// KillWithReason is Kill, recording why the select was killed for KillReason and the
+// WithKillReasonAction callback. Only the first kill's reason is kept. A nil reason is ErrKilled.
+// It is safe to call concurrently, with itself, Kill and IsAlive, the kill is issued once.
+func (d *DynamicSelect) KillWithReason(reason error) {
+	if !d.IsAlive() {
+		return
+	}
+
+	if reason == nil {
+		reason = ErrKilled
+	}
+
+	d.resetMu.RLock()
+	defer d.resetMu.RUnlock()
+
+	d.killOnce.Do(func() {
+		d.startSend()
+		defer d.endSend()
+		if !d.IsAlive() {
+			return
+		}
+
+		d.killHeard.Store(true)
+		d.setKillReason(reason)
+		d.kill <- unit
+		d.emit(EventKillReceived, -1, "", reason)
+	})
+}
+
+// KillReason reports why the select was killed: the reason given to KillWithReason, ErrKilled
+// for Kill, the context's cause when run by RunContext, or a *PanicError. It is nil until killed.
+func (d *DynamicSelect) KillReason() error {
+	d.reasonMu.Lock()
+	defer d.reasonMu.Unlock()
+	return d.killReason
+}
+
+func (d *DynamicSelect) setKillReason(reason error) {
+	d.reasonMu.Lock()
+	d.killReason = reason
+	d.reasonMu.Unlock()
+}
+
+// Context returns the Context of the current run, cancelled once the select is killed.
+// Before the select is first run it is context.Background().
+func (d *DynamicSelect) Context() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+
+	return d.ctx
+}
+
+// Wait blocks until a killed DynamicSelect has finished shutting down: its listeners and
+// shard loops have exited and its OnClose and dispatched handlers have returned. It returns at
+// once if the DynamicSelect was never started. Past a shutdown timeout, see WithShutdownTimeout,
+// it returns without waiting on whatever is stuck.
+func (d *DynamicSelect) Wait() {
+	if !d.started.Load() {
+		return
+	}
+
+	<-d.finished
+}
+
+// Done returns a channel closed once the current run has finished shutting down, as Wait waits for.
+// A Reset replaces it.
+func (d *DynamicSelect) Done() <-chan struct{} {
+	return d.finished
+}
+
+// Err reports why the last run failed to shut down cleanly, a *ShutdownTimeoutError, or nil.
+// It is only meaningful once Wait returns.
+func (d *DynamicSelect) Err() error {
+	return d.shutdownErr
+}
+
+// Reset readies a DynamicSelect halted by Kill to run again with its last known Channels,
+// first waiting for the previous run to finish shutting down. Resetting a DynamicSelect that has
+// never run does nothing, resetting one that is still running is an error.
+// Entries whose channels closed in the meantime are reported closed again once running.
+func (d *DynamicSelect) Reset() error {
+	if !d.started.Load() {
+		return nil
+	}
+
+	if d.IsAlive() {
+		return fmt.Errorf("%w, it must be killed before it can be reset", ErrRunning)
+	}
+
+	<-d.stopped
+	if d.shutdownErr != nil {
+		return fmt.Errorf("DynamicSelect can't be reset, its last run did not shut down: %w", d.shutdownErr)
+	}
+	<-d.drained
+	<-d.finished
+
+	d.resetMu.Lock()
+	d.init()
+	d.resetMu.Unlock()
+
+	// Loads the last run never took in don't hold on to their Names.
+	d.loadMu.Lock()
+	d.reserved = nil
+	d.loadMu.Unlock()
+
+	d.killHeard.Store(false)
+	d.setKillReason(nil)
+	d.alive.Store(true)
+	d.started.Store(false)
+	d.transition(StateIdle)
+
+	// A child was let go of once it shut down, its next run is bounded by its parent again.
+	if d.parent != nil {
+		d.parent.adopt(d)
+	}
+
+	return nil
+}
+
+// Load either blocks until the given ChannelEntry is loaded into a running DynamicSelect
+// or informs via error that the DynamicSelect has halted.
+// Nothing is loaded if any entry has a nil Channel or shares a Name; entries the select can run,
+// if perhaps not as intended, are loaded, see ValidateEntries to check those too.
+// A Handle is returned for each entry, in order, to find it again whatever index it lands on.
+func (d *DynamicSelect) Load(c []ChannelEntry) ([]Handle, error) {
+	return d.loadEntries(c, nil)
+}
+
+// LoadWait loads the entries as Load does, then blocks until a listener is listening to each of
+// them, so a message sent as soon as it returns is heard. It errors if the select halts first.
+func (d *DynamicSelect) LoadWait(c []ChannelEntry) ([]Handle, error) {
+	done := d.done
+
+	var wg sync.WaitGroup
+	wg.Add(len(c))
+	handles, err := d.loadEntries(c, wg.Done)
+	if err != nil {
+		return nil, err
+	}
+
+	listening := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(listening)
+	}()
+
+	select {
+	case <-listening:
+		return handles, nil
+	case <-done:
+		return handles, fmt.Errorf("%w before every entry was listened to", ErrHalted)
+	}
+}
+
+// loadEntries is Load, calling started once for each entry listened to if it is given.
+func (d *DynamicSelect) loadEntries(c []ChannelEntry, started func()) ([]Handle, error) {
+	// Counted before checking, so drainChannels either sees this call or it sees the select halted.
+	d.startSend()
+	defer d.endSend()
+
+	if !d.IsAlive() {
+		return nil, ErrHalted
+	}
+
+	if !d.running.Load() {
+		return nil, fmt.Errorf("%w, this could otherwise deadlock", ErrNotRunning)
+	}
+
+	if err := checkLoadable(c); err != nil {
+		return nil, err
+	}
+
+	d.loadMu.Lock()
+	v := &ValidationError{}
+	for k, e := range c {
+		_, taken := d.indexOf(e.Name)
+		if _, reserved := d.reserved[e.Name]; taken || reserved {
+			v.Entries = append(v.Entries, &EntryError{Index: k, Field: "Name", Problem: fmt.Sprintf("%q is already loaded", e.Name)})
+		}
+	}
+	if len(v.Entries) > 0 {
+		d.loadMu.Unlock()
+		return nil, v
+	}
+	d.reserve(c)
+	d.loadMu.Unlock()
+
+	// Copied, as the entries are annotated with their ids.
+	c = append([]ChannelEntry(nil), c...)
+	handles := make([]Handle, len(c))
+	for k := range c {
+		c[k].id = d.lastID.Add(1)
+		if started != nil {
+			c[k].started = sync.OnceFunc(started)
+		}
+		handles[k] = Handle{d: d, id: c[k].id}
+	}
+
+	d.load <- c
+	return handles, nil
+}
+
+// LoadNamed loads a single entry under the given name, as Load does.
+func (d *DynamicSelect) LoadNamed(name string, e ChannelEntry) (Handle, error) {
+	e.Name = name
+	handles, err := d.Load([]ChannelEntry{e})
+	if err != nil {
+		return Handle{}, err
+	}
+
+	return handles[0], nil
+}
+
+// KillNamed stops listening to the named entry, leaving its channel open.
+// The entry is then reported closed and its OnClose handler called, as if its channel had closed.
+func (d *DynamicSelect) KillNamed(name string) error {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	i, ok := d.indexOf(name)
+	if !ok {
+		return fmt.Errorf("%w: no entry named %q", ErrNoEntry, name)
+	}
+
+	return d.killAt(i)
+}
+
+// killAt closes the stop channel of the entry at index i. The caller must hold loadMu.
+func (d *DynamicSelect) killAt(i int) error {
+	e := d.channels[i]
+	select {
+	case <-e.stop:
+		return fmt.Errorf("%w: entry %d has already been killed", ErrEntryClosed, i)
+	default:
+		close(e.stop)
+	}
+
+	return nil
+}
+
+// Entry returns the named entry and its index within Channels.
+func (d *DynamicSelect) Entry(name string) (ChannelEntry, int, bool) {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	i, ok := d.indexOf(name)
+	if !ok {
+		return ChannelEntry{}, -1, false
+	}
+
+	return d.channels[i], i, true
+}
+
+// indexOf finds the index of the named entry. The caller must hold loadMu.
+func (d *DynamicSelect) indexOf(name string) (int, bool) {
+	if name == "" {
+		return -1, false
+	}
+
+	for i, e := range d.channels {
+		if e.Name == name {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
+
+// global empty var.
+var unit interface{}
+
+// Once all listeners hit done, exit.
+func (d *DynamicSelect) shutDown() {
+	if r := recover(); r != nil {
+		d.logf(LogError, "Recovered from panic in main DynamicSelect: %v\n", r)
+		d.logf(LogError, "Attempting normal shutdown.\n")
+
+		d.setKillReason(&PanicError{Value: r})
+		d.emit(EventHandlerPanicked, -1, "", d.KillReason())
+	}
+
+	// just making sure.
+	d.killHeard.Store(true)
+	d.alive.Store(false)
+	d.running.Store(false)
+	d.transition(StateDraining)
+
+	// Children go first, while the listeners still hold off their OnClose handlers.
+	d.killChildren()
+	close(d.done)
+	d.cancel()
+
+	// Tell the outside world we're done.
+	d.runShutdownHooks()
+	if d.onKillReason != nil {
+		d.onKillReason(d.KillReason())
+	}
+
+	// Handle outstanding requests / a flood of closed messages.
+	d.spawn(d.drainChannels)
+	d.watchLeaks()
+
+	// Wait for internal listeners and shard loops to halt.
+	if !d.awaitListeners() {
+		// Give up on the stragglers, they close the internal channels if they ever return.
+		d.shutdownErr = &ShutdownTimeoutError{Timeout: d.shutdownTimeout, Entries: d.stuckEntries()}
+		d.logf(LogError, "DynamicSelect forced to shut down: %v\n", d.shutdownErr)
+
+		closeInternal := d.closeInternal()
+		d.spawn(func() {
+			d.listenerWG.Wait()
+			d.shardWG.Wait()
+			d.awaitSenders()
+			closeInternal()
+		})
+
+		close(d.stopped)
+		close(d.finished)
+		d.transition(StateStopped)
+		d.emit(EventShutdownComplete, -1, "", d.shutdownErr)
+		return
+	}
+
+	// Broadcasts under way route to the aggregators and dispatcher as the listeners did.
+	d.awaitSenders()
+	d.closeInternal()()
+	close(d.stopped)
+
+	closesDrained, finished, dispatch := d.closesDrained, d.finished, d.dispatch
+	d.spawn(func() { d.finish(closesDrained, finished, dispatch) })
+}
+
+// finish closes finished once the last close notification is handled and every OnClose and
+// dispatched handler has returned.
+func (d *DynamicSelect) finish(closesDrained, finished chan struct{}, dispatch *dispatcher) {
+	<-closesDrained
+	d.onCloseWG.Wait()
+	dispatch.wait()
+	close(finished)
+	d.transition(StateStopped)
+	d.emit(EventShutdownComplete, -1, "", nil)
+}
+
+// awaitListeners waits for the listeners and shard loops to halt, reporting false if they
+// did not within the shutdown timeout.
+func (d *DynamicSelect) awaitListeners() bool {
+	if d.shutdownTimeout <= 0 {
+		d.listenerWG.Wait()
+		d.shardWG.Wait()
+		return true
+	}
+
+	halted := make(chan struct{})
+	d.spawn(func() {
+		d.listenerWG.Wait()
+		d.shardWG.Wait()
+		close(halted)
+	})
+
+	timer := d.clock.NewTimer(d.shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-halted:
+		return true
+	case <-timer.C():
+		return false
+	}
+}
+
+// closeInternal returns a func closing the internal channels of the current run,
+// captured now so it is safe to call late.
+func (d *DynamicSelect) closeInternal() func() {
+	dispatch, shards, onClose := d.dispatch, d.shards, d.onClose
+
+	return func() {
+		// Listeners were the last to submit work, let the workers finish up.
+		dispatch.close()
+
+		// Make it painfully clear to the GC.
+		for _, s := range shards {
+			close(s.aggregator)
+			close(s.priorityAggregator)
+			for _, l := range s.levels {
+				close(l)
+			}
+		}
+		close(onClose)
+	}
+}
+
+// stuckEntries lists the entries still being listened to or handled.
+func (d *DynamicSelect) stuckEntries() []StuckEntry {
+	stuck := []StuckEntry{}
+	for i, e := range d.Channels() {
+		if e.stats == nil {
+			continue
+		}
+
+		listening, handling := e.stats.listening.Load(), e.stats.handling.Load() > 0
+		if listening || handling {
+			stuck = append(stuck, StuckEntry{Index: i, Name: e.Name, Listening: listening, Handling: handling})
+		}
+	}
+
+	return stuck
+}
+
+// First, check if a kill command was heard during the previous process...
+func (d *DynamicSelect) stateMachine() bool {
+	select {
+	case <-d.kill:
+		return false
+
+	default:
+		return d.priorityMessageState()
+	}
+}
+
+// Then, check if any channel closed (a one-time event) in addition to priority events and the kill command.
+func (d *DynamicSelect) priorityMessageState() bool {
+	d.tier.Store(int32(tierPriority))
+
+	if d.handleLevels(d.shards[0]) {
+		return true
+	}
+
+	select {
+	case ocw := <-d.onClose:
+		d.handleClosed(ocw)
+		return true
+
+	case dsw := <-d.priorityAggregator:
+		d.handleInternal(dsw)
+		return true
+
+	case <-d.shards[0].priorityWake:
+		d.handlePriorityQueue(d.shards[0])
+		return true
+
+	case <-d.kill:
+		return false
+
+	default:
+		return d.allMessageState()
+	}
+}
+
+// Finally, react to any event FIFO.
+func (d *DynamicSelect) allMessageState() bool {
+	d.tier.Store(int32(tierAll))
+
+	if d.busyPoll > 0 {
+		if alive, heard := d.spinMessageState(); heard {
+			return alive
+		}
+	}
+
+	select {
+
+	case <-d.shards[0].levelWake:
+		d.handleLevels(d.shards[0])
+		return true
+
+	case dsw := <-d.priorityAggregator:
+		d.handleInternal(dsw)
+		return true
+
+	case <-d.shards[0].priorityWake:
+		d.handlePriorityQueue(d.shards[0])
+		return true
+
+	case dsw := <-d.aggregator:
+		d.handleInternal(dsw)
+		d.handleBatch(d.shards[0])
+		return true
+
+	case nextList := <-d.load:
+		d.handleLoad(nextList)
+		return true
+
+	case ocw := <-d.onClose:
+		d.handleClosed(ocw)
+		return true
+
+	case <-d.kill:
+		return false
+	}
+}
+
+// spinMessageState polls every case of allMessageState for up to busyPoll before the caller parks,
+// yielding the processor between polls. It reports whether anything was heard.
+func (d *DynamicSelect) spinMessageState() (alive bool, heard bool) {
+	deadline := time.Now().Add(d.busyPoll)
+	for {
+		select {
+		case <-d.shards[0].levelWake:
+			d.handleLevels(d.shards[0])
+			return true, true
+
+		case dsw := <-d.priorityAggregator:
+			d.handleInternal(dsw)
+			return true, true
+
+		case <-d.shards[0].priorityWake:
+			d.handlePriorityQueue(d.shards[0])
+			return true, true
+
+		case dsw := <-d.aggregator:
+			d.handleInternal(dsw)
+			d.handleBatch(d.shards[0])
+			return true, true
+
+		case nextList := <-d.load:
+			d.handleLoad(nextList)
+			return true, true
+
+		case ocw := <-d.onClose:
+			d.handleClosed(ocw)
+			return true, true
+
+		case <-d.kill:
+			return false, true
+
+		default:
+			if !time.Now().Before(deadline) {
+				return true, false
+			}
+			runtime.Gosched()
+		}
+	}
+}
+
+func (d *DynamicSelect) handleLoad(nextList []ChannelEntry) {
+	d.loadMu.Lock()
+	// Grab the current len, and thus next index.
+	nextIndex := len(d.channels)
+	// Add next, copied as the select annotates its entries.
+	nextList = append([]ChannelEntry(nil), nextList...)
+	for k := range nextList {
+		nextList[k] = d.attach(nextIndex+k, nextList[k])
+	}
+	d.channels = append(d.channels, nextList...)
+	for _, e := range nextList {
+		delete(d.reserved, e.Name)
+	}
+	d.loadMu.Unlock()
+
+	for k, e := range nextList {
+		d.emit(EventEntryLoaded, nextIndex+k, e.Name, nil)
+	}
+
+	// Create New Listeners
+	d.spawnListeners(nextIndex, nextList)
+}
+
+// reserve holds the Names of entries about to be sent to be loaded. The caller must hold loadMu.
+func (d *DynamicSelect) reserve(entries []ChannelEntry) {
+	for _, e := range entries {
+		if e.Name == "" {
+			continue
+		}
+
+		if d.reserved == nil {
+			d.reserved = map[string]struct{}{}
+		}
+		d.reserved[e.Name] = struct{}{}
+	}
+}
+
+func (d *DynamicSelect) updateChannels(index int, entry ChannelEntry) {
+	d.loadMu.Lock()
+	d.channels[index] = entry.current()
+	d.loadMu.Unlock()
+}
+
+// handleClosed records the final state of a listener's entry and calls its OnClose handler.
+func (d *DynamicSelect) handleClosed(ocw *closeWrapper) {
+	index, entry, reason, called := ocw.Index, ocw.Entry, ocw.Reason, ocw.Called
+	putCloseWrapper(ocw)
+
+	d.spawn(func() { d.updateChannels(index, entry) })
+
+	if d.closeHook != nil {
+		d.closeHook(index, entry.Name)
+	}
+
+	d.emit(EventEntryClosed, index, entry.Name, nil)
+
+	if !called {
+		d.handleOnClose(index, reason)
+	}
+}
+
+func (d *DynamicSelect) startListeners() {
+	d.loadMu.Lock()
+	entries := make([]ChannelEntry, len(d.channels))
+	copy(entries, d.channels)
+	for index := range d.channels {
+		d.channels[index].IsClosed = false
+	}
+	d.loadMu.Unlock()
+
+	d.spawnListeners(0, entries)
+}
+
+// spawnListeners starts listening to entries, which occupy the indices from first onward.
+func (d *DynamicSelect) spawnListeners(first int, entries []ChannelEntry) {
+	for _, entry := range entries {
+		if entry.signals != nil {
+			d.relaySignals(entry.signals)
+		}
+	}
+
+	if d.groupSize <= 1 && !d.lazyListeners {
+		for k, entry := range entries {
+			// Start a go routine with the current channel
+			d.listenerWG.Add(1)
+			go d.startListener(first+k, entry)
+		}
+		return
+	}
+
+	d.startListenerGroups(first, entries)
+}
+
+// Channels returns a snapshot of the select's entries, in the order they were loaded.
+// The snapshot is a copy, changing it changes nothing in the select, but entries in it may be
+// passed to another select.
+func (d *DynamicSelect) Channels() []ChannelEntry {
+	d.loadMu.Lock()
+	c := append([]ChannelEntry(nil), d.channels...)
+	d.loadMu.Unlock()
+
+	for i := range c {
+		if c[i].stats != nil {
+			c[i].Handled = c[i].stats.handled.Load()
+		}
+
+		// Ids only mean something to the select that gave them, see Handle.
+		c[i].id = 0
+	}
+
+	return c
+}
+
+// Start listener either passes messages to the aggregator channels or calls handlers locally
+// Depending on the entry supplied.
+func (d *DynamicSelect) startListener(i int, e ChannelEntry) {
+	d.runListener(i, e, nil)
+}
+
+// runListener is startListener, calling first, if given, before listening, as if within the listener.
+func (d *DynamicSelect) runListener(i int, e ChannelEntry, first func()) {
+	d.goroutines.Add(1)
+	defer d.goroutines.Add(-1)
+	d.checkGoroutines()
+
+	d.listening(i, e)
+
+	e.IsClosed = false
+
+	// in is the channel listened to, the relay of sp while the entry spills.
+	in := e.Channel
+	var sp *spool
+
+	// Clean up on close.
+	defer func() {
+		panicked := false
+
+		// We don't control the channels passed in. We may hit a runtime panic if they are closed.
+		if r := recover(); r != nil {
+			d.logf(LogWarn, "Recovered but exiting in DynamicSelect select listener. Likely attempted to read on a closed channel, error: %v\n", r)
+			d.emit(EventHandlerPanicked, i, e.Name, &PanicError{Value: r})
+
+			// This is likely true, but a panic in a handler may trip this.
+			e.IsClosed = true
+			panicked = true
+		}
+
+		if sp != nil {
+			e.Channel = sp.channel()
+		}
+
+		d.finishListener(i, e, panicked)
+	}()
+
+	if first != nil {
+		first()
+	}
+
+	if e.typed != nil {
+		e.IsClosed = e.typed.listen(d, i, e)
+		return
+	}
+
+	if e.batch != nil {
+		e.IsClosed = d.listenBatched(i, e)
+		return
+	}
+
+	if e.Spill != nil {
+		in, sp = d.spill(i, e)
+	}
+
+	// next is the earliest a rate limited entry may be heard from again.
+	var next time.Time
+
+	for {
+		// If using non-blocking handlers, we must check the select
 		// we are a proxy of is still alive after the last process.
 		if !d.IsAlive() {
 			return
 		}
 
+		if e.RateLimit > 0 {
+			if wait := next.Sub(d.clock.Now()); wait > 0 {
+				timer := d.clock.NewTimer(wait)
+				select {
+				case <-d.done:
+					timer.Stop()
+					return
+				case <-e.stop:
+					timer.Stop()
+					return
+				case <-timer.C():
+				}
+			}
+		}
+
 		select {
 		// While waiting, listen for overarching kill command.
 		case <-d.done:
 			return
+		// or for this entry alone being killed.
+		case <-e.stop:
+			return
 		// block to hear the channel.
-		case x, ok := <-e.Channel:
+		case x, ok := <-in:
 
-			// break when the channel is closed
+			// break when the channel is closed, a spilling entry's spool has reopened it already.
 			if !ok {
+				if sp == nil {
+					if c := d.reopen(i, e); c != nil {
+						e.Channel, in = c, c
+						continue
+					}
+				}
+
 				// by returning here, we do not propegate
 				// the 0 value emmited on channel closure.
 				e.IsClosed = true
 				return
 			}
 
-			// check for Blocking. If not handle locally.
-			if !e.Handler.Blocking {
-				go e.Handler.Func(x)
-				continue
+			if d.chaosClose(i, e, x) {
+				e.IsClosed = true
+				return
 			}
 
-			// otherwise, pass through the value to the main listener.
-			message := dsWrapper{
-				Index:  i,
-				Target: x,
+			if e.RateLimit > 0 {
+				next = d.clock.Now().Add(time.Duration(float64(time.Second) / e.RateLimit))
 			}
 
-			// based on priority
-			if e.Handler.Priority {
-				d.priorityAggregator <- message
-				continue
+			// A spilling entry's relay is unbuffered, only the spool reads its channel.
+			if e.Coalesce != nil && sp == nil && len(e.Channel) > 0 {
+				var closed bool
+				x, closed = e.coalesceWaiting(x, len(e.Channel), e.tryRecv)
+				if closed {
+					d.route(i, e, x)
+					if c := d.reopen(i, e); c != nil {
+						e.Channel, in = c, c
+						continue
+					}
+
+					e.IsClosed = true
+					return
+				}
+			}
+
+			d.route(i, e, x)
+		}
+	}
+}
+
+// listening records that a listener is listening to the entry.
+func (d *DynamicSelect) listening(i int, e ChannelEntry) {
+	e.stats.listen()
+
+	if e.Heartbeat > 0 && e.stats != nil {
+		e.stats.hear(d.clock.Now())
+		done := d.done
+		d.spawn(func() { d.watchHeartbeat(i, e, done) })
+	}
+
+	if e.OnStart != nil {
+		e.OnStart()
+	}
+
+	if e.started != nil {
+		e.started()
+	}
+}
+
+// reopen asks the Reopen factory of entry i, whose channel closed, for a fresh channel, and
+// records it. It returns nil if the entry should close instead.
+func (d *DynamicSelect) reopen(i int, e ChannelEntry) chan interface{} {
+	if e.Reopen == nil || !d.IsAlive() {
+		return nil
+	}
+
+	c := e.Reopen()
+	if c == nil {
+		return nil
+	}
+
+	d.loadMu.Lock()
+	d.channels[i].Channel = c
+	d.loadMu.Unlock()
+
+	d.emit(EventEntryReopened, i, e.Name, nil)
+	return c
+}
+
+// route sends a message heard by the listener of entry i towards its handler.
+func (d *DynamicSelect) route(i int, e ChannelEntry, x interface{}) {
+	e = e.current()
+	e.stats.hear(d.clock.Now())
+
+	if e.skip() {
+		d.shed(i, e, x, DropSampled)
+		return
+	}
+
+	if d.chaosDrop(i, e, x) {
+		return
+	}
+
+	if e.Transform != nil {
+		x = e.Transform(x)
+	}
+
+	if e.duplicate(x, d.clock.Now()) {
+		d.shed(i, e, x, DropDuplicate)
+		return
+	}
+
+	if e.journal != nil {
+		e.journal.record(x, d.clock.Now())
+	}
+
+	// check for Blocking. If not hand off to the dispatcher.
+	if !e.Handler.Blocking {
+		if d.acquireSlot(e) {
+			if !d.dispatch.submit(d.job(e, x)) && !d.halting() {
+				d.shed(i, e, x, DropOverflow)
+			}
+		}
+		return
+	}
+
+	// otherwise, pass through the value to the main listener.
+	d.forward(i, e, x)
+}
+
+// finishListener reports that the listener for entry i has exited, having panicked if set.
+func (d *DynamicSelect) finishListener(i int, e ChannelEntry, panicked bool) {
+	reason := e.closeReason(panicked)
+
+	// check for Blocking, a reverse ordered shut down handles both alike.
+	w := getCloseWrapper(i, e, reason)
+	if !e.OnClose.Blocking && !(d.reverseClose && d.halting()) {
+		d.onCloseWG.Add(1)
+		d.spawn(func() {
+			defer d.onCloseWG.Done()
+			e.OnClose.call(reason)
+		})
+		w.Called = true
+	}
+
+	// Otherwise pass to main handler
+	d.onClose <- w
+
+	if e.stats != nil {
+		e.stats.listening.Store(false)
+	}
+	e.trace.end()
+
+	// Free up the waitgroup for shutdown.
+	d.listenerWG.Done()
+}
+
+// closeReason is why the listener of the entry exited.
+func (e ChannelEntry) closeReason(panicked bool) CloseReason {
+	switch {
+	case panicked:
+		return ClosedPanicked
+	case e.IsClosed:
+		return ClosedChannel
+	case e.killed():
+		return ClosedEntryKilled
+	default:
+		return ClosedKilled
+	}
+}
+
+// halting reports whether the current run has been told to stop.
+func (d *DynamicSelect) halting() bool {
+	select {
+	case <-d.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// forward passes a message for a Blocking handler to the aggregator matching its priority.
+func (d *DynamicSelect) forward(i int, e ChannelEntry, x interface{}) {
+	message := getWrapper(i, x)
+	message.Enqueued = d.enqueued()
+
+	s := d.shardFor(e)
+	level := e.Handler.level()
+	if level > 0 && d.busyPoll > 0 {
+		// Skip the hop through the main loop.
+		d.handleInternal(message)
+		return
+	}
+
+	if level > 1 && len(s.levels) > 0 {
+		d.send(s.levels[min(level-2, len(s.levels)-1)], message, e)
+		s.wakeLevels()
+		return
+	}
+
+	if level > 0 && s.priorityQueue != nil {
+		s.priorityQueue.push(message)
+		s.wakePriority()
+		return
+	}
+
+	if level > 0 {
+		d.send(s.priorityAggregator, message, e)
+		return
+	}
+
+	d.send(s.aggregator, message, e)
+}
+
+// send passes a message for entry e to an aggregator, applying the aggregator overflow policy
+// if it is full.
+func (d *DynamicSelect) send(c chan *dsWrapper, message *dsWrapper, e ChannelEntry) {
+	switch d.aggregatorPolicy {
+	case OverflowDrop, OverflowDropOldest, OverflowError:
+	default:
+		c <- message
+		return
+	}
+
+	select {
+	case c <- message:
+		return
+	default:
+	}
+
+	switch d.aggregatorPolicy {
+	case OverflowDropOldest:
+		for {
+			select {
+			case c <- message:
+				return
+			default:
+			}
+
+			select {
+			case oldest := <-c:
+				d.discard(oldest)
+			default:
 			}
+		}
+
+	case OverflowError:
+		d.reportError(&HandlerError{Index: message.Index, Name: e.Name, Message: message.Target, Err: ErrAggregatorFull})
+	}
+
+	d.shed(message.Index, e, message.Target, DropOverflow)
+	putWrapper(message)
+	e.discarded()
+}
+
+// discard drops a message taken back from an aggregator.
+func (d *DynamicSelect) discard(dsw *dsWrapper) {
+	index, x := dsw.Index, dsw.Target
+	putWrapper(dsw)
+
+	d.loadMu.Lock()
+	entry := d.channels[index]
+	d.loadMu.Unlock()
+
+	d.shed(index, entry, x, DropOverflow)
+	entry.discarded()
+}
+
+// discarded releases what a message of the entry holds once it is dropped rather than handled.
+func (e ChannelEntry) discarded() {
+	if e.typed != nil {
+		e.typed.discardNext()
+	}
+
+	e.Credit.release()
+	e.stats.drop()
+}
+
+// handleLevels handles the oldest message of the highest level waiting in the shard's level
+// aggregators, reporting whether there was one. The shard is woken again in case more are waiting.
+func (d *DynamicSelect) handleLevels(s *shard) bool {
+	for l := len(s.levels) - 1; l >= 0; l-- {
+		select {
+		case dsw := <-s.levels[l]:
+			s.wakeLevels()
+			d.handleInternal(dsw)
+			return true
+		default:
+		}
+	}
+
+	return false
+}
+
+// handlePriorityQueue handles the oldest message in the shard's priority queue,
+// waking the shard again in case more are waiting.
+func (d *DynamicSelect) handlePriorityQueue(s *shard) {
+	dsw := s.priorityQueue.pop()
+	if dsw == nil {
+		return
+	}
+
+	s.wakePriority()
+	d.handleInternal(dsw)
+}
+
+// handleBatch handles up to batchSize - 1 further messages already waiting in the shard's aggregator.
+// It stops early if nothing is waiting or a kill command arrives.
+func (d *DynamicSelect) handleBatch(s *shard) {
+	for n := 1; n < d.batchSize; n++ {
+		if !d.IsAlive() || len(d.kill) > 0 {
+			return
+		}
 
-			d.aggregator <- message
+		select {
+		case dsw := <-s.aggregator:
+			d.handleInternal(dsw)
+		default:
+			return
 		}
 	}
 }
 
-func (d *DynamicSelect) handleInternal(dsw dsWrapper) {
+func (d *DynamicSelect) handleInternal(dsw *dsWrapper) {
+	index, target, enqueued := dsw.Index, dsw.Target, dsw.Enqueued
+	putWrapper(dsw)
+
 	// Find the coresponding entry in the array,
-	<-d.loadGuard
-	entry := d.channels[dsw.Index]
-	d.loadGuard <- unit
+	d.loadMu.Lock()
+	entry := d.channels[index]
+	d.loadMu.Unlock()
 
-	entry.Handler.Func(dsw.Target)
+	if d.busyPoll > 0 {
+		s := d.shardFor(entry)
+		s.handlerMu.Lock()
+		defer s.handlerMu.Unlock()
+	}
+
+	defer entry.Credit.release()
+
+	start := entry.stats.begin(enqueued)
+	defer entry.stats.end(start)
+
+	if d.watchdog != nil {
+		defer d.watchSlow(index, entry)()
+	}
+
+	if entry.typed != nil {
+		entry.typed.handleNext()
+		return
+	}
+
+	entry.handler(target)
+}
+
+// job packages a message for a non-Blocking handler.
+func (d *DynamicSelect) job(e ChannelEntry, x interface{}) dispatchJob {
+	return dispatchJob{
+		f:        e.handler,
+		x:        x,
+		credit:   e.Credit,
+		stats:    e.stats,
+		enqueued: d.enqueued(),
+		slots:    e.slots,
+	}
+}
+
+// acquireSlot waits for the entry to have room for another handler call, see
+// HandlerEntry.MaxConcurrency, reporting false if the select halted first.
+func (d *DynamicSelect) acquireSlot(e ChannelEntry) bool {
+	if e.slots == nil {
+		return true
+	}
+
+	select {
+	case <-e.slots.tokens:
+		return true
+	case <-d.done:
+		return false
+	}
 }
 
-func (d *DynamicSelect) handleOnClose(index int) {
+func (d *DynamicSelect) handleOnClose(index int, reason CloseReason) {
 	// Find the coresponding entry in the array,
-	<-d.loadGuard
+	d.loadMu.Lock()
 	entry := d.channels[index]
-	d.loadGuard <- unit
+	d.loadMu.Unlock()
 
-	entry.OnClose.Func()
+	entry.OnClose.call(reason)
 }
 
 // Looks awful, but drains all channels in the DynamicSelect while waiting for the WG
 // to synchronize with the listeners, then close the channels.
+// Each channel is captured up front, as a Reset replaces them once draining is done.
 func (d *DynamicSelect) drainChannels() {
-	go func() {
-		for {
-			_, ok := <-d.aggregator
-			if ok {
-				continue
-			}
-			return
+	onClose, kill, load, drained := d.onClose, d.kill, d.load, d.drained
+	closesDrained, stopped := d.closesDrained, d.stopped
+
+	for _, s := range d.shards {
+		for _, c := range append([]chan *dsWrapper{s.aggregator, s.priorityAggregator}, s.levels...) {
+			d.spawn(func() { drainWrappers(c) })
 		}
-	}()
+	}
 
-	go func() {
+	d.spawn(func() {
+		defer close(closesDrained)
+
+		// closed holds the notifications of a reverse ordered shut down until every listener has exited.
+		closed := []closeWrapper{}
 		for {
-			_, ok := <-d.priorityAggregator
-			if ok {
+			x, ok := <-onClose
+			if ok && d.reverseClose {
+				closed = append(closed, *x)
+				putCloseWrapper(x)
 				continue
 			}
-			return
-		}
-	}()
 
-	go func() {
-		for {
-			x, ok := <-d.onClose
 			if ok {
-				d.handleOnClose(x.Index)
+				index, name, reason, called := x.Index, x.Entry.Name, x.Reason, x.Called
+				putCloseWrapper(x)
+				d.emit(EventEntryClosed, index, name, nil)
+				if !called {
+					d.handleOnClose(index, reason)
+				}
 				continue
 			}
+
+			// Every listener has exited, the last loaded are closed first.
+			sort.Slice(closed, func(i, j int) bool {
+				return closed[i].Index > closed[j].Index
+			})
+
+			for _, c := range closed {
+				d.emit(EventEntryClosed, c.Index, c.Entry.Name, nil)
+				if !c.Called {
+					d.handleOnClose(c.Index, c.Reason)
+				}
+			}
 			return
 		}
-	}()
+	})
 
 	// We know that killHeard is set to true so:
-	go func() {
+	d.spawn(func() {
 
 		for {
-			_, ok := <-d.kill
+			_, ok := <-kill
 			if ok {
 				continue
 			}
 			return
 		}
-	}()
+	})
 
 	// At this point, any call to d.Load will return an error, so we can safely
 	// Discard these as outstanding requests that will never be filled.
-	go func() {
+	d.spawn(func() {
 		for {
-			_, ok := <-d.load
+			_, ok := <-load
 			if ok {
 				continue
 			}
 			return
 		}
-	}()
+	})
+
+	// Let any outstanding calls to Kill or Load land once the listeners, whose handlers may
+	// be making them, have exited.
+	d.spawn(func() {
+		<-stopped
+		d.awaitSenders()
 
-	// Stack any outstanding attempts to call kill or load
-	go func() {
-		time.Sleep(time.Second)
 		// Then close all channels that don't point internally.
-		close(d.kill)
-		close(d.killGuard)
-		close(d.load)
-	}()
+		close(kill)
+		close(load)
+		close(drained)
+	})
+}
+
+// startSend counts a Kill, Load or Broadcast call that may send, until endSend.
+func (d *DynamicSelect) startSend() {
+	d.sendMu.Lock()
+	defer d.sendMu.Unlock()
+
+	if d.sending == 0 {
+		d.idle = make(chan struct{})
+	}
+	d.sending++
+}
+
+func (d *DynamicSelect) endSend() {
+	d.sendMu.Lock()
+	defer d.sendMu.Unlock()
+
+	d.sending--
+	if d.sending == 0 {
+		close(d.idle)
+	}
+}
+
+// awaitSenders waits, for up to the drain grace period, until no Kill, Load or Broadcast call is sending.
+// The select is halted, so any call made later returns without sending.
+func (d *DynamicSelect) awaitSenders() {
+	d.sendMu.Lock()
+	idle := d.idle
+	sending := d.sending > 0
+	d.sendMu.Unlock()
+
+	if !sending {
+		return
+	}
+
+	grace := d.clock.NewTimer(d.drainGrace)
+	defer grace.Stop()
+
+	select {
+	case <-idle:
+	case <-grace.C():
+	}
+}
+
+func drainWrappers(c chan *dsWrapper) {
+	for {
+		x, ok := <-c
+		if ok {
+			putWrapper(x)
+			continue
+		}
+		return
+	}
+}
+
+// shardFor returns the shard responsible for the entry.
+func (d *DynamicSelect) shardFor(e ChannelEntry) *shard {
+	return d.shards[e.shardKey%uint32(len(d.shards))]
+}
+
+// startShards runs a consumer loop for every shard past the first.
+func (d *DynamicSelect) startShards() {
+	for _, s := range d.shards[1:] {
+		d.shardWG.Add(1)
+		go d.runShard(s)
+	}
+}
+
+// runShard mirrors the message tiers of the state machine for a single shard.
+// Kill commands and loads remain the responsibility of the main loop.
+func (d *DynamicSelect) runShard(s *shard) {
+	d.goroutines.Add(1)
+	defer d.goroutines.Add(-1)
+	defer d.shardWG.Done()
+
+	for d.IsAlive() {
+		if d.handleLevels(s) {
+			continue
+		}
+
+		select {
+		case <-d.done:
+			return
+
+		case dsw := <-s.priorityAggregator:
+			d.handleInternal(dsw)
+
+		case <-s.priorityWake:
+			d.handlePriorityQueue(s)
+
+		default:
+			select {
+			case <-d.done:
+				return
+
+			case dsw := <-s.priorityAggregator:
+				d.handleInternal(dsw)
+
+			case <-s.priorityWake:
+				d.handlePriorityQueue(s)
+
+			case <-s.levelWake:
+				d.handleLevels(s)
+
+			case dsw := <-s.aggregator:
+				d.handleInternal(dsw)
+				d.handleBatch(s)
+			}
+		}
+	}
 }
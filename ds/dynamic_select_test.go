@@ -1,7 +1,12 @@
 package ds
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -183,6 +188,131 @@ func TestKill(t *testing.T) {
 	}
 }
 
+func TestConcurrentKill(t *testing.T) {
+	var kills atomic.Int32
+	contested := NewDynamicSelect(func() { kills.Add(1) }, []ChannelEntry{{
+		Channel: make(chan interface{}),
+		Handler: HandlerEntry{Func: func(i interface{}) {}},
+	}})
+	contestedReady := make(chan interface{})
+	go contested.Forever(contestedReady)
+	<-contestedReady
+
+	reasons := []error{}
+	var wg sync.WaitGroup
+	for n := 0; n < 16; n++ {
+		reason := fmt.Errorf("killer %d", n)
+		reasons = append(reasons, reason)
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			contested.KillWithReason(reason)
+		}()
+		go func() {
+			defer wg.Done()
+			contested.IsAlive()
+		}()
+	}
+
+	wg.Wait()
+	contested.Wait()
+
+	if kills.Load() != 1 {
+		t.Errorf("Expected the kill action to run once, ran %d times", kills.Load())
+	}
+
+	found := false
+	for _, reason := range reasons {
+		found = found || errors.Is(contested.KillReason(), reason)
+	}
+
+	if !found || contested.IsAlive() {
+		t.Errorf("Expected the select to be killed for one of the reasons given, found %v", contested.KillReason())
+	}
+}
+
+func TestDrainGrace(t *testing.T) {
+	draining := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: make(chan interface{}),
+		Handler: HandlerEntry{Func: func(i interface{}) {}},
+	}}, WithDrainGrace(time.Minute))
+	drainingReady := make(chan interface{})
+	go draining.Forever(drainingReady)
+	<-drainingReady
+
+	// Loads racing the kill either land or are turned away, never sent on a closed channel.
+	var wg sync.WaitGroup
+	for n := 0; n < 16; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			draining.Load([]ChannelEntry{{
+				Channel: make(chan interface{}),
+				Handler: HandlerEntry{Func: func(i interface{}) {}},
+			}})
+		}()
+	}
+
+	start := time.Now()
+	draining.Kill()
+	wg.Wait()
+
+	drained := make(chan struct{})
+	go func() {
+		draining.Reset()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(time.Second / 2):
+		t.Fatalf("Expected draining to end with the listeners, not the grace period.")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second/2 {
+		t.Errorf("Draining took %s", elapsed)
+	}
+}
+
+func TestDrainGraceManualClock(t *testing.T) {
+	draining := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: make(chan interface{}),
+		Handler: HandlerEntry{Func: func(i interface{}) {}},
+	}}, WithDrainGrace(time.Minute), WithClock(NewManualClock(time.Now())))
+	drainingReady := make(chan interface{})
+	go draining.Forever(drainingReady)
+	<-drainingReady
+
+	var wg sync.WaitGroup
+	for n := 0; n < 16; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			draining.Load([]ChannelEntry{{
+				Channel: make(chan interface{}),
+				Handler: HandlerEntry{Func: func(i interface{}) {}},
+			}})
+		}()
+	}
+
+	draining.Kill()
+	wg.Wait()
+
+	// The clock is never advanced, draining ends as the last call returns.
+	drained := make(chan struct{})
+	go func() {
+		draining.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected draining to end with the calls sending, not the grace period.")
+	}
+}
+
 func TestKillOverPriorityMessage(t *testing.T) {
 	defer reset()
 
@@ -223,7 +353,7 @@ func TestLoad(t *testing.T) {
 	next := []ChannelEntry{unblockingChannel}
 
 	selectMgr := NewDynamicSelect(ka, []ChannelEntry{lesserChannel})
-	err := selectMgr.Load(next)
+	_, err := selectMgr.Load(next)
 	if err == nil {
 		t.Errorf("Load err was nil when it should not have been.")
 	}
@@ -233,7 +363,7 @@ func TestLoad(t *testing.T) {
 
 	lesserChannel.Channel <- unit
 
-	err = selectMgr.Load(next)
+	_, err = selectMgr.Load(next)
 	if err != nil {
 		t.Errorf("Could not load when expected to: %s", err.Error())
 	}
@@ -244,7 +374,7 @@ func TestLoad(t *testing.T) {
 	selectMgr.Kill()
 	time.Sleep(time.Second / 10)
 
-	err = selectMgr.Load(next)
+	_, err = selectMgr.Load(next)
 	if err == nil {
 		t.Errorf("Load err was nil when it should not have been.")
 	}
@@ -469,3 +599,1214 @@ func TestChannels(t *testing.T) {
 		}
 	}
 }
+
+func TestBatchSize(t *testing.T) {
+	defer reset()
+
+	heard := []interface{}{}
+	batchChannel := ChannelEntry{
+		Channel: make(chan interface{}, 10),
+		Handler: HandlerEntry{
+			Func: func(i interface{}) {
+				heard = append(heard, i)
+			},
+			Blocking: true,
+		},
+		OnClose: OnCloseEntry{
+			Func: func() {},
+		},
+	}
+
+	for i := 0; i < 10; i++ {
+		batchChannel.Channel <- i
+	}
+
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{batchChannel}, WithBatchSize(4))
+	go selectMgr.Forever(ready)
+	<-ready
+
+	time.Sleep(time.Second / 10)
+	selectMgr.Kill()
+	time.Sleep(time.Second / 10)
+
+	if len(heard) != 10 {
+		t.Fatalf("Expected 10 messages to be heard, heard %d", len(heard))
+	}
+
+	for i, v := range heard {
+		if v != i {
+			t.Errorf("Batched messages were heard out of order: %v", heard)
+			break
+		}
+	}
+}
+
+func TestShards(t *testing.T) {
+	heard := make([]chan interface{}, 4)
+	entries := []ChannelEntry{}
+	for i := range heard {
+		heard[i] = make(chan interface{}, 10)
+		entries = append(entries, ChannelEntry{
+			Channel: make(chan interface{}, 10),
+			Handler: HandlerEntry{
+				Func: func(x interface{}) {
+					heard[i] <- x
+				},
+				Blocking: true,
+				Priority: i%2 > 0,
+			},
+			OnClose: OnCloseEntry{
+				Func: func() {},
+			},
+		})
+	}
+
+	selectMgr := NewDynamicSelect(func() {}, entries, WithShards(3))
+	shardsReady := make(chan interface{})
+	go selectMgr.Forever(shardsReady)
+	<-shardsReady
+	defer selectMgr.Kill()
+
+	for n := 0; n < 10; n++ {
+		for _, e := range entries {
+			e.Channel <- n
+		}
+	}
+
+	for i, h := range heard {
+		for n := 0; n < 10; n++ {
+			select {
+			case v := <-h:
+				if v != n {
+					t.Errorf("Entry %d heard messages out of order, %v was heard %d", i, v, n)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Expected entry %d to hear 10 messages, heard %d", i, n)
+			}
+		}
+	}
+}
+
+func TestAffinity(t *testing.T) {
+	var running, overlapped atomic.Int32
+	handled := make(chan interface{}, 20)
+	h := HandlerEntry{
+		Func: func(x interface{}) {
+			if running.Add(1) > 1 {
+				overlapped.Add(1)
+			}
+			time.Sleep(time.Second / 200)
+			running.Add(-1)
+			handled <- x
+		},
+		Blocking: true,
+	}
+
+	// Adjacent entries would land on different shards by index.
+	entries := []ChannelEntry{
+		{Channel: make(chan interface{}), Handler: h, Affinity: "ledger"},
+		{Channel: make(chan interface{}), Handler: h, Affinity: "ledger"},
+	}
+
+	pinned := NewDynamicSelect(func() {}, entries, WithShards(4))
+	pinnedReady := make(chan interface{})
+	go pinned.Forever(pinnedReady)
+	<-pinnedReady
+	defer pinned.Kill()
+
+	for n := 0; n < 10; n++ {
+		for _, e := range entries {
+			e.Channel <- n
+		}
+	}
+
+	for n := 0; n < 20; n++ {
+		<-handled
+	}
+
+	if overlapped.Load() != 0 {
+		t.Errorf("Expected entries with the same Affinity never to be handled concurrently, overlapped %d times", overlapped.Load())
+	}
+}
+
+func TestBusyPoll(t *testing.T) {
+	var heard, closed sync.WaitGroup
+	entries := mixedEntries(&heard, &closed)
+
+	selectMgr := NewDynamicSelect(func() {}, entries, WithBusyPoll(time.Millisecond))
+	busyReady := make(chan interface{})
+	go selectMgr.Forever(busyReady)
+	<-busyReady
+
+	for _, e := range entries {
+		e.Channel <- unit
+	}
+
+	if !waitTimeout(&heard, time.Second) {
+		t.Errorf("Blocking, priority and unblocking channels were not all heard in busy poll mode.")
+	}
+
+	selectMgr.Kill()
+	if !waitTimeout(&closed, time.Second) {
+		t.Errorf("Child listener did not clean up!")
+	}
+}
+
+func TestBuffers(t *testing.T) {
+	var heard, closed sync.WaitGroup
+	entries := mixedEntries(&heard, &closed)
+
+	selectMgr := NewDynamicSelect(func() {}, entries, WithBuffers(Buffers{
+		Aggregator:         4,
+		PriorityAggregator: 4,
+		OnClose:            2,
+		Load:               1,
+	}))
+	buffersReady := make(chan interface{})
+	go selectMgr.Forever(buffersReady)
+	<-buffersReady
+
+	for _, e := range entries {
+		e.Channel <- unit
+	}
+
+	if !waitTimeout(&heard, time.Second) {
+		t.Errorf("Channels were not heard through buffered aggregators.")
+	}
+
+	selectMgr.Kill()
+	if !waitTimeout(&closed, time.Second) {
+		t.Errorf("Child listener did not clean up!")
+	}
+}
+
+// mixedEntries returns six entries, Blocking, Priority and non-Blocking with Blocking and
+// non-Blocking OnClose, counting each message heard off heard and each close off closed.
+func mixedEntries(heard, closed *sync.WaitGroup) []ChannelEntry {
+	entries := []ChannelEntry{}
+	for i := 0; i < 6; i++ {
+		entries = append(entries, ChannelEntry{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{
+				Func:     func(x interface{}) { heard.Done() },
+				Blocking: i%3 != 2,
+				Priority: i%3 == 1,
+			},
+			OnClose: OnCloseEntry{
+				Func:     closed.Done,
+				Blocking: i%2 == 0,
+			},
+		})
+	}
+	heard.Add(len(entries))
+	closed.Add(len(entries))
+
+	return entries
+}
+
+// waitTimeout waits on wg, reporting false if it takes longer than timeout.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	waited := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestLockFreePriority(t *testing.T) {
+	var heard, closed sync.WaitGroup
+	entries := mixedEntries(&heard, &closed)
+
+	selectMgr := NewDynamicSelect(func() {}, entries, WithLockFreePriority(), WithShards(2))
+	lockFreeReady := make(chan interface{})
+	go selectMgr.Forever(lockFreeReady)
+	<-lockFreeReady
+
+	for _, e := range entries {
+		e.Channel <- unit
+	}
+
+	if !waitTimeout(&heard, time.Second) {
+		t.Errorf("Priority and blocking channels were not all heard alongside the lock-free queue.")
+	}
+
+	selectMgr.Kill()
+	if !waitTimeout(&closed, time.Second) {
+		t.Errorf("Child listener did not clean up!")
+	}
+}
+
+func TestListenerGroups(t *testing.T) {
+	defer reset()
+
+	selectMgr := NewDynamicSelect(func() {}, fullSet[:4], WithListenerGroups(3))
+	go selectMgr.Forever(ready)
+	<-ready
+
+	_, err := selectMgr.Load(fullSet[4:])
+	if err != nil {
+		t.Errorf("Could not load when expected to: %s", err.Error())
+	}
+
+	for _, v := range fullSet {
+		v.Channel <- unit
+	}
+
+	time.Sleep(time.Second / 10)
+
+	close(lesserChannel.Channel)
+	close(pcCloseChannel.Channel)
+	time.Sleep(time.Second / 10)
+
+	chs := selectMgr.Channels()
+	if !chs[0].IsClosed || !chs[5].IsClosed {
+		t.Errorf("Closed channels in a listener group were not reported closed.")
+	}
+
+	if chs[1].IsClosed || chs[4].IsClosed {
+		t.Errorf("Open channels in a listener group were reported closed.")
+	}
+
+	selectMgr.Kill()
+	time.Sleep(time.Second / 10)
+
+	if !lesserHeard || !greaterHeard || !unblockingHeard || !cHandHeard || !cCloseHeard || !pcCloseHeard {
+		t.Errorf("Channels were not heard through listener groups.")
+	}
+
+	if !lesserClosed || !greaterClosed || !unblockingClosed || !cHandClosed || !cCloseClosed || !pcCloseClosed {
+		t.Errorf("Child listener did not clean up!")
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	defer reset()
+
+	heard := []interface{}{}
+	release := make(chan struct{})
+	coalesceChannel := ChannelEntry{
+		Channel: make(chan interface{}, 10),
+		Handler: HandlerEntry{
+			Func: func(i interface{}) {
+				<-release
+				heard = append(heard, i)
+			},
+			Blocking: true,
+		},
+		OnClose: OnCloseEntry{
+			Func: func() {},
+		},
+		Coalesce: func(pending []interface{}) interface{} {
+			sum := 0
+			for _, p := range pending {
+				sum += p.(int)
+			}
+			return sum
+		},
+	}
+
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{coalesceChannel})
+	go selectMgr.Forever(ready)
+	<-ready
+
+	// The first is heard alone and holds up the handler while the rest queue behind it.
+	coalesceChannel.Channel <- 1
+	time.Sleep(time.Second / 100)
+	coalesceChannel.Channel <- 2
+	time.Sleep(time.Second / 100)
+	for i := 0; i < 5; i++ {
+		coalesceChannel.Channel <- 10
+	}
+
+	close(release)
+	time.Sleep(time.Second / 10)
+	selectMgr.Kill()
+
+	if len(heard) != 3 || heard[0] != 1 || heard[1] != 2 || heard[2] != 50 {
+		t.Errorf("Expected the burst to be coalesced into [1 2 50], heard %v", heard)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	heard := make(chan interface{}, 2)
+	c := make(chan interface{})
+
+	transforming := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{Func: func(i interface{}) { heard <- i }},
+		Transform: func(x interface{}) interface{} {
+			return len(x.(string))
+		},
+	}})
+	transformingReady := make(chan interface{})
+	go transforming.Forever(transformingReady)
+	<-transformingReady
+	defer transforming.Kill()
+
+	c <- "four"
+	if got := <-heard; got != 4 {
+		t.Errorf("Expected the handler to receive the transformed message 4, received %v", got)
+	}
+
+	// Broadcast messages are routed as if heard.
+	if _, err := transforming.Broadcast("seven!!"); err != nil {
+		t.Fatalf("Failed to broadcast: %v", err)
+	}
+
+	if got := <-heard; got != 7 {
+		t.Errorf("Expected the handler to receive the transformed broadcast 7, received %v", got)
+	}
+}
+
+func TestReset(t *testing.T) {
+	killed := make(chan struct{}, 2)
+	heard := make(chan interface{}, 2)
+	entries := []ChannelEntry{
+		{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(x interface{}) { heard <- x }, Blocking: true},
+		},
+		{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(x interface{}) { heard <- x }},
+		},
+	}
+
+	selectMgr := NewDynamicSelect(func() { killed <- struct{}{} }, entries)
+
+	if err := selectMgr.Reset(); err != nil {
+		t.Errorf("Could not reset a select that never ran: %s", err.Error())
+	}
+
+	resetReady := make(chan interface{})
+	go selectMgr.Forever(resetReady)
+	<-resetReady
+
+	if err := selectMgr.Reset(); !errors.Is(err, ErrRunning) {
+		t.Errorf("Expected ErrRunning resetting a running select, got: %v", err)
+	}
+
+	// Inspecting the select must be safe while it is reset.
+	inspected := make(chan struct{})
+	go func() {
+		defer close(inspected)
+		for i := 0; i < 100; i++ {
+			selectMgr.DumpState()
+			selectMgr.DispatchStats()
+		}
+	}()
+
+	selectMgr.Kill()
+	if err := selectMgr.Reset(); err != nil {
+		t.Fatalf("Could not reset a killed select: %s", err.Error())
+	}
+	<-inspected
+
+	if !selectMgr.IsAlive() {
+		t.Fatalf("Reset select does not report itself alive.")
+	}
+
+	nextReady := make(chan interface{})
+	go selectMgr.Forever(nextReady)
+	<-nextReady
+
+	entries[0].Channel <- 1
+	entries[1].Channel <- 2
+	for i := 0; i < 2; i++ {
+		select {
+		case <-heard:
+		case <-time.After(time.Second):
+			t.Fatalf("Reset select did not hear its entries.")
+		}
+	}
+
+	selectMgr.Kill()
+	selectMgr.Wait()
+
+	if len(killed) != 2 {
+		t.Errorf("Expected the kill action to run once per run, ran %d times", len(killed))
+	}
+}
+
+func TestNilFuncs(t *testing.T) {
+	defer reset()
+
+	partial := []ChannelEntry{
+		{
+			Channel: make(chan interface{}, 1),
+			Handler: HandlerEntry{Blocking: true},
+		},
+		{
+			Channel: make(chan interface{}, 1),
+		},
+		{
+			Channel: make(chan interface{}, 1),
+			Handler: HandlerEntry{Blocking: true, Priority: true},
+			OnClose: OnCloseEntry{Blocking: true},
+		},
+	}
+
+	selectMgr := NewDynamicSelect(func() {}, partial)
+	go selectMgr.Forever(ready)
+	<-ready
+
+	_, err := selectMgr.Load([]ChannelEntry{{Channel: make(chan interface{})}})
+	if err != nil {
+		t.Errorf("Could not load a partial entry: %s", err.Error())
+	}
+
+	for _, v := range partial {
+		v.Channel <- unit
+		close(v.Channel)
+	}
+
+	time.Sleep(time.Second / 10)
+
+	if !selectMgr.IsAlive() {
+		t.Errorf("Entries without handlers brought down the select.")
+	}
+
+	for i, v := range selectMgr.Channels()[:len(partial)] {
+		if !v.IsClosed {
+			t.Errorf("Entry %d without handlers was not reported closed.", i)
+		}
+	}
+
+	selectMgr.Kill()
+}
+
+func BenchmarkBlockingThroughput(b *testing.B) {
+	handled := make(chan interface{})
+	entry := ChannelEntry{
+		Channel: make(chan interface{}),
+		Handler: HandlerEntry{
+			Func: func(i interface{}) {
+				handled <- i
+			},
+			Blocking: true,
+		},
+		OnClose: OnCloseEntry{
+			Func: func() {},
+		},
+	}
+
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{entry})
+	benchReady := make(chan interface{})
+	go selectMgr.Forever(benchReady)
+	<-benchReady
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		entry.Channel <- unit
+		<-handled
+	}
+	b.StopTimer()
+
+	selectMgr.Kill()
+}
+
+func TestSteadyStateAllocs(t *testing.T) {
+	handled := make(chan interface{})
+	h := func(i interface{}) { handled <- i }
+	entries := []ChannelEntry{
+		{Channel: make(chan interface{}), Handler: HandlerEntry{Func: h, Blocking: true}},
+		{Channel: make(chan interface{}), Handler: HandlerEntry{Func: h}},
+	}
+
+	pooled := NewDynamicSelect(func() {}, entries, WithDispatcher(1, 1, OverflowBlock))
+	pooledReady := make(chan interface{})
+	go pooled.Forever(pooledReady)
+	<-pooledReady
+	defer pooled.Kill()
+
+	// Wrappers are pooled, so a message costs no allocation once the pools are warm.
+	for _, e := range entries {
+		if allocs := testing.AllocsPerRun(1000, func() {
+			e.Channel <- unit
+			<-handled
+		}); allocs >= 1 {
+			t.Errorf("Expected a message for a Blocking: %v entry not to allocate, found %v allocations", e.Handler.Blocking, allocs)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	heard := make(chan time.Time, 3)
+	c := make(chan interface{}, 3)
+	entries := []ChannelEntry{{
+		Channel:   c,
+		Handler:   HandlerEntry{Func: func(i interface{}) { heard <- time.Now() }, Blocking: true},
+		RateLimit: 20,
+	}}
+
+	limited := NewDynamicSelect(func() {}, entries)
+	limitedReady := make(chan interface{})
+	go limited.Forever(limitedReady)
+	<-limitedReady
+	defer limited.Kill()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		c <- i
+	}
+
+	var last time.Time
+	for i := 0; i < 3; i++ {
+		last = <-heard
+	}
+
+	// Three messages at 20 per second take at least two 50ms gaps.
+	if last.Sub(start) < 90*time.Millisecond {
+		t.Errorf("Rate limited entry was heard too quickly: %s", last.Sub(start))
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	c := make(chan interface{})
+	handlerCtx := make(chan context.Context, 1)
+	killed := make(chan interface{})
+
+	entries := []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{
+			FuncContext: func(ctx context.Context, i interface{}) { handlerCtx <- ctx },
+			Blocking:    true,
+		},
+	}}
+
+	ctxSelect := NewDynamicSelect(func() { close(killed) }, entries)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctxReady := make(chan interface{})
+	go ctxSelect.RunContext(ctx, ctxReady)
+	<-ctxReady
+
+	c <- unit
+	heardCtx := <-handlerCtx
+	if heardCtx.Err() != nil {
+		t.Errorf("Handler context was cancelled while running.")
+	}
+
+	cancel()
+	select {
+	case <-killed:
+	case <-time.After(time.Second):
+		t.Fatalf("Cancelling the context did not kill the select.")
+	}
+
+	ctxSelect.Wait()
+	if ctxSelect.IsAlive() || heardCtx.Err() == nil {
+		t.Errorf("Expected the select to halt and its handler context to be cancelled.")
+	}
+
+	if !errors.Is(ctxSelect.KillReason(), context.Canceled) {
+		t.Errorf("Expected the kill reason to be the context's cause, found %v", ctxSelect.KillReason())
+	}
+}
+
+func TestConcurrentNamedLoads(t *testing.T) {
+	named := NewDynamicSelect(func() {}, []ChannelEntry{})
+	namedReady := make(chan interface{})
+	go named.Forever(namedReady)
+	<-namedReady
+	defer named.Kill()
+
+	// Of the Loads racing for one Name, only one claims it.
+	loaded := make(chan error, 8)
+	for n := 0; n < cap(loaded); n++ {
+		go func() {
+			_, err := named.LoadNamed("contested", ChannelEntry{
+				Channel: make(chan interface{}),
+				Handler: HandlerEntry{Func: func(i interface{}) {}},
+			})
+			loaded <- err
+		}()
+	}
+
+	succeeded := 0
+	for n := 0; n < cap(loaded); n++ {
+		var v *ValidationError
+		switch err := <-loaded; {
+		case err == nil:
+			succeeded++
+		case !errors.As(err, &v):
+			t.Errorf("Expected a ValidationError from a losing Load, got: %v", err)
+		}
+	}
+
+	if succeeded != 1 {
+		t.Errorf("Expected one Load to claim the Name, %d did", succeeded)
+	}
+
+	for len(named.Channels()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := len(named.Channels()); n != 1 {
+		t.Errorf("Expected one entry loaded under the Name, found %d", n)
+	}
+}
+
+func TestNamedEntries(t *testing.T) {
+	orders := make(chan interface{})
+	alerts := make(chan interface{})
+	closedNames := make(chan string, 2)
+	alertsClosed := make(chan interface{})
+
+	named := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Name:    "orders",
+		Channel: orders,
+		Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+	}}, WithCloseHook(func(index int, name string) { closedNames <- name }))
+
+	namedReady := make(chan interface{})
+	go named.Forever(namedReady)
+	<-namedReady
+	defer named.Kill()
+
+	_, err := named.LoadNamed("alerts", ChannelEntry{
+		Channel: alerts,
+		Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+		OnClose: OnCloseEntry{Func: func() { close(alertsClosed) }, Blocking: true},
+	})
+	if err != nil {
+		t.Fatalf("Could not load named entry: %s", err.Error())
+	}
+
+	_, err = named.LoadNamed("orders", ChannelEntry{Channel: alerts, Handler: HandlerEntry{Func: func(i interface{}) {}}})
+	var v *ValidationError
+	if !errors.As(err, &v) || len(v.Entries) != 1 || v.Entries[0].Field != "Name" {
+		t.Errorf("Expected a ValidationError on the Name loading an entry under a name already in use, got: %v", err)
+	}
+
+	time.Sleep(time.Second / 20)
+
+	if _, i, ok := named.Entry("alerts"); !ok || i != 1 {
+		t.Errorf("Expected alerts at index 1, found %d", i)
+	}
+
+	if err := named.KillNamed("alerts"); err != nil {
+		t.Fatalf("Could not kill named entry: %s", err.Error())
+	}
+
+	select {
+	case <-alertsClosed:
+	case <-time.After(time.Second):
+		t.Fatalf("Killing a named entry did not run its OnClose.")
+	}
+
+	if name := <-closedNames; name != "alerts" {
+		t.Errorf("Expected alerts to be reported closed, found %q", name)
+	}
+
+	if err := named.KillNamed("alerts"); err == nil {
+		t.Errorf("Killed a named entry twice.")
+	}
+
+	if err := named.KillNamed("missing"); err == nil {
+		t.Errorf("Killed an entry that does not exist.")
+	}
+
+	// orders is still heard.
+	select {
+	case orders <- unit:
+	case <-time.After(time.Second):
+		t.Errorf("Killing one named entry stopped another.")
+	}
+}
+
+func TestPriorityLevels(t *testing.T) {
+	gate := make(chan interface{})
+	order := make(chan int, 4)
+
+	entry := func(level int) ChannelEntry {
+		return ChannelEntry{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) { order <- level }, Blocking: true, Level: level},
+		}
+	}
+
+	entries := []ChannelEntry{
+		{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) { <-gate }, Blocking: true},
+		},
+		entry(0),
+		entry(1),
+		entry(3),
+	}
+
+	levelled := NewDynamicSelect(func() {}, entries, WithPriorityLevels(4))
+	levelledReady := make(chan interface{})
+	go levelled.Forever(levelledReady)
+	<-levelledReady
+	defer levelled.Kill()
+
+	// Hold the main loop, so the rest queue up behind it.
+	entries[0].Channel <- unit
+	for _, e := range entries[1:] {
+		e.Channel <- unit
+	}
+
+	time.Sleep(time.Second / 20)
+	close(gate)
+
+	for _, expected := range []int{3, 1, 0} {
+		select {
+		case level := <-order:
+			if level != expected {
+				t.Errorf("Expected level %d to be handled, found %d", expected, level)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Level %d was never handled.", expected)
+		}
+	}
+}
+
+func TestDoneAfterOnClose(t *testing.T) {
+	closes := make(chan string, 4)
+	entries := []ChannelEntry{
+		{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+			OnClose: OnCloseEntry{Func: func() {
+				time.Sleep(time.Second / 20)
+				closes <- "blocking"
+			}, Blocking: true},
+		},
+		{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}},
+			OnClose: OnCloseEntry{Func: func() {
+				time.Sleep(time.Second / 20)
+				closes <- "non-blocking"
+			}},
+		},
+	}
+
+	waited := NewDynamicSelect(func() {}, entries)
+	waitedReady := make(chan interface{})
+	go waited.Forever(waitedReady)
+	<-waitedReady
+
+	waited.Kill()
+	select {
+	case <-waited.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Done was never closed.")
+	}
+
+	// Both OnClose handlers have returned by the time Done is closed.
+	if len(closes) != 2 {
+		t.Errorf("Expected both OnClose handlers to have returned once Done was closed, %d calls returned", len(closes))
+	}
+
+	waited.Wait()
+	time.Sleep(time.Second / 10)
+
+	// And neither is called again after.
+	calls := map[string]int{}
+	for len(closes) > 0 {
+		calls[<-closes]++
+	}
+
+	if calls["blocking"] != 1 || calls["non-blocking"] != 1 {
+		t.Errorf("Expected each OnClose handler called exactly once, found %v", calls)
+	}
+}
+
+func TestAggregatorOverflow(t *testing.T) {
+	run := func(policy OverflowPolicy) ([]interface{}, []*HandlerError, EntryStats) {
+		gate := make(chan interface{})
+		held := make(chan interface{})
+		c := make(chan interface{})
+		handled := make(chan interface{}, 5)
+		sunk := make(chan *HandlerError, 5)
+
+		entries := []ChannelEntry{
+			{Channel: held, Handler: HandlerEntry{Func: func(i interface{}) { <-gate }, Blocking: true}},
+			{Channel: c, Handler: HandlerEntry{Func: func(i interface{}) { handled <- i }, Blocking: true}},
+		}
+
+		d := NewDynamicSelect(func() {}, entries,
+			WithBuffers(Buffers{Aggregator: 2}),
+			WithAggregatorOverflow(policy),
+			WithErrorSink(func(err *HandlerError) { sunk <- err }))
+		r := make(chan interface{})
+		go d.Forever(r)
+		<-r
+
+		// Hold the main loop while the aggregator overflows.
+		held <- unit
+		time.Sleep(time.Second / 20)
+		for i := 0; i < 5; i++ {
+			c <- i
+		}
+		time.Sleep(time.Second / 20)
+
+		close(gate)
+		time.Sleep(time.Second / 20)
+		stats := d.Stats()[1]
+		d.Kill()
+		d.Wait()
+
+		close(handled)
+		close(sunk)
+		heard, errs := []interface{}{}, []*HandlerError{}
+		for i := range handled {
+			heard = append(heard, i)
+		}
+		for err := range sunk {
+			errs = append(errs, err)
+		}
+
+		return heard, errs, stats
+	}
+
+	heard, _, stats := run(OverflowDrop)
+	if fmt.Sprint(heard) != "[0 1]" || stats.Dropped != 3 {
+		t.Errorf("Expected the newest messages to be dropped, heard %v, dropped %d", heard, stats.Dropped)
+	}
+
+	heard, _, stats = run(OverflowDropOldest)
+	if fmt.Sprint(heard) != "[3 4]" || stats.Dropped != 3 {
+		t.Errorf("Expected the oldest messages to be dropped, heard %v, dropped %d", heard, stats.Dropped)
+	}
+
+	heard, errs, _ := run(OverflowError)
+	if fmt.Sprint(heard) != "[0 1]" || len(errs) != 3 || !errors.Is(errs[0], ErrAggregatorFull) {
+		t.Errorf("Expected overflow to be reported, heard %v, reported %d", heard, len(errs))
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	running := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Channel: make(chan interface{}), Handler: HandlerEntry{Func: func(i interface{}) {}}},
+	})
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- running.Run(ctx)
+	}()
+
+	<-running.Ready()
+	if !running.IsAlive() {
+		t.Errorf("Expected the select to be alive once Ready is closed")
+	}
+
+	if err := running.Run(ctx); !errors.Is(err, ErrAlreadyRun) {
+		t.Errorf("Expected ErrAlreadyRun running a select twice, got: %v", err)
+	}
+
+	cancel()
+	if err := <-stopped; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the select to stop with context.Canceled, got %v", err)
+	}
+
+	if err := running.Reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+
+	go func() {
+		stopped <- running.Run(context.Background())
+	}()
+
+	<-running.Ready()
+	running.Kill()
+	if err := <-stopped; !errors.Is(err, ErrKilled) {
+		t.Errorf("Expected the select to stop with ErrKilled, got %v", err)
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	running := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Channel: make(chan interface{}), Handler: HandlerEntry{Func: func(i interface{}) {}}},
+	})
+
+	stopped := make(chan error, 4)
+	for k := 0; k < cap(stopped); k++ {
+		go func() {
+			stopped <- running.Run(context.Background())
+		}()
+	}
+
+	// All but one Run are refused.
+	for k := 0; k < cap(stopped)-1; k++ {
+		if err := <-stopped; errors.Is(err, ErrKilled) {
+			t.Fatalf("Expected a concurrent Run refused, got %v", err)
+		}
+	}
+
+	<-running.Ready()
+	running.Kill()
+	if err := <-stopped; !errors.Is(err, ErrKilled) {
+		t.Errorf("Expected the select to stop with ErrKilled, got %v", err)
+	}
+}
+
+func TestChannelsSnapshot(t *testing.T) {
+	c := make(chan interface{})
+	before := time.Now()
+	snapshotting := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Channel: c, Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true}},
+	})
+	snapshottingReady := make(chan interface{})
+	go snapshotting.Forever(snapshottingReady)
+	<-snapshottingReady
+	defer snapshotting.Kill()
+
+	for k := 0; k < 3; k++ {
+		c <- k
+	}
+	time.Sleep(time.Second / 20)
+
+	snapshot := snapshotting.Channels()
+	if snapshot[0].Handled != 3 {
+		t.Errorf("Expected the snapshot to count 3 handled messages, found %d", snapshot[0].Handled)
+	}
+
+	if snapshot[0].LoadedAt.Before(before) {
+		t.Errorf("Expected the snapshot to record when the entry was loaded")
+	}
+
+	// Changing the snapshot leaves the select be.
+	snapshot[0].Channel = nil
+	snapshot[0].IsClosed = true
+	if e := snapshotting.Channels()[0]; e.Channel != c || e.IsClosed {
+		t.Errorf("Expected changes to a snapshot not to reach the select")
+	}
+}
+
+func TestOnStart(t *testing.T) {
+	started := make(chan int, 8)
+	entry := func(k int) ChannelEntry {
+		return ChannelEntry{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}},
+			OnStart: func() { started <- k },
+		}
+	}
+
+	starting := NewDynamicSelect(func() {}, []ChannelEntry{entry(0), entry(1)}, WithLazyListeners())
+	startingReady := make(chan interface{})
+	go starting.Forever(startingReady)
+	<-startingReady
+	defer starting.Kill()
+
+	if _, err := starting.LoadWait([]ChannelEntry{entry(2)}); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	// LoadWait returns once OnStart has.
+	heard := map[int]bool{}
+	for len(started) > 0 {
+		heard[<-started] = true
+	}
+
+	if !heard[2] {
+		t.Errorf("Expected OnStart to be called for the loaded entry before LoadWait returned")
+	}
+
+	for len(heard) < 3 {
+		select {
+		case k := <-started:
+			heard[k] = true
+		case <-time.After(time.Second):
+			t.Fatalf("Only heard OnStart for %v", heard)
+		}
+	}
+
+	// Promoting a lazily listened to entry doesn't start it again.
+	starting.Channels()[0].Channel <- 1
+	time.Sleep(time.Second / 20)
+	if len(started) > 0 {
+		t.Errorf("Expected OnStart once per entry, heard it again for %d", <-started)
+	}
+}
+
+func TestReverseClose(t *testing.T) {
+	var mu sync.Mutex
+	order := []int{}
+
+	entries := []ChannelEntry{}
+	for k := 0; k < 6; k++ {
+		entries = append(entries, ChannelEntry{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}},
+			OnClose: OnCloseEntry{Func: func() {
+				mu.Lock()
+				order = append(order, k)
+				mu.Unlock()
+			}, Blocking: k%2 == 0},
+		})
+	}
+
+	reversing := NewDynamicSelect(func() {}, entries[:4], WithReverseClose())
+	reversingReady := make(chan interface{})
+	go reversing.Forever(reversingReady)
+	<-reversingReady
+
+	if _, err := reversing.LoadWait(entries[4:]); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	reversing.Kill()
+	reversing.Wait()
+
+	if fmt.Sprint(order) != "[5 4 3 2 1 0]" {
+		t.Errorf("Expected the OnClose handlers in reverse load order, got %v", order)
+	}
+}
+
+func TestCloseReason(t *testing.T) {
+	var mu sync.Mutex
+	reasons := map[int]CloseReason{}
+
+	entries := []ChannelEntry{}
+	for k := 0; k < 3; k++ {
+		entries = append(entries, ChannelEntry{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}},
+			OnClose: OnCloseEntry{FuncReason: func(reason CloseReason) {
+				mu.Lock()
+				reasons[k] = reason
+				mu.Unlock()
+			}, Blocking: true},
+		})
+	}
+	entries[1].Name = "killed"
+
+	closing := NewDynamicSelect(func() {}, entries)
+	closingReady := make(chan interface{})
+	go closing.Forever(closingReady)
+	<-closingReady
+
+	close(entries[0].Channel)
+	if err := closing.KillNamed("killed"); err != nil {
+		t.Fatalf("Failed to kill the entry: %v", err)
+	}
+	time.Sleep(time.Second / 20)
+
+	closing.Kill()
+	closing.Wait()
+
+	expected := map[int]CloseReason{0: ClosedChannel, 1: ClosedEntryKilled, 2: ClosedKilled}
+	if fmt.Sprint(reasons) != fmt.Sprint(expected) {
+		t.Errorf("Expected the close reasons %v, got %v", expected, reasons)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	heard := make(chan interface{}, 4)
+	opened := make(chan chan interface{}, 4)
+	first := make(chan interface{})
+
+	reopens := 0
+	reopening := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: first,
+		Handler: HandlerEntry{Func: func(i interface{}) { heard <- i }, Blocking: true},
+		Reopen: func() chan interface{} {
+			reopens++
+			if reopens > 1 {
+				return nil
+			}
+
+			c := make(chan interface{})
+			opened <- c
+			return c
+		},
+	}}, WithListenerGroups(4))
+	reopeningReady := make(chan interface{})
+	go reopening.Forever(reopeningReady)
+	<-reopeningReady
+	defer reopening.Kill()
+
+	first <- 1
+	close(first)
+
+	second := <-opened
+	second <- 2
+	if reopening.Channels()[0].Channel != second {
+		t.Errorf("Expected Channels to report the reopened channel")
+	}
+
+	for _, want := range []int{1, 2} {
+		if got := <-heard; got != want {
+			t.Errorf("Expected to hear %d, heard %v", want, got)
+		}
+	}
+
+	// The factory gives up, the entry closes.
+	close(second)
+	time.Sleep(time.Second / 20)
+	if !reopening.Channels()[0].IsClosed {
+		t.Errorf("Expected the entry to close once Reopen returned nil")
+	}
+}
+
+func TestOutput(t *testing.T) {
+	in := make(chan interface{})
+	between := make(chan interface{})
+	heard := make(chan interface{}, 4)
+
+	// The first stage doubles even numbers and drops odd ones, the second is its sink.
+	first := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: in,
+		Handler: HandlerEntry{
+			FuncOut: func(i interface{}) interface{} {
+				if i.(int)%2 != 0 {
+					return nil
+				}
+
+				return i.(int) * 2
+			},
+			Output: between,
+		},
+	}})
+	second := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: between,
+		Handler: HandlerEntry{Func: func(i interface{}) { heard <- i }, Blocking: true},
+	}})
+
+	for _, stage := range []*DynamicSelect{first, second} {
+		stageReady := make(chan interface{})
+		go stage.Forever(stageReady)
+		<-stageReady
+		defer stage.Kill()
+	}
+
+	for i := 1; i <= 4; i++ {
+		in <- i
+	}
+
+	results := []int{}
+	for len(results) < 2 {
+		select {
+		case got := <-heard:
+			results = append(results, got.(int))
+		case <-time.After(time.Second):
+			t.Fatalf("Expected two results to pass through the pipeline, heard %v", results)
+		}
+	}
+
+	sort.Ints(results)
+	if results[0] != 4 || results[1] != 8 {
+		t.Errorf("Expected the results [4 8], heard %v", results)
+	}
+
+	time.Sleep(time.Second / 20)
+	select {
+	case got := <-heard:
+		t.Errorf("Expected nil results to be dropped, heard %v", got)
+	default:
+	}
+}
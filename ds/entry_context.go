@@ -0,0 +1,43 @@
+package ds
+
+import (
+	"context"
+	"sync"
+)
+
+// entryContext is the Context passed to an entry's FuncContext handler: the select's Context,
+// also cancelled once the entry is killed alone. It is derived afresh for each run.
+type entryContext struct {
+	d    *DynamicSelect
+	stop chan struct{}
+
+	mu     sync.Mutex
+	parent context.Context
+	ctx    context.Context
+}
+
+func (c *entryContext) get() context.Context {
+	parent := c.d.Context()
+	if c.stop == nil {
+		return parent
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.parent != parent {
+		ctx, cancel := context.WithCancel(parent)
+		stop := c.stop
+		c.d.spawn(func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		})
+
+		c.parent, c.ctx = parent, ctx
+	}
+
+	return c.ctx
+}
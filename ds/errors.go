@@ -0,0 +1,143 @@
+package ds
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HandlerError is an error returned by a FuncErr handler, with the message it failed on,
+// or ErrAggregatorFull for a message discarded by the OverflowError policy, or ErrSpillFailed for
+// messages a spilling entry lost.
+type HandlerError struct {
+	// Index of the entry, as in Channels, and its Name if it has one.
+	Index int
+	Name  string
+
+	Message interface{}
+	Err     error
+}
+
+func (e *HandlerError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("handler of entry %q: %v", e.Name, e.Err)
+	}
+
+	return fmt.Sprintf("handler of entry %d: %v", e.Index, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// reportError passes a handler's error to the sink, dropping it if there is none.
+func (d *DynamicSelect) reportError(err *HandlerError) {
+	if d.errorSink != nil {
+		d.errorSink(err)
+	}
+}
+
+// StuckEntry describes an entry that held up a shut down.
+type StuckEntry struct {
+	Index int
+	Name  string
+
+	// Listening is set if its listener had not exited, Handling if a handler call had not returned.
+	Listening bool
+	Handling  bool
+}
+
+// ShutdownTimeoutError is reported by Err when a shut down was forced, see WithShutdownTimeout.
+type ShutdownTimeoutError struct {
+	Timeout time.Duration
+	Entries []StuckEntry
+}
+
+func (e *ShutdownTimeoutError) Error() string {
+	stuck := make([]string, len(e.Entries))
+	for i, s := range e.Entries {
+		entry := strconv.Itoa(s.Index)
+		if s.Name != "" {
+			entry = strconv.Quote(s.Name)
+		}
+
+		states := []string{}
+		if s.Listening {
+			states = append(states, "listening")
+		}
+		if s.Handling {
+			states = append(states, "handling")
+		}
+
+		stuck[i] = fmt.Sprintf("%s (%s)", entry, strings.Join(states, ", "))
+	}
+
+	return fmt.Sprintf("DynamicSelect did not shut down within %s, stuck entries: %s", e.Timeout, strings.Join(stuck, "; "))
+}
+
+// ErrHalted is returned, possibly wrapped, by calls that need a live select once it has halted.
+var ErrHalted = errors.New("DynamicSelect has either halted or is uninitialized")
+
+// ErrNotRunning is returned, possibly wrapped, by calls that need a running select before it runs.
+var ErrNotRunning = errors.New("DynamicSelect has not been started")
+
+// ErrAlreadyRun is returned by Run when the select has been run before without a Reset.
+var ErrAlreadyRun = errors.New("DynamicSelect has already been run, Reset it first")
+
+// ErrRunning is returned, wrapped, by calls that need a halted select while it still runs.
+var ErrRunning = errors.New("DynamicSelect is still running")
+
+// ErrNoJournal is returned, wrapped, by Replay for an entry that keeps no journal.
+var ErrNoJournal = errors.New("entry keeps no journal, see ChannelEntry.Journal")
+
+// ErrTypedHandler is returned, wrapped, when replacing the handler of an entry built by Typed.
+var ErrTypedHandler = errors.New("the handler of an entry built by Typed can't be replaced")
+
+// ErrBadInterval is returned, wrapped, for a ticker interval or supervisor cooldown tick that
+// isn't positive.
+var ErrBadInterval = errors.New("interval must be positive")
+
+// ErrEntryClosed is returned, wrapped, when an entry's channel has closed or the entry was killed.
+var ErrEntryClosed = errors.New("entry is closed")
+
+// ErrNoEntry is returned, wrapped, when no entry is loaded at the index, name or Handle given.
+var ErrNoEntry = errors.New("no such entry is loaded")
+
+// runningErr reports why the select can't be used as if running, or nil if it can.
+func (d *DynamicSelect) runningErr() error {
+	if !d.IsAlive() {
+		return ErrHalted
+	}
+
+	if !d.running.Load() {
+		return ErrNotRunning
+	}
+
+	return nil
+}
+
+// ErrStalled is carried, wrapped, by an EventEntryStalled, see ChannelEntry.Heartbeat.
+var ErrStalled = errors.New("entry stalled")
+
+// ErrAggregatorFull is reported for messages discarded by the OverflowError aggregator policy.
+var ErrAggregatorFull = errors.New("aggregator is full")
+
+// ErrTooManyGoroutines is reported, wrapped, when a select's goroutines exceed the limit set by WithGoroutineWatch.
+var ErrTooManyGoroutines = errors.New("too many goroutines")
+
+// ErrGoroutineLeak is reported, wrapped, when a select's goroutines outlive its shutdown, see WithGoroutineWatch.
+var ErrGoroutineLeak = errors.New("goroutines survived shutdown")
+
+// ErrKilled is the kill reason of a select halted by Kill.
+var ErrKilled = errors.New("DynamicSelect was killed")
+
+// PanicError is the kill reason of a select halted by a panic in its main loop.
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("DynamicSelect panicked: %v", e.Value)
+}
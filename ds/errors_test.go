@@ -0,0 +1,174 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrorSink(t *testing.T) {
+	failure := errors.New("could not handle")
+	c := make(chan interface{})
+	sunk := make(chan *HandlerError, 2)
+
+	failing := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Name:    "failing",
+		Channel: c,
+		Handler: HandlerEntry{
+			FuncErr: func(i interface{}) error {
+				if i == "bad" {
+					return failure
+				}
+				return nil
+			},
+			Blocking: true,
+		},
+	}}, WithErrorSink(func(err *HandlerError) { sunk <- err }))
+
+	failingReady := make(chan interface{})
+	go failing.Forever(failingReady)
+	<-failingReady
+	defer failing.Kill()
+
+	c <- "good"
+	c <- "bad"
+
+	select {
+	case err := <-sunk:
+		if !errors.Is(err, failure) || err.Message != "bad" || err.Name != "failing" || err.Index != 0 {
+			t.Errorf("Unexpected handler error: %+v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Handler error never reached the sink.")
+	}
+
+	time.Sleep(time.Second / 20)
+	if len(sunk) != 0 {
+		t.Errorf("A successful handler call reached the sink.")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	gate := make(chan interface{})
+	defer close(gate)
+
+	entries := []ChannelEntry{
+		{
+			Name:    "hangs",
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+			OnClose: OnCloseEntry{Func: func() { <-gate }, Blocking: true},
+		},
+		{
+			Name:    "waits",
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+			OnClose: OnCloseEntry{Func: func() { <-gate }, Blocking: true},
+		},
+	}
+
+	stuck := NewDynamicSelect(func() {}, entries, WithShutdownTimeout(time.Second/10))
+	stuckReady := make(chan interface{})
+	go stuck.Forever(stuckReady)
+	<-stuckReady
+
+	stuck.Kill()
+
+	waited := make(chan interface{})
+	go func() {
+		stuck.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatalf("Shut down was not forced after its timeout.")
+	}
+
+	var timeoutErr *ShutdownTimeoutError
+	if !errors.As(stuck.Err(), &timeoutErr) || len(timeoutErr.Entries) != 1 || !timeoutErr.Entries[0].Listening {
+		t.Fatalf("Expected one stuck listener, found: %v", stuck.Err())
+	}
+
+	if err := stuck.Reset(); err == nil {
+		t.Errorf("Reset a select whose shut down was forced.")
+	}
+}
+
+func TestKillReason(t *testing.T) {
+	operator := errors.New("operator shut down")
+	panicky := make(chan interface{})
+
+	run := func(opts ...Option) (*DynamicSelect, chan error) {
+		reasons := make(chan error, 1)
+		entries := []ChannelEntry{{
+			Channel: panicky,
+			Handler: HandlerEntry{Func: func(i interface{}) { panic(i) }, Blocking: true},
+		}}
+
+		d := NewDynamicSelect(func() {}, entries, append(opts, WithKillReasonAction(func(reason error) { reasons <- reason }))...)
+		r := make(chan interface{})
+		go d.Forever(r)
+		<-r
+		return d, reasons
+	}
+
+	d, reasons := run()
+	d.KillWithReason(operator)
+	if reason := <-reasons; reason != operator || d.KillReason() != operator {
+		t.Errorf("Expected the operator's reason, found %v", reason)
+	}
+
+	d, reasons = run()
+	d.Kill()
+	if reason := <-reasons; !errors.Is(reason, ErrKilled) {
+		t.Errorf("Expected ErrKilled, found %v", reason)
+	}
+
+	d, reasons = run()
+	panicky <- "boom"
+	var panicErr *PanicError
+	if reason := <-reasons; !errors.As(reason, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Expected a PanicError, found %v", reason)
+	}
+	d.Wait()
+}
+
+func TestSentinelErrors(t *testing.T) {
+	c := make(chan interface{})
+	sentinel := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Name: "only", Channel: c, Handler: HandlerEntry{Func: func(i interface{}) {}}},
+	})
+
+	if _, err := sentinel.Load(nil); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning loading before the select runs, got %v", err)
+	}
+
+	sentinelReady := make(chan interface{})
+	go sentinel.Forever(sentinelReady)
+	<-sentinelReady
+
+	if err := sentinel.Send(1, "hi"); !errors.Is(err, ErrNoEntry) {
+		t.Errorf("Expected ErrNoEntry sending to a missing entry, got %v", err)
+	}
+
+	if err := sentinel.KillNamed("only"); err != nil {
+		t.Fatalf("Failed to kill the entry: %v", err)
+	}
+
+	if err := sentinel.KillNamed("only"); !errors.Is(err, ErrEntryClosed) {
+		t.Errorf("Expected ErrEntryClosed killing the entry twice, got %v", err)
+	}
+
+	sentinel.Kill()
+	sentinel.Wait()
+
+	if _, err := sentinel.Load(nil); !errors.Is(err, ErrHalted) {
+		t.Errorf("Expected ErrHalted loading once the select halted, got %v", err)
+	}
+
+	if err := sentinel.SendNamed("only", "hi"); !errors.Is(err, ErrHalted) {
+		t.Errorf("Expected ErrHalted sending once the select halted, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package ds
+
+import (
+	"time"
+)
+
+// EventKind tells the events of a select's lifecycle apart.
+type EventKind int
+
+const (
+	// EventStarted is emitted once a run is listening to its Channels.
+	EventStarted EventKind = iota
+
+	// EventEntryLoaded is emitted for each entry loaded into a running select.
+	EventEntryLoaded
+
+	// EventEntryClosed is emitted once an entry's channel has closed, or the entry was killed,
+	// as its OnClose is called.
+	EventEntryClosed
+
+	// EventHandlerPanicked is emitted when a panic is recovered, Err is a *PanicError.
+	// A panic in the main loop, in a Blocking handler say, halts the select.
+	EventHandlerPanicked
+
+	// EventKillReceived is emitted once the select is killed, Err is the kill reason.
+	EventKillReceived
+
+	// EventEntryReopened is emitted when an entry's channel closed and Reopen replaced it.
+	EventEntryReopened
+
+	// EventEntryStalled is emitted when an entry has been silent for longer than its Heartbeat,
+	// Err wraps ErrStalled.
+	EventEntryStalled
+
+	// EventHandlerSlow is emitted when a Blocking handler runs past the threshold of the watchdog,
+	// see WithSlowHandlerWatchdog.
+	EventHandlerSlow
+
+	// EventShutdownComplete is the last event of a run, emitted once the select has finished
+	// shutting down, see Wait. Err is a *ShutdownTimeoutError if shutting down timed out.
+	EventShutdownComplete
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventStarted:
+		return "started"
+	case EventEntryLoaded:
+		return "entry loaded"
+	case EventEntryClosed:
+		return "entry closed"
+	case EventHandlerPanicked:
+		return "handler panicked"
+	case EventKillReceived:
+		return "kill received"
+	case EventEntryReopened:
+		return "entry reopened"
+	case EventEntryStalled:
+		return "entry stalled"
+	case EventHandlerSlow:
+		return "handler slow"
+	case EventShutdownComplete:
+		return "shutdown complete"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a step in the lifecycle of a select.
+type Event struct {
+	Kind EventKind
+	Time time.Time
+
+	// Index and Name identify the entry of entry events, Index is -1 otherwise.
+	Index int
+	Name  string
+
+	Err error
+}
+
+// eventBuffer is how many events wait for a reader before further events are dropped.
+const eventBuffer = 256
+
+// Events returns the channel the select's lifecycle events are emitted on, across every run.
+// Events are dropped rather than hold up the select if nobody reads them.
+func (d *DynamicSelect) Events() <-chan Event {
+	return d.events
+}
+
+// emit sends an event if there is room for it.
+func (d *DynamicSelect) emit(kind EventKind, index int, name string, err error) {
+	if d.logLevel >= LogDebug {
+		d.logf(LogDebug, "DynamicSelect %s, entry %d %q: %v\n", kind, index, name, err)
+	}
+
+	select {
+	case d.events <- Event{Kind: kind, Time: d.clock.Now(), Index: index, Name: name, Err: err}:
+	default:
+	}
+}
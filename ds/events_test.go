@@ -0,0 +1,67 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	first := make(chan interface{})
+	observed := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Channel: first, Handler: HandlerEntry{Func: func(i interface{}) {}}},
+	})
+
+	observedReady := make(chan interface{})
+	go observed.Forever(observedReady)
+	<-observedReady
+
+	if _, err := observed.LoadNamed("loaded", ChannelEntry{Channel: make(chan interface{}), Handler: HandlerEntry{Func: func(i interface{}) {}}}); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	close(first)
+	time.Sleep(time.Second / 20)
+	observed.Kill()
+
+	kinds := []EventKind{}
+	for {
+		select {
+		case ev := <-observed.Events():
+			kinds = append(kinds, ev.Kind)
+
+			switch ev.Kind {
+			case EventEntryLoaded:
+				if ev.Index != 1 || ev.Name != "loaded" {
+					t.Errorf("Expected the loaded event to name entry 1, got %d %q", ev.Index, ev.Name)
+				}
+			case EventKillReceived:
+				if !errors.Is(ev.Err, ErrKilled) {
+					t.Errorf("Expected the kill event to carry ErrKilled, got %v", ev.Err)
+				}
+			}
+
+			if ev.Kind != EventShutdownComplete {
+				continue
+			}
+
+		case <-time.After(time.Second):
+			t.Fatalf("Never heard the shutdown complete, heard %v", kinds)
+		}
+
+		break
+	}
+
+	// The loaded entry only closes during shut down.
+	expected := []EventKind{EventStarted, EventEntryLoaded, EventEntryClosed, EventKillReceived, EventEntryClosed, EventShutdownComplete}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Expected events %v, heard %v", expected, kinds)
+	}
+
+	for k := range expected {
+		if kinds[k] != expected[k] {
+			t.Errorf("Expected events %v, heard %v", expected, kinds)
+			break
+		}
+	}
+}
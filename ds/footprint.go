@@ -0,0 +1,63 @@
+package ds
+
+import (
+	"unsafe"
+)
+
+// Footprint describes the resources a DynamicSelect holds, for capacity planning.
+type Footprint struct {
+	// Goroutines counts the internal goroutines: main and shard loops, listeners and dispatcher workers.
+	// Handlers spawned by an unbounded dispatcher or OverflowSpawn are not included.
+	Goroutines int
+
+	// BufferedMessages counts messages waiting in internal channels and queues.
+	BufferedMessages int
+
+	// Entries counts the ChannelEntries loaded, open or closed.
+	Entries int
+
+	// QueueBytes approximates the memory held by internal channel buffers, queues and the entry list,
+	// excluding the messages themselves.
+	QueueBytes int
+}
+
+// Footprint reports the resources currently held by the DynamicSelect.
+func (d *DynamicSelect) Footprint() Footprint {
+	f := Footprint{
+		Goroutines: int(d.goroutines.Load()),
+	}
+
+	wrapperSize := int(unsafe.Sizeof(dsWrapper{}))
+	pointerSize := int(unsafe.Sizeof(uintptr(0)))
+
+	d.resetMu.RLock()
+	for _, s := range d.shards {
+		f.BufferedMessages += len(s.aggregator) + len(s.priorityAggregator)
+		f.QueueBytes += (cap(s.aggregator) + cap(s.priorityAggregator)) * pointerSize
+
+		for _, l := range s.levels {
+			f.BufferedMessages += len(l)
+			f.QueueBytes += cap(l) * pointerSize
+		}
+
+		if s.priorityQueue != nil {
+			queued := int(s.priorityQueue.length.Load())
+			f.BufferedMessages += queued
+			f.QueueBytes += queued * wrapperSize
+		}
+	}
+
+	f.BufferedMessages += len(d.onClose) + len(d.load) + len(d.dispatch.work)
+	f.QueueBytes += cap(d.onClose) * pointerSize
+	f.QueueBytes += cap(d.load) * int(unsafe.Sizeof([]ChannelEntry{}))
+	f.QueueBytes += cap(d.dispatch.work) * int(unsafe.Sizeof(dispatchJob{}))
+	f.Goroutines += int(d.dispatch.running.Load())
+	d.resetMu.RUnlock()
+
+	d.loadMu.Lock()
+	f.Entries = len(d.channels)
+	f.QueueBytes += cap(d.channels) * int(unsafe.Sizeof(ChannelEntry{}))
+	d.loadMu.Unlock()
+
+	return f
+}
@@ -0,0 +1,43 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFootprint(t *testing.T) {
+	defer reset()
+
+	selectMgr := NewDynamicSelect(func() {}, fullSet, WithShards(2), WithBuffers(Buffers{Aggregator: 4}))
+
+	f := selectMgr.Footprint()
+	if f.Goroutines != 0 {
+		t.Errorf("Expected no goroutines before running, found %d", f.Goroutines)
+	}
+
+	if f.Entries != len(fullSet) {
+		t.Errorf("Expected %d entries, found %d", len(fullSet), f.Entries)
+	}
+
+	if f.QueueBytes == 0 {
+		t.Errorf("Expected buffered aggregators to take up space.")
+	}
+
+	go selectMgr.Forever(ready)
+	<-ready
+	time.Sleep(time.Second / 100)
+
+	// The main loop, a second shard loop and a listener per entry.
+	f = selectMgr.Footprint()
+	if f.Goroutines != len(fullSet)+2 {
+		t.Errorf("Expected %d goroutines, found %d", len(fullSet)+2, f.Goroutines)
+	}
+
+	selectMgr.Kill()
+	time.Sleep(time.Second / 10)
+
+	f = selectMgr.Footprint()
+	if f.Goroutines != 0 {
+		t.Errorf("Expected goroutines to exit on kill, found %d", f.Goroutines)
+	}
+}
@@ -0,0 +1,77 @@
+package ds
+
+import (
+	"fmt"
+	"time"
+)
+
+// leakGrace is how long the goroutines of a run get to exit once its internal channels are drained,
+// before WithGoroutineWatch reports them as leaked.
+const leakGrace = time.Second
+
+// GoroutineCount reports the goroutines the DynamicSelect has running: its main and shard loops,
+// listeners, dispatcher workers and spawned handlers, and the helpers watching contexts, timers,
+// supervised producers and spills or draining on shutdown. It drops to zero once a killed select
+// has finished shutting down.
+func (d *DynamicSelect) GoroutineCount() int {
+	n := d.goroutines.Load() + d.helpers.Load()
+	n += d.dispatch.running.Load() + d.dispatch.inflight.Load()
+	return int(n)
+}
+
+// spawn runs f in a goroutine counted by GoroutineCount.
+func (d *DynamicSelect) spawn(f func()) {
+	d.helpers.Add(1)
+	d.checkGoroutines()
+
+	go func() {
+		defer d.helpers.Add(-1)
+		f()
+	}()
+}
+
+// checkGoroutines reports once each time GoroutineCount climbs above the limit of WithGoroutineWatch.
+func (d *DynamicSelect) checkGoroutines() {
+	if d.reportGoroutines == nil || d.goroutineLimit <= 0 {
+		return
+	}
+
+	n := d.GoroutineCount()
+	if n <= d.goroutineLimit {
+		d.overLimit.Store(false)
+		return
+	}
+
+	if d.overLimit.CompareAndSwap(false, true) {
+		d.reportGoroutines(fmt.Errorf("%w: %d running, limit %d", ErrTooManyGoroutines, n, d.goroutineLimit))
+	}
+}
+
+// watchLeaks reports the goroutines still running leakGrace after the current run has drained
+// its internal channels, such as those of a stuck handler. It is not itself counted.
+func (d *DynamicSelect) watchLeaks() {
+	if d.reportGoroutines == nil {
+		return
+	}
+
+	drained := d.drained
+	go func() {
+		<-drained
+
+		poll := d.clock.NewTicker(10 * time.Millisecond)
+		defer poll.Stop()
+		grace := d.clock.NewTimer(leakGrace)
+		defer grace.Stop()
+
+		for d.GoroutineCount() > 0 {
+			select {
+			case <-poll.C():
+			case <-grace.C():
+				if n := d.GoroutineCount(); n > 0 {
+					d.reportGoroutines(fmt.Errorf("%w: %d still running after %s", ErrGoroutineLeak, n, leakGrace))
+				}
+				return
+			}
+		}
+	}()
+}
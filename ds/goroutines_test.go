@@ -0,0 +1,128 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGoroutineCount(t *testing.T) {
+	reports := make(chan error, 8)
+	entries := make([]ChannelEntry, 3)
+	for k := range entries {
+		entries[k] = ChannelEntry{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) {}},
+		}
+	}
+
+	counted := NewDynamicSelect(func() {}, entries, WithGoroutineWatch(2, func(err error) { reports <- err }))
+	if n := counted.GoroutineCount(); n != 0 {
+		t.Errorf("Expected no goroutines before running, found %d", n)
+	}
+
+	countedReady := make(chan interface{})
+	go counted.Forever(countedReady)
+	<-countedReady
+
+	// At least the main loop and a listener per entry, once they are all listening.
+	time.Sleep(time.Second / 100)
+	if n := counted.GoroutineCount(); n < len(entries)+1 {
+		t.Errorf("Expected at least %d goroutines, found %d", len(entries)+1, n)
+	}
+
+	select {
+	case err := <-reports:
+		if !errors.Is(err, ErrTooManyGoroutines) {
+			t.Errorf("Expected too many goroutines, found: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Exceeding the limit went unreported.")
+	}
+
+	counted.Kill()
+	counted.Wait()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for counted.GoroutineCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected goroutines to exit on kill, found %d", counted.GoroutineCount())
+		}
+		time.Sleep(time.Second / 100)
+	}
+
+	select {
+	case err := <-reports:
+		t.Errorf("Unexpected report after a clean shutdown: %v", err)
+	case <-time.After(leakGrace + time.Second/2):
+	}
+}
+
+func TestGoroutineLeak(t *testing.T) {
+	reports := make(chan error, 8)
+	gate := make(chan interface{})
+	defer close(gate)
+
+	entries := []ChannelEntry{{
+		Channel: make(chan interface{}),
+		Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+		OnClose: OnCloseEntry{Func: func() { <-gate }, Blocking: true},
+	}}
+
+	leaky := NewDynamicSelect(func() {}, entries,
+		WithShutdownTimeout(time.Second/10),
+		WithGoroutineWatch(0, func(err error) { reports <- err }))
+	leakyReady := make(chan interface{})
+	go leaky.Forever(leakyReady)
+	<-leakyReady
+
+	leaky.Kill()
+
+	select {
+	case err := <-reports:
+		if !errors.Is(err, ErrGoroutineLeak) {
+			t.Errorf("Expected a leak, found: %v", err)
+		}
+	case <-time.After(leakGrace + 2*time.Second):
+		t.Fatalf("The stuck listener went unreported.")
+	}
+}
+
+func TestGoroutineLeakManualClock(t *testing.T) {
+	reports := make(chan error, 8)
+	gate := make(chan interface{})
+	defer close(gate)
+
+	entries := []ChannelEntry{{
+		Channel: make(chan interface{}),
+		Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+		OnClose: OnCloseEntry{Func: func() { <-gate }, Blocking: true},
+	}}
+
+	clock := NewManualClock(time.Now())
+	leaky := NewDynamicSelect(func() {}, entries,
+		WithClock(clock),
+		WithShutdownTimeout(time.Second/10),
+		WithGoroutineWatch(0, func(err error) { reports <- err }))
+	leakyReady := make(chan interface{})
+	go leaky.Forever(leakyReady)
+	<-leakyReady
+
+	leaky.Kill()
+
+	// Only the clock's time passes, past the shutdown timeout then the leak grace period.
+	giveUp := time.After(2 * time.Second)
+	for {
+		select {
+		case err := <-reports:
+			if !errors.Is(err, ErrGoroutineLeak) {
+				t.Errorf("Expected a leak, found: %v", err)
+			}
+			return
+		case <-time.After(time.Millisecond * 10):
+			clock.Advance(leakGrace)
+		case <-giveUp:
+			t.Fatalf("The stuck listener went unreported.")
+		}
+	}
+}
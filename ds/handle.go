@@ -0,0 +1,108 @@
+package ds
+
+import (
+	"fmt"
+	"time"
+)
+
+// Handle refers to an entry loaded into a select, see Load. Indices shift meaning as entries come
+// and go, and two concurrent Loads can't know which indices they will land on, a Handle doesn't.
+// An entry is only found once the main loop has taken it in, shortly after Load returns.
+// A Handle can't pause its entry, Kill it and Load it again.
+type Handle struct {
+	d  *DynamicSelect
+	id uint64
+}
+
+// Index returns the entry's index within Channels, if it is loaded.
+func (h Handle) Index() (int, bool) {
+	if h.d == nil {
+		return -1, false
+	}
+
+	h.d.loadMu.Lock()
+	defer h.d.loadMu.Unlock()
+
+	return h.d.indexOfID(h.id)
+}
+
+// Entry returns the entry, if it is loaded.
+func (h Handle) Entry() (ChannelEntry, bool) {
+	var e ChannelEntry
+	err := h.with(func(i int) error {
+		e = h.d.channels[i]
+		return nil
+	})
+
+	return e, err == nil
+}
+
+// Stats reports the counters kept for the entry, if it is loaded.
+func (h Handle) Stats() (EntryStats, bool) {
+	var stats EntryStats
+	err := h.with(func(i int) error {
+		stats = h.d.channels[i].statsAt(i)
+		return nil
+	})
+
+	return stats, err == nil
+}
+
+// Replay returns the messages journaled for the entry since the time given, as DynamicSelect.Replay does.
+func (h Handle) Replay(since time.Time) ([]interface{}, error) {
+	var msgs []interface{}
+	err := h.with(func(i int) error {
+		var err error
+		msgs, err = h.d.replay(i, since)
+		return err
+	})
+
+	return msgs, err
+}
+
+// Kill stops listening to the entry, as KillNamed does, whether or not it has a Name.
+func (h Handle) Kill() error {
+	return h.with(h.d.killAt)
+}
+
+// ReplaceHandler swaps the entry's handler, as DynamicSelect.ReplaceHandler does.
+func (h Handle) ReplaceHandler(handler HandlerEntry) error {
+	return h.with(func(i int) error {
+		return h.d.replaceHandler(i, handler)
+	})
+}
+
+// Reconfigure changes how messages reach the entry's handler, as DynamicSelect.Reconfigure does.
+func (h Handle) Reconfigure(r Routing) error {
+	return h.with(func(i int) error {
+		return h.d.reconfigure(i, r)
+	})
+}
+
+// with calls f with the entry's index while holding loadMu, or errors if it isn't loaded.
+func (h Handle) with(f func(i int) error) error {
+	if h.d == nil {
+		return fmt.Errorf("%w: the handle refers to no select", ErrNoEntry)
+	}
+
+	h.d.loadMu.Lock()
+	defer h.d.loadMu.Unlock()
+
+	i, ok := h.d.indexOfID(h.id)
+	if !ok {
+		return fmt.Errorf("%w: the handle's entry is gone or not yet taken in", ErrNoEntry)
+	}
+
+	return f(i)
+}
+
+// indexOfID finds the index of the entry with the given id. The caller must hold loadMu.
+func (d *DynamicSelect) indexOfID(id uint64) (int, bool) {
+	for i, e := range d.channels {
+		if e.id == id {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
@@ -0,0 +1,168 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHandles(t *testing.T) {
+	handled := make(chan interface{}, 4)
+	handler := HandlerEntry{Func: func(i interface{}) { handled <- i }}
+
+	handling := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Channel: make(chan interface{}), Handler: handler},
+	})
+	handlingReady := make(chan interface{})
+	go handling.Forever(handlingReady)
+	<-handlingReady
+	defer handling.Kill()
+
+	first, second := make(chan interface{}), make(chan interface{})
+	firstClosed := make(chan struct{})
+
+	// Two concurrent Loads can't know where they land, their handles can.
+	var firstHandles, secondHandles []Handle
+	loaded := make(chan error, 2)
+	go func() {
+		var err error
+		firstHandles, err = handling.Load([]ChannelEntry{{
+			Channel: first,
+			Handler: handler,
+			OnClose: OnCloseEntry{Func: func() { close(firstClosed) }},
+		}})
+		loaded <- err
+	}()
+	go func() {
+		var err error
+		secondHandles, err = handling.Load([]ChannelEntry{{Name: "second", Channel: second, Handler: handler}})
+		loaded <- err
+	}()
+
+	for k := 0; k < 2; k++ {
+		if err := <-loaded; err != nil {
+			t.Fatalf("Failed to load: %v", err)
+		}
+	}
+	time.Sleep(time.Second / 20)
+
+	for _, c := range []struct {
+		h       Handle
+		channel chan interface{}
+	}{{firstHandles[0], first}, {secondHandles[0], second}} {
+		i, ok := c.h.Index()
+		if !ok {
+			t.Fatalf("Expected the handle's entry to be loaded")
+		}
+
+		if e, _ := c.h.Entry(); e.Channel != c.channel || handling.Channels()[i].Channel != c.channel {
+			t.Errorf("Expected the handle to find its own entry at index %d", i)
+		}
+	}
+
+	second <- 1
+	<-handled
+	if stats, _ := secondHandles[0].Stats(); stats.Handled != 1 {
+		t.Errorf("Expected the handle's entry to have handled 1 message, found %d", stats.Handled)
+	}
+
+	// Unnamed entries are killed alone too.
+	if err := firstHandles[0].Kill(); err != nil {
+		t.Errorf("Failed to kill the unnamed entry: %v", err)
+	}
+
+	select {
+	case <-firstClosed:
+	case <-time.After(time.Second):
+		t.Fatalf("Killing an unnamed entry by its handle did not run its OnClose.")
+	}
+
+	if err := firstHandles[0].Kill(); !errors.Is(err, ErrEntryClosed) {
+		t.Errorf("Expected ErrEntryClosed killing an entry twice, got %v", err)
+	}
+
+	if err := secondHandles[0].Kill(); err != nil {
+		t.Errorf("Failed to kill the named entry: %v", err)
+	}
+
+	if _, ok := (Handle{}).Index(); ok {
+		t.Errorf("Expected the zero Handle to find nothing")
+	}
+}
+
+func TestHandleKillGrouped(t *testing.T) {
+	heard := make(chan interface{}, 1)
+	closed := make(chan struct{})
+	kept, killed := make(chan interface{}), make(chan interface{})
+
+	grouped := NewDynamicSelect(func() {}, []ChannelEntry{}, WithListenerGroups(4))
+	groupedReady := make(chan interface{})
+	go grouped.Forever(groupedReady)
+	<-groupedReady
+	defer grouped.Kill()
+
+	handles, err := grouped.LoadWait([]ChannelEntry{
+		{Channel: kept, Handler: HandlerEntry{Func: func(i interface{}) { heard <- i }}},
+		{Channel: killed, Handler: HandlerEntry{Func: func(i interface{}) {}}, OnClose: OnCloseEntry{Func: func() { close(closed) }}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	// The group stops listening to the killed entry alone.
+	if err := handles[1].Kill(); err != nil {
+		t.Fatalf("Failed to kill a grouped entry: %v", err)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("Killing a grouped entry did not run its OnClose.")
+	}
+
+	select {
+	case killed <- 1:
+		t.Errorf("Expected the killed entry no longer listened to")
+	case <-time.After(time.Second / 20):
+	}
+
+	kept <- 2
+	if x := <-heard; x != 2 {
+		t.Errorf("Expected the kept entry still heard, heard %v", x)
+	}
+}
+
+func TestLoadWait(t *testing.T) {
+	heard := make(chan interface{}, 1)
+
+	waiting := NewDynamicSelect(func() {}, []ChannelEntry{}, WithListenerGroups(8))
+	waitingReady := make(chan interface{})
+	go waiting.Forever(waitingReady)
+	<-waitingReady
+	defer waiting.Kill()
+
+	entries := []ChannelEntry{}
+	for k := 0; k < 10; k++ {
+		entries = append(entries, ChannelEntry{Channel: make(chan interface{}), Handler: HandlerEntry{Func: func(i interface{}) { heard <- i }}})
+	}
+	entries = append(entries, Typed(make(chan int), TypedHandlerEntry[int]{}, OnCloseEntry{}))
+
+	handles, err := waiting.LoadWait(entries)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+
+	for k, h := range handles {
+		if _, ok := h.Index(); !ok {
+			t.Fatalf("Expected entry %d to be loaded once LoadWait returned", k)
+		}
+	}
+
+	select {
+	case entries[9].Channel <- "hi":
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the entry to be listened to once LoadWait returned")
+	}
+
+	<-heard
+}
@@ -0,0 +1,53 @@
+package ds
+
+import (
+	"fmt"
+	"time"
+)
+
+// hear records that a message for the entry was heard at now.
+func (s *entryStats) hear(now time.Time) {
+	if s != nil {
+		s.lastHeard.Store(now.UnixNano())
+	}
+}
+
+// watchHeartbeat reports entry i stalled whenever it goes silent for longer than its Heartbeat,
+// until it is no longer listened to or done is closed.
+func (d *DynamicSelect) watchHeartbeat(i int, e ChannelEntry, done chan interface{}) {
+	timer := d.clock.NewTimer(e.Heartbeat)
+	defer timer.Stop()
+
+	// reported is the silent spell last reported, by when it began.
+	var reported int64
+	for {
+		select {
+		case <-done:
+			return
+		case <-e.stop:
+			return
+		case <-timer.C():
+		}
+
+		if !e.stats.listening.Load() {
+			return
+		}
+
+		last := e.stats.lastHeard.Load()
+		silence := d.clock.Now().Sub(time.Unix(0, last))
+		if silence < e.Heartbeat {
+			timer.Reset(e.Heartbeat - silence)
+			continue
+		}
+
+		if last != reported {
+			reported = last
+			d.emit(EventEntryStalled, i, e.Name, fmt.Errorf("%w for %s", ErrStalled, silence))
+			if e.OnStall != nil {
+				e.OnStall(silence)
+			}
+		}
+
+		timer.Reset(e.Heartbeat)
+	}
+}
@@ -0,0 +1,74 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	stalls := make(chan time.Duration, 4)
+	c := make(chan interface{})
+
+	watched := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Name:      "upstream",
+		Channel:   c,
+		Handler:   HandlerEntry{Func: func(i interface{}) {}},
+		Heartbeat: time.Second / 20,
+		OnStall:   func(silence time.Duration) { stalls <- silence },
+	}})
+	watchedReady := make(chan interface{})
+	go watched.Forever(watchedReady)
+	<-watchedReady
+	defer watched.Kill()
+
+	// Chatter keeps the entry from stalling.
+	for k := 0; k < 5; k++ {
+		c <- k
+		time.Sleep(time.Second / 50)
+	}
+
+	if len(stalls) > 0 {
+		t.Fatalf("Expected no stall while messages arrive, stalled after %s", <-stalls)
+	}
+
+	// A silent spell is reported once.
+	select {
+	case silence := <-stalls:
+		if silence < time.Second/20 {
+			t.Errorf("Expected the stall after at least the heartbeat, was %s", silence)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the silent entry to stall")
+	}
+
+	time.Sleep(time.Second / 5)
+	if len(stalls) > 0 {
+		t.Errorf("Expected one stall per silent spell")
+	}
+
+	// A message ends the spell, the next one is reported anew.
+	c <- 1
+	select {
+	case <-stalls:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a second silent spell to stall")
+	}
+
+	for {
+		select {
+		case ev := <-watched.Events():
+			if ev.Kind != EventEntryStalled {
+				continue
+			}
+
+			if ev.Name != "upstream" || !errors.Is(ev.Err, ErrStalled) {
+				t.Errorf("Expected a stall event for upstream, got %+v", ev)
+			}
+		default:
+			t.Errorf("Expected a stall event")
+		}
+
+		break
+	}
+}
@@ -0,0 +1,128 @@
+package ds
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Histograms are log-linear in the manner of HDR histograms: every power of two nanoseconds
+// is split into histogramSubBuckets buckets, bounding the error of any reading to 1/8th.
+// Durations past 2^histogramMaxExp nanoseconds, about 18 minutes, land in the last bucket.
+const (
+	histogramSubBits    = 3
+	histogramSubBuckets = 1 << histogramSubBits
+	histogramMaxExp     = 40
+	histogramBuckets    = (histogramMaxExp - histogramSubBits + 2) * histogramSubBuckets
+)
+
+// Histogram records durations concurrently into log-linear buckets.
+type Histogram struct {
+	counts [histogramBuckets]atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Uint64
+	max    atomic.Uint64
+}
+
+// Record adds a duration to the histogram, negative durations count as 0.
+func (h *Histogram) Record(d time.Duration) {
+	v := uint64(0)
+	if d > 0 {
+		v = uint64(d)
+	}
+
+	h.counts[histogramBucket(v)].Add(1)
+	h.count.Add(1)
+	h.sum.Add(v)
+
+	for {
+		old := h.max.Load()
+		if v <= old || h.max.CompareAndSwap(old, v) {
+			return
+		}
+	}
+}
+
+// Snapshot copies the histogram's current state.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Count: h.count.Load(),
+		Sum:   time.Duration(h.sum.Load()),
+		Max:   time.Duration(h.max.Load()),
+	}
+
+	for b := range h.counts {
+		if n := h.counts[b].Load(); n > 0 {
+			s.Buckets = append(s.Buckets, Bucket{UpperBound: histogramUpperBound(b), Count: n})
+		}
+	}
+
+	return s
+}
+
+// HistogramSnapshot is a point in time copy of a Histogram. Only non-empty buckets are kept.
+type HistogramSnapshot struct {
+	Buckets []Bucket
+	Count   uint64
+	Sum     time.Duration
+	Max     time.Duration
+}
+
+// Bucket counts the durations below UpperBound and at or above the previous bucket's UpperBound.
+type Bucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// Quantile estimates the duration below which the fraction q of recordings fall, e.g. 0.99 for P99.
+func (s HistogramSnapshot) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	rank := uint64(q * float64(s.Count))
+	seen := uint64(0)
+	for _, b := range s.Buckets {
+		seen += b.Count
+		if seen > rank {
+			if b.UpperBound > s.Max {
+				return s.Max
+			}
+			return b.UpperBound
+		}
+	}
+
+	return s.Max
+}
+
+// Mean is the average duration recorded.
+func (s HistogramSnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.Sum / time.Duration(s.Count)
+}
+
+func histogramBucket(v uint64) int {
+	if v < histogramSubBuckets {
+		return int(v)
+	}
+
+	exp := bits.Len64(v) - 1
+	if exp > histogramMaxExp {
+		return histogramBuckets - 1
+	}
+
+	sub := int(v>>(exp-histogramSubBits)) - histogramSubBuckets
+	return (exp-histogramSubBits+1)*histogramSubBuckets + sub
+}
+
+func histogramUpperBound(b int) time.Duration {
+	if b < histogramSubBuckets {
+		return time.Duration(b + 1)
+	}
+
+	group, sub := b/histogramSubBuckets, b%histogramSubBuckets
+	return time.Duration(uint64(histogramSubBuckets+sub+1) << (group - 1))
+}
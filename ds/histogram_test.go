@@ -0,0 +1,50 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	for _, v := range []uint64{0, 1, 7, 8, 9, 15, 16, 17, 1000, 123456789, 1 << 40} {
+		b := histogramBucket(v)
+		upper := uint64(histogramUpperBound(b))
+		if v >= upper {
+			t.Errorf("%d landed in bucket %d with upper bound %d", v, b, upper)
+		}
+
+		if b > 0 && v < uint64(histogramUpperBound(b-1)) {
+			t.Errorf("%d landed in bucket %d but is below its lower bound", v, b)
+		}
+	}
+
+	if histogramBucket(1<<62) != histogramBuckets-1 {
+		t.Errorf("Huge durations did not land in the last bucket.")
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := &Histogram{}
+	for i := 1; i <= 100; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	s := h.Snapshot()
+	if s.Count != 100 {
+		t.Errorf("Expected 100 recordings, found %d", s.Count)
+	}
+
+	if s.Max != 100*time.Millisecond {
+		t.Errorf("Expected a max of 100ms, found %s", s.Max)
+	}
+
+	p50 := s.Quantile(0.5)
+	if p50 < 50*time.Millisecond || p50 > 57*time.Millisecond {
+		t.Errorf("Expected P50 near 50ms, found %s", p50)
+	}
+
+	p99 := s.Quantile(0.99)
+	if p99 < 99*time.Millisecond || p99 > 100*time.Millisecond {
+		t.Errorf("Expected P99 near 99ms, found %s", p99)
+	}
+}
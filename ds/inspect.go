@@ -0,0 +1,174 @@
+package ds
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+)
+
+// State is a point in time view of a DynamicSelect, as served to conquerctl. It is meant for
+// debugging a select that appears hung.
+type State struct {
+	Alive     bool   `json:"alive"`
+	Running   bool   `json:"running"`
+	Lifecycle string `json:"lifecycle"`
+
+	// Tier is the state machine tier the main loop last entered, "priority" or "all",
+	// empty unless running. Entries with Handling set show which handlers it may be stuck in.
+	Tier string `json:"tier,omitempty"`
+
+	// PendingLoads counts the Loads waiting for the main loop to take them in.
+	PendingLoads int `json:"pendingLoads"`
+
+	Footprint Footprint    `json:"footprint"`
+	Shards    []ShardState `json:"shards"`
+	Entries   []EntryState `json:"entries"`
+}
+
+// ShardState counts the messages waiting in one shard's aggregators within a State, see WithShards.
+type ShardState struct {
+	Aggregator         int   `json:"aggregator"`
+	PriorityAggregator int   `json:"priorityAggregator"`
+	PriorityQueue      int   `json:"priorityQueue"`
+	Levels             []int `json:"levels,omitempty"`
+}
+
+// EntryState describes one ChannelEntry within a State.
+type EntryState struct {
+	Index    int    `json:"index"`
+	Name     string `json:"name,omitempty"`
+	Closed   bool   `json:"closed"`
+	Blocking bool   `json:"blocking"`
+	Priority bool   `json:"priority"`
+	Level    int    `json:"level"`
+
+	// Buffered is the number of messages waiting in the entry's channel, of Capacity.
+	Buffered int `json:"buffered"`
+	Capacity int `json:"capacity"`
+
+	Handled uint64 `json:"handled"`
+
+	// Typed and Batched are set for entries built by Typed or Batched.
+	Typed   bool `json:"typed,omitempty"`
+	Batched bool `json:"batched,omitempty"`
+
+	// Listening is set while a listener hears the entry, Handling counts its handler calls in progress.
+	Listening bool  `json:"listening"`
+	Handling  int32 `json:"handling"`
+}
+
+// DumpState reports the lifecycle state of the DynamicSelect and of each of its entries.
+func (d *DynamicSelect) DumpState() State {
+	s := State{
+		Alive:     d.IsAlive(),
+		Running:   d.running.Load(),
+		Lifecycle: d.Lifecycle().String(),
+		Footprint: d.Footprint(),
+	}
+
+	if s.Running {
+		s.Tier = tier(d.tier.Load()).String()
+	}
+
+	d.resetMu.RLock()
+	s.PendingLoads = len(d.load)
+	for _, sh := range d.shards {
+		ss := ShardState{
+			Aggregator:         len(sh.aggregator),
+			PriorityAggregator: len(sh.priorityAggregator),
+		}
+
+		if sh.priorityQueue != nil {
+			ss.PriorityQueue = int(sh.priorityQueue.length.Load())
+		}
+
+		for _, l := range sh.levels {
+			ss.Levels = append(ss.Levels, len(l))
+		}
+
+		s.Shards = append(s.Shards, ss)
+	}
+	d.resetMu.RUnlock()
+
+	stats := d.Stats()
+	for i, e := range d.Channels() {
+		es := EntryState{
+			Index:    i,
+			Name:     e.Name,
+			Closed:   e.IsClosed,
+			Blocking: e.Handler.Blocking,
+			Priority: e.Handler.level() > 0,
+			Level:    e.Handler.level(),
+			Buffered: len(e.Channel),
+			Capacity: cap(e.Channel),
+			Typed:    e.typed != nil,
+			Batched:  e.batch != nil,
+		}
+
+		if e.stats != nil {
+			es.Listening = e.stats.listening.Load()
+			es.Handling = e.stats.handling.Load()
+		}
+
+		if i < len(stats) {
+			es.Handled = stats[i].Handled
+		}
+
+		s.Entries = append(s.Entries, es)
+	}
+
+	return s
+}
+
+// tier is a tier of the main loop's state machine, see State.Tier.
+type tier int32
+
+const (
+	tierPriority tier = iota
+	tierAll
+)
+
+func (t tier) String() string {
+	if t == tierAll {
+		return "all"
+	}
+
+	return "priority"
+}
+
+// ServeInspector writes the DumpState of the DynamicSelect, as JSON, to each connection accepted on l,
+// then closes it. It returns once l is closed.
+func (d *DynamicSelect) ServeInspector(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+			json.NewEncoder(conn).Encode(d.DumpState())
+		}()
+	}
+}
+
+// ListenInspector serves the DynamicSelect's state on a unix socket at path, for conquerctl to read.
+// A stale socket left at path is removed first. Closing the returned listener stops serving.
+func (d *DynamicSelect) ListenInspector(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	go d.ServeInspector(l)
+	return l, nil
+}
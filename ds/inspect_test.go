@@ -0,0 +1,88 @@
+package ds
+
+import (
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInspector(t *testing.T) {
+	c := make(chan interface{}, 4)
+	entries := []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+	}}
+
+	inspected := NewDynamicSelect(func() {}, entries)
+	inspectedReady := make(chan interface{})
+	go inspected.Forever(inspectedReady)
+	<-inspectedReady
+	defer inspected.Kill()
+
+	c <- unit
+	time.Sleep(time.Second / 20)
+
+	path := filepath.Join(t.TempDir(), "ds.sock")
+	l, err := inspected.ListenInspector(path)
+	if err != nil {
+		t.Fatalf("Could not listen: %s", err.Error())
+	}
+	defer l.Close()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Could not dial inspector: %s", err.Error())
+	}
+	defer conn.Close()
+
+	s := State{}
+	if err := json.NewDecoder(conn).Decode(&s); err != nil {
+		t.Fatalf("Could not decode state: %s", err.Error())
+	}
+
+	if !s.Alive || !s.Running {
+		t.Errorf("Expected state to be alive and running.")
+	}
+
+	if len(s.Entries) != 1 || s.Entries[0].Handled != 1 || s.Entries[0].Capacity != 4 || !s.Entries[0].Blocking {
+		t.Errorf("Unexpected entry state: %+v", s.Entries)
+	}
+}
+
+func TestDumpStateHung(t *testing.T) {
+	c := make(chan interface{})
+	release := make(chan struct{})
+	entries := []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{Func: func(i interface{}) { <-release }, Blocking: true},
+	}}
+
+	hung := NewDynamicSelect(func() {}, entries, WithShards(2))
+	hungReady := make(chan interface{})
+	go hung.Forever(hungReady)
+	<-hungReady
+	defer hung.Kill()
+	defer close(release)
+
+	c <- unit
+	time.Sleep(time.Second / 20)
+
+	s := hung.DumpState()
+	if s.Lifecycle != "running" || s.Tier != "all" {
+		t.Errorf("Expected a running select in the all tier, found %q in %q", s.Lifecycle, s.Tier)
+	}
+
+	if len(s.Shards) != 2 {
+		t.Errorf("Expected the state of 2 shards, found %d", len(s.Shards))
+	}
+
+	if len(s.Entries) != 1 || s.Entries[0].Handling != 1 || !s.Entries[0].Listening {
+		t.Errorf("Expected the entry to be listened to and stuck handling, found %+v", s.Entries)
+	}
+
+	if _, err := json.Marshal(s); err != nil {
+		t.Errorf("Expected the state to serialize: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package ds
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// journal is a ring of the last messages routed to an entry's handler, see ChannelEntry.Journal.
+type journal struct {
+	mu      sync.Mutex
+	entries []journalEntry
+
+	// next is where the next message is recorded, full is set once the ring has wrapped.
+	next int
+	full bool
+}
+
+type journalEntry struct {
+	at  time.Time
+	msg interface{}
+}
+
+func newJournal(size int) *journal {
+	return &journal{entries: make([]journalEntry, size)}
+}
+
+// record remembers msg as routed at, forgetting the oldest message if the ring is full.
+func (j *journal) record(msg interface{}, at time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries[j.next] = journalEntry{at: at, msg: msg}
+	j.next = (j.next + 1) % len(j.entries)
+	if j.next == 0 {
+		j.full = true
+	}
+}
+
+// since returns the messages remembered as routed at or after t, oldest first.
+func (j *journal) since(t time.Time) []interface{} {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ordered := j.entries[:j.next]
+	if j.full {
+		ordered = append(append([]journalEntry{}, j.entries[j.next:]...), ordered...)
+	}
+
+	msgs := []interface{}{}
+	for _, e := range ordered {
+		if !e.at.Before(t) {
+			msgs = append(msgs, e.msg)
+		}
+	}
+
+	return msgs
+}
+
+// Replay returns the messages journaled for the entry at index, see ChannelEntry.Journal, that
+// were routed to its handler at or after since, oldest first. An entry loaded late may pass them
+// to its own handler to catch up with what it missed. It errors if there is no entry at index,
+// or it keeps no journal.
+func (d *DynamicSelect) Replay(index int, since time.Time) ([]interface{}, error) {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	return d.replay(index, since)
+}
+
+// replay is Replay. The caller must hold loadMu.
+func (d *DynamicSelect) replay(index int, since time.Time) ([]interface{}, error) {
+	if index < 0 || index >= len(d.channels) {
+		return nil, fmt.Errorf("%w: no entry at index %d", ErrNoEntry, index)
+	}
+
+	j := d.channels[index].journal
+	if j == nil {
+		return nil, fmt.Errorf("%w: entry %d", ErrNoJournal, index)
+	}
+
+	return j.since(since), nil
+}
@@ -0,0 +1,78 @@
+package ds
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestJournalRing(t *testing.T) {
+	j := newJournal(3)
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		j.record(i, start.Add(time.Duration(i)*time.Second))
+	}
+
+	if got := fmt.Sprint(j.since(time.Time{})); got != "[2 3 4]" {
+		t.Errorf("Expected the ring to keep the last three messages [2 3 4], kept %s", got)
+	}
+
+	if got := fmt.Sprint(j.since(start.Add(3 * time.Second))); got != "[3 4]" {
+		t.Errorf("Expected the messages since the fourth to be [3 4], found %s", got)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	heard := make(chan interface{}, 10)
+	c := make(chan interface{})
+
+	journaling := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{Func: func(i interface{}) { heard <- i }, Blocking: true},
+		Journal: 2,
+	}})
+	journalingReady := make(chan interface{})
+	go journaling.Forever(journalingReady)
+	<-journalingReady
+	defer journaling.Kill()
+
+	since := time.Now()
+	for i := 0; i < 3; i++ {
+		c <- i
+		<-heard
+	}
+
+	msgs, err := journaling.Replay(0, since)
+	if err != nil {
+		t.Fatalf("Failed to replay: %v", err)
+	}
+
+	if fmt.Sprint(msgs) != "[1 2]" {
+		t.Errorf("Expected to replay the last two messages [1 2], replayed %v", msgs)
+	}
+
+	if msgs, _ := journaling.Replay(0, time.Now()); len(msgs) != 0 {
+		t.Errorf("Expected nothing to replay since now, replayed %v", msgs)
+	}
+
+	// A late consumer catches up through its handle.
+	late := make(chan interface{})
+	handles, err := journaling.Load([]ChannelEntry{{
+		Channel: late,
+		Handler: HandlerEntry{Func: func(i interface{}) {}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	time.Sleep(time.Second / 20)
+
+	if _, err := handles[0].Replay(since); !errors.Is(err, ErrNoJournal) {
+		t.Errorf("Expected ErrNoJournal replaying an entry without a journal, got: %v", err)
+	}
+
+	if _, err := journaling.Replay(5, since); !errors.Is(err, ErrNoEntry) {
+		t.Errorf("Expected replaying a missing entry to fail with ErrNoEntry, found %v", err)
+	}
+}
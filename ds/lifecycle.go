@@ -0,0 +1,64 @@
+package ds
+
+// LifecycleState is where a select is in its lifecycle, see StateChanges.
+type LifecycleState int32
+
+const (
+	// StateIdle is a select yet to run, or Reset since it last ran.
+	StateIdle LifecycleState = iota
+
+	// StateStarting is a select starting its loops and listeners.
+	StateStarting
+
+	// StateRunning is a select listening to its entries, once Ready is closed.
+	StateRunning
+
+	// StateDraining is a killed select shutting down.
+	StateDraining
+
+	// StateStopped is a select that has finished shutting down, as Wait returns.
+	StateStopped
+)
+
+func (s LifecycleState) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// stateBuffer is how many transitions wait for a reader before further transitions are dropped,
+// enough for a few runs.
+const stateBuffer = 16
+
+// StateChanges returns the channel each transition of the select's lifecycle is sent on, across
+// every run, so supervising code can wait on a transition rather than poll IsAlive. Transitions
+// are dropped rather than hold up the select if nobody reads them, see Lifecycle.
+func (d *DynamicSelect) StateChanges() <-chan LifecycleState {
+	return d.stateChanges
+}
+
+// Lifecycle reports where the select is in its lifecycle.
+func (d *DynamicSelect) Lifecycle() LifecycleState {
+	return LifecycleState(d.lifecycle.Load())
+}
+
+// transition records the select moving to state s.
+func (d *DynamicSelect) transition(s LifecycleState) {
+	d.lifecycle.Store(int32(s))
+
+	select {
+	case d.stateChanges <- s:
+	default:
+	}
+}
@@ -0,0 +1,52 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStateChanges(t *testing.T) {
+	watched := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: make(chan interface{}),
+		Handler: HandlerEntry{Func: func(i interface{}) {}},
+	}})
+
+	if watched.Lifecycle() != StateIdle {
+		t.Errorf("Expected a select yet to run to be idle, found %s", watched.Lifecycle())
+	}
+
+	go watched.Forever(make(chan interface{}))
+	expectStates(t, watched, StateStarting, StateRunning)
+
+	watched.Kill()
+	expectStates(t, watched, StateDraining, StateStopped)
+
+	if watched.Lifecycle() != StateStopped {
+		t.Errorf("Expected a killed select to be stopped, found %s", watched.Lifecycle())
+	}
+
+	if err := watched.Reset(); err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+	expectStates(t, watched, StateIdle)
+
+	if LifecycleState(-1).String() != "unknown" {
+		t.Errorf("Unexpected name for an unknown state")
+	}
+}
+
+// expectStates reads the transitions expected from d, in order.
+func expectStates(t *testing.T, d *DynamicSelect, expected ...LifecycleState) {
+	t.Helper()
+
+	for _, want := range expected {
+		select {
+		case got := <-d.StateChanges():
+			if got != want {
+				t.Errorf("Expected the select to become %s, became %s", want, got)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Expected the select to become %s", want)
+		}
+	}
+}
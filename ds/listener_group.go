@@ -0,0 +1,161 @@
+package ds
+
+import (
+	"reflect"
+)
+
+// lazyGroupSize is the most idle entries parked on one listener goroutine when listeners are lazy
+// and no group size is given.
+const lazyGroupSize = 1024
+
+// startListenerGroups splits entries, occupying the indices from first onward, into groups
+// and starts a listener for each group.
+func (d *DynamicSelect) startListenerGroups(first int, entries []ChannelEntry) {
+	size := d.groupSize
+	if d.lazyListeners && size <= 1 {
+		size = lazyGroupSize
+	}
+
+	indices := []int{}
+	group := []ChannelEntry{}
+
+	for k, entry := range entries {
+		d.listenerWG.Add(1)
+
+		// Typed, batched, rate limited, reopened and spilled entries can't share a select.
+		if entry.typed != nil || entry.batch != nil || entry.RateLimit > 0 || entry.Reopen != nil || entry.Spill != nil {
+			go d.startListener(first+k, entry)
+			continue
+		}
+
+		indices = append(indices, first+k)
+		group = append(group, entry)
+
+		if len(group) == size {
+			go d.startListenerGroup(indices, group)
+			indices, group = []int{}, []ChannelEntry{}
+		}
+	}
+
+	if len(group) > 0 {
+		go d.startListenerGroup(indices, group)
+	}
+}
+
+// startListenerGroup behaves as startListener for each of the entries at once.
+// Case 0 of the select is the done channel, case k+1 is entries[k] and case n+k+1 its stop channel,
+// for n entries.
+// With lazy listeners, an entry is handed off to a listener of its own on its first message.
+func (d *DynamicSelect) startListenerGroup(indices []int, entries []ChannelEntry) {
+	d.goroutines.Add(1)
+	defer d.goroutines.Add(-1)
+	d.checkGoroutines()
+
+	n := len(entries)
+	cases := make([]reflect.SelectCase, 2*n+1)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(d.done)}
+
+	for k := range entries {
+		d.listening(indices[k], entries[k])
+		entries[k].IsClosed = false
+		cases[k+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(entries[k].Channel)}
+		cases[n+k+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(entries[k].stop)}
+	}
+
+	live := len(entries)
+
+	// drop stops listening to entries[k], handed off or finished.
+	drop := func(k int) {
+		cases[k+1].Chan, cases[n+k+1].Chan = reflect.Value{}, reflect.Value{}
+		live--
+	}
+
+	// Clean up whatever is still open.
+	defer func() {
+		panicked := false
+		if r := recover(); r != nil {
+			d.logf(LogWarn, "Recovered but exiting in DynamicSelect listener group, error: %v\n", r)
+			panicked = true
+		}
+
+		for k := range entries {
+			if cases[k+1].Chan.IsValid() {
+				d.finishListener(indices[k], entries[k], panicked)
+			}
+		}
+	}()
+
+	for live > 0 {
+		if !d.IsAlive() {
+			return
+		}
+
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return
+		}
+
+		// The entry was killed alone.
+		if chosen > n {
+			k := chosen - n - 1
+			drop(k)
+			d.finishListener(indices[k], entries[k], false)
+			continue
+		}
+
+		k := chosen - 1
+		e := entries[k]
+
+		if !ok {
+			// An invalid Chan drops the case from future selects.
+			drop(k)
+
+			e.IsClosed = true
+			d.finishListener(indices[k], e, false)
+			continue
+		}
+
+		x := v.Interface()
+		if d.lazyListeners {
+			drop(k)
+
+			// The new listener takes over the entry's share of listenerWG.
+			go d.promoteListener(indices[k], e, x)
+			continue
+		}
+
+		if e.Coalesce != nil && cases[chosen].Chan.Len() > 0 {
+			c := cases[chosen].Chan
+			var closed bool
+			x, closed = e.coalesceWaiting(x, c.Len(), func() (interface{}, bool, bool) {
+				y, ok := c.TryRecv()
+				if !y.IsValid() {
+					return nil, false, false
+				}
+				if !ok {
+					return nil, true, true
+				}
+				return y.Interface(), true, false
+			})
+
+			if closed {
+				d.route(indices[k], e, x)
+				drop(k)
+
+				e.IsClosed = true
+				d.finishListener(indices[k], e, false)
+				continue
+			}
+		}
+
+		d.route(indices[k], e, x)
+	}
+}
+
+// promoteListener routes the first message heard for an idle entry, then listens to it alone.
+// The message is routed by the new listener, counted and recovered from as any other.
+func (d *DynamicSelect) promoteListener(i int, e ChannelEntry, x interface{}) {
+	// The group started listening to the entry already.
+	e.OnStart, e.started, e.Heartbeat = nil, nil, 0
+	d.runListener(i, e, func() { d.route(i, e, x) })
+}
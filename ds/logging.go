@@ -0,0 +1,54 @@
+package ds
+
+import "log"
+
+// Logger receives the messages a DynamicSelect logs, a *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// LogLevel sets how much a DynamicSelect logs, see WithLogLevel.
+type LogLevel int
+
+const (
+	// LogSilent logs nothing.
+	LogSilent LogLevel = iota
+
+	// LogError logs recovered panics in the main loop and shut downs forced by WithShutdownTimeout.
+	LogError
+
+	// LogWarn adds listeners exiting on a recovered panic and goroutine watch reports, the default.
+	LogWarn
+
+	// LogDebug adds every Event, see Events, such as entries loading and closing.
+	LogDebug
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogSilent:
+		return "silent"
+	case LogError:
+		return "error"
+	case LogWarn:
+		return "warn"
+	case LogDebug:
+		return "debug"
+	default:
+		return "unknown"
+	}
+}
+
+// logf logs a message of the given level, if the select logs at least as much.
+func (d *DynamicSelect) logf(level LogLevel, format string, v ...interface{}) {
+	if level > d.logLevel || level == LogSilent {
+		return
+	}
+
+	logger := d.logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	logger.Printf(format, v...)
+}
@@ -0,0 +1,65 @@
+package ds
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) logged() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
+
+func TestLogLevel(t *testing.T) {
+	run := func(level LogLevel) []string {
+		logger := &recordingLogger{}
+		panicky := make(chan interface{})
+		logged := NewDynamicSelect(func() {}, []ChannelEntry{{
+			Channel: panicky,
+			Handler: HandlerEntry{Func: func(i interface{}) { panic(i) }, Blocking: true},
+		}}, WithLogger(logger), WithLogLevel(level))
+
+		loggedReady := make(chan interface{})
+		go logged.Forever(loggedReady)
+		<-loggedReady
+
+		panicky <- "boom"
+		logged.Wait()
+		return logger.logged()
+	}
+
+	if lines := run(LogSilent); len(lines) != 0 {
+		t.Errorf("Expected a silent select to log nothing, found %q", lines)
+	}
+
+	lines := run(LogError)
+	if len(lines) == 0 || !strings.Contains(lines[0], "boom") {
+		t.Errorf("Expected the panic to be logged, found %q", lines)
+	}
+
+	var closed bool
+	for _, line := range run(LogDebug) {
+		closed = closed || strings.Contains(line, EventEntryClosed.String())
+	}
+	if !closed {
+		t.Errorf("Expected events to be logged at debug level.")
+	}
+
+	if LogLevel(-1).String() != "unknown" || LogWarn.String() != "warn" {
+		t.Errorf("Unexpected level names")
+	}
+}
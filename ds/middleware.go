@@ -0,0 +1,31 @@
+package ds
+
+// HandlerFunc is the signature of HandlerEntry.Func.
+type HandlerFunc func(i interface{})
+
+// Middleware wraps a handler with cross-cutting behavior: logging, metrics, recovery, tracing.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Use wraps the handler of every entry, loaded now or later, in the given middleware.
+// The first middleware used is the outermost. Use must be called before the select runs.
+// Blocking entries built by Typed skip middleware, their messages never become an interface.
+func (d *DynamicSelect) Use(mw ...Middleware) {
+	d.middleware = append(d.middleware, mw...)
+
+	d.loadMu.Lock()
+	for i, e := range d.channels {
+		d.channels[i].handler = d.chain(e.Handler.Func)
+		e.bound.Store(&binding{Handler: e.Handler, handler: d.channels[i].handler})
+	}
+	d.loadMu.Unlock()
+}
+
+// chain wraps f in the select's middleware.
+func (d *DynamicSelect) chain(f func(i interface{})) HandlerFunc {
+	h := HandlerFunc(f)
+	for i := len(d.middleware) - 1; i >= 0; i-- {
+		h = d.middleware[i](h)
+	}
+
+	return h
+}
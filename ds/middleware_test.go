@@ -0,0 +1,53 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	calls := make(chan string, 8)
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(i interface{}) {
+				calls <- name
+				next(i)
+			}
+		}
+	}
+
+	blocking := make(chan interface{})
+	nonBlocking := make(chan interface{})
+	loaded := make(chan interface{})
+	handler := HandlerEntry{Func: func(i interface{}) { calls <- "handler" }}
+
+	wrapped := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Channel: blocking, Handler: HandlerEntry{Func: handler.Func, Blocking: true}},
+		{Channel: nonBlocking, Handler: handler},
+	})
+	wrapped.Use(record("outer"), record("inner"))
+
+	wrappedReady := make(chan interface{})
+	go wrapped.Forever(wrappedReady)
+	<-wrappedReady
+	defer wrapped.Kill()
+
+	if _, err := wrapped.Load([]ChannelEntry{{Channel: loaded, Handler: handler}}); err != nil {
+		t.Fatalf("Could not load: %s", err.Error())
+	}
+
+	for _, c := range []chan interface{}{blocking, nonBlocking, loaded} {
+		c <- unit
+
+		for _, expected := range []string{"outer", "inner", "handler"} {
+			select {
+			case call := <-calls:
+				if call != expected {
+					t.Errorf("Expected %s to be called, found %s", expected, call)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Expected %s to be called.", expected)
+			}
+		}
+	}
+}
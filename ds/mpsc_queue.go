@@ -0,0 +1,79 @@
+package ds
+
+import (
+	"sync/atomic"
+)
+
+// mpscQueue is an intrusive, lock-free, unbounded queue of wrappers with many producers
+// and a single consumer, after Dmitry Vyukov's non-intrusive MPSC node queue.
+// Producers never wait on each other or the consumer, which is the point of using it for priority traffic.
+type mpscQueue struct {
+	// head is the most recently pushed wrapper, swapped by producers.
+	head atomic.Pointer[dsWrapper]
+
+	// tail is the next wrapper to pop, only touched by the consumer.
+	tail *dsWrapper
+
+	// stub keeps the queue non-empty so head and tail are never nil.
+	stub dsWrapper
+
+	// length approximates the wrappers queued, for reporting only.
+	length atomic.Int64
+}
+
+func newMPSCQueue() *mpscQueue {
+	q := &mpscQueue{}
+	q.head.Store(&q.stub)
+	q.tail = &q.stub
+	return q
+}
+
+// push adds w to the queue, safe for concurrent producers.
+func (q *mpscQueue) push(w *dsWrapper) {
+	if w != &q.stub {
+		q.length.Add(1)
+	}
+
+	w.next.Store(nil)
+	prev := q.head.Swap(w)
+	prev.next.Store(w)
+}
+
+// pop removes the oldest wrapper, returning nil when the queue is empty or a producer
+// is midway through a push. Only the consumer may call pop.
+func (q *mpscQueue) pop() *dsWrapper {
+	tail := q.tail
+	next := tail.next.Load()
+
+	if tail == &q.stub {
+		if next == nil {
+			return nil
+		}
+
+		q.tail = next
+		tail = next
+		next = next.next.Load()
+	}
+
+	if next != nil {
+		q.tail = next
+		q.length.Add(-1)
+		return tail
+	}
+
+	if tail != q.head.Load() {
+		// A producer has swapped head but not yet linked its wrapper.
+		return nil
+	}
+
+	// tail is the last wrapper, put the stub behind it so it can be handed out.
+	q.push(&q.stub)
+	next = tail.next.Load()
+	if next != nil {
+		q.tail = next
+		q.length.Add(-1)
+		return tail
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package ds
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMPSCQueue(t *testing.T) {
+	q := newMPSCQueue()
+	if q.pop() != nil {
+		t.Fatalf("Empty queue returned a wrapper.")
+	}
+
+	const producers, perProducer = 8, 1000
+
+	var wg sync.WaitGroup
+	wg.Add(producers)
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			defer wg.Done()
+			for n := 0; n < perProducer; n++ {
+				q.push(&dsWrapper{Index: p, Target: n})
+			}
+		}(p)
+	}
+
+	// Each producer's messages must come out in the order it pushed them.
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+
+	popped := 0
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	for popped < producers*perProducer {
+		w := q.pop()
+		if w == nil {
+			select {
+			case <-finished:
+			default:
+			}
+			continue
+		}
+
+		n := w.Target.(int)
+		if n != last[w.Index]+1 {
+			t.Fatalf("Producer %d's message %d popped after %d", w.Index, n, last[w.Index])
+		}
+
+		last[w.Index] = n
+		popped++
+	}
+
+	if q.pop() != nil {
+		t.Errorf("Drained queue returned a wrapper.")
+	}
+}
@@ -0,0 +1,278 @@
+package ds
+
+import (
+	"time"
+)
+
+// Option configures optional behavior of a DynamicSelect at construction.
+type Option func(*DynamicSelect)
+
+// WithBatchSize lets the main loop handle up to n pending aggregator messages per pass
+// of the state machine before returning to check for kill commands and priority messages.
+// The default of 1 re-runs the tiered select for every message.
+func WithBatchSize(n int) Option {
+	return func(d *DynamicSelect) {
+		if n < 1 {
+			n = 1
+		}
+		d.batchSize = n
+	}
+}
+
+// WithShards splits the aggregators into n shards, each consumed by its own loop, so Blocking
+// handlers of independent entries progress in parallel. Entries are pinned to shards by index,
+// or by ChannelEntry.Affinity, preserving the order of each entry's messages while removing the
+// single channel every listener contends on.
+// An n below 1 uses a shard per available CPU, see WithDispatcher.
+// Note that Blocking handlers of entries in different shards may run concurrently, which is why
+// a single shard remains the default.
+func WithShards(n int) Option {
+	return func(d *DynamicSelect) {
+		if n < 1 {
+			n = availableCPUs()
+		}
+		d.shardCount = n
+	}
+}
+
+// WithDispatcher bounds the goroutines running non-Blocking handlers to workers, queueing up to
+// queue messages before the overflow policy applies. A workers or queue below 1 keeps the default.
+// Without this option a dispatcher that blocks on overflow is sized by the CPUs available:
+// GOMAXPROCS, or the cgroup CPU quota if lower, with 8 workers per CPU and 16 queued messages per worker.
+func WithDispatcher(workers, queue int, policy OverflowPolicy) Option {
+	return func(d *DynamicSelect) {
+		if workers > 0 {
+			d.dispatchWorkers = workers
+		}
+		if queue > 0 {
+			d.dispatchQueue = queue
+		}
+		d.dispatchPolicy = policy
+		d.dispatchUnbounded = false
+	}
+}
+
+// WithUnboundedDispatch runs every non-Blocking handler call in a goroutine of its own.
+// A burst of messages will spawn a goroutine per message, so only opt in when bursts are known to be small.
+func WithUnboundedDispatch() Option {
+	return func(d *DynamicSelect) {
+		d.dispatchUnbounded = true
+	}
+}
+
+// WithBusyPoll trades CPU for dispatch latency. The main loop spins for up to spin, yielding
+// with runtime.Gosched, before parking on its select, and listeners of Priority entries call
+// their Blocking handlers directly rather than passing messages through the main loop.
+// Blocking handlers remain serialized, but a Priority message is no longer guaranteed to be
+// handled ahead of Blocking messages already heard.
+func WithBusyPoll(spin time.Duration) Option {
+	return func(d *DynamicSelect) {
+		d.busyPoll = spin
+	}
+}
+
+// Buffers sizes the internal channels of a DynamicSelect. Each is unbuffered by default,
+// which keeps listeners in lockstep with the main loop at the cost of two context switches per message.
+// Buffering relaxes the tiered ordering: a message may wait in a buffer while one heard later
+// on a higher tier is handled first, and a Load returns before the main loop has seen it.
+type Buffers struct {
+	Aggregator         int
+	PriorityAggregator int
+	OnClose            int
+	Load               int
+}
+
+// WithBuffers sizes the internal channels, see Buffers. Shards are sized alike.
+func WithBuffers(b Buffers) Option {
+	return func(d *DynamicSelect) {
+		d.buffers = b
+	}
+}
+
+// WithLockFreePriority passes Priority messages to the main loop through a lock-free queue
+// rather than a channel, so high frequency priority producers never contend with bulk traffic.
+// The queue is unbounded: listeners of Priority entries no longer wait on the main loop.
+func WithLockFreePriority() Option {
+	return func(d *DynamicSelect) {
+		d.lockFreePriority = true
+	}
+}
+
+// WithListenerGroups multiplexes up to k entries onto each listener goroutine with reflect.Select,
+// rather than running a goroutine per entry. Mostly idle entries then cost a select case instead
+// of a goroutine stack, while busy entries pay for reflection on every message.
+// Entries built by Typed or Batched, or with a RateLimit, a Reopen or a Spill, always get a listener of their own.
+func WithListenerGroups(k int) Option {
+	return func(d *DynamicSelect) {
+		d.groupSize = k
+	}
+}
+
+// WithLatencyStats records, per entry, how long messages wait between being heard and handled
+// and how long handlers take, see Stats. Measuring costs two clock reads per message.
+func WithLatencyStats() Option {
+	return func(d *DynamicSelect) {
+		d.latencyStats = true
+	}
+}
+
+// WithLazyListeners parks entries in shared listener groups, see WithListenerGroups, until their
+// first message, at which point the entry gets a listener goroutine of its own. Selects fanning in
+// from very many mostly idle channels then only pay a goroutine per active entry.
+// Groups hold up to 1024 entries unless WithListenerGroups says otherwise.
+func WithLazyListeners() Option {
+	return func(d *DynamicSelect) {
+		d.lazyListeners = true
+	}
+}
+
+// WithCloseHook calls f from the main loop each time an entry's listener finishes while the select
+// runs, because its channel closed or it was killed by name. Entries without a Name are reported
+// with an empty name.
+func WithCloseHook(f func(index int, name string)) Option {
+	return func(d *DynamicSelect) {
+		d.closeHook = f
+	}
+}
+
+// WithErrorSink passes every error returned by a FuncErr handler to sink, which is called on the
+// goroutine that ran the handler. A Blocking handler's sink call holds up the main loop, so sink
+// should be quick; to forward errors to a channel, send without blocking.
+// Without a sink, handler errors are discarded.
+func WithErrorSink(sink func(err *HandlerError)) Option {
+	return func(d *DynamicSelect) {
+		d.errorSink = sink
+	}
+}
+
+// WithPriorityLevels gives Blocking handlers n priority levels, 0 through n-1, see HandlerEntry.Level.
+// Each level above 1 adds an aggregator per shard, checked before those below it.
+// Handlers above the top level share it. n below 2 keeps the default of 2 levels.
+func WithPriorityLevels(n int) Option {
+	return func(d *DynamicSelect) {
+		if n > 2 {
+			d.priorityLevels = n
+		}
+	}
+}
+
+// WithShutdownTimeout bounds how long a killed select waits for its listeners and shard loops,
+// which a stuck Blocking handler or OnClose can otherwise hold up forever. Once timeout passes,
+// the shut down completes without them and Err reports the entries that were stuck.
+// Such a select can't be Reset.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(d *DynamicSelect) {
+		d.shutdownTimeout = timeout
+	}
+}
+
+// WithGoroutineWatch calls report with an error wrapping ErrTooManyGoroutines each time
+// GoroutineCount climbs above limit, and one wrapping ErrGoroutineLeak if any goroutine of a run
+// is still running a second after the select has shut down. A limit below 1 only watches for leaks.
+// A nil report logs the errors instead.
+func WithGoroutineWatch(limit int, report func(err error)) Option {
+	return func(d *DynamicSelect) {
+		if report == nil {
+			report = func(err error) {
+				d.logf(LogWarn, "DynamicSelect: %v\n", err)
+			}
+		}
+		d.goroutineLimit = limit
+		d.reportGoroutines = report
+	}
+}
+
+// WithDrainGrace bounds how long a killed select waits, once its listeners have exited, on calls
+// to Kill, Load or Broadcast that were already under way before closing its internal channels.
+// Such calls normally land at once, so the wait is brief, the default bound is a second.
+func WithDrainGrace(grace time.Duration) Option {
+	return func(d *DynamicSelect) {
+		d.drainGrace = grace
+	}
+}
+
+// WithDeterministic runs the select so that, with its inputs given in a fixed order, its handlers
+// run in a fixed order too, as under testing/synctest: one shard loop, no busy polling, which would
+// spin forever on a bubble's still clock, and a single dispatcher worker, so non-Blocking handlers run
+// one at a time in the order heard. It overrides WithShards, WithBusyPoll and WithDispatcher.
+// Leave out WithGoroutineWatch under synctest, its leak check outlives the run by up to a second.
+func WithDeterministic() Option {
+	return func(d *DynamicSelect) {
+		d.deterministic = true
+	}
+}
+
+// WithChaos injects the faults described by c into every entry's handling: delayed handler calls,
+// dropped messages and channels treated as closed early, so tests can check that handlers cope.
+// It is meant for tests, never production.
+func WithChaos(c Chaos) Option {
+	return func(d *DynamicSelect) {
+		d.chaos = newChaos(c)
+	}
+}
+
+// WithClock times the select by c rather than the wall clock, see Clock. A ManualClock lets
+// tests advance ticker entries, heartbeats, rate limits and timeouts without sleeping.
+func WithClock(c Clock) Option {
+	return func(d *DynamicSelect) {
+		if c != nil {
+			d.clock = c
+		}
+	}
+}
+
+// WithLogger sends the select's log messages to logger rather than the standard logger.
+func WithLogger(logger Logger) Option {
+	return func(d *DynamicSelect) {
+		d.logger = logger
+	}
+}
+
+// WithLogLevel sets how much the select logs: LogSilent for none, up to LogDebug for its
+// lifecycle too. The default, LogWarn, logs recovered panics and forced shut downs.
+func WithLogLevel(level LogLevel) Option {
+	return func(d *DynamicSelect) {
+		d.logLevel = level
+	}
+}
+
+// WithKillReasonAction calls f with the select's KillReason once it is killed, after the shutdown
+// hooks, see OnShutdown. It tells an operator's Kill apart from a cancelled context or a panic.
+func WithKillReasonAction(f func(reason error)) Option {
+	return func(d *DynamicSelect) {
+		d.onKillReason = f
+	}
+}
+
+// WithReverseClose calls the OnClose handlers of the entries still open when the select is killed
+// in reverse load order, as deferred calls are, once every listener has exited. Entries loaded
+// later, which may depend on those loaded before them, are then torn down first. Without it, close
+// notifications are handled in whatever order the listeners happen to exit.
+// Entries closing while the select runs are unaffected.
+func WithReverseClose() Option {
+	return func(d *DynamicSelect) {
+		d.reverseClose = true
+	}
+}
+
+// WithSlowHandlerWatchdog calls f whenever a Blocking handler has run for longer than threshold,
+// once per message, from a goroutine of its own while the handler still holds up the main loop.
+// With stack set, the report carries the stacks of every goroutine, the handler's among them,
+// which costs a stop of the world each time.
+func WithSlowHandlerWatchdog(threshold time.Duration, stack bool, f func(slow SlowHandler)) Option {
+	return func(d *DynamicSelect) {
+		d.watchdog = &watchdog{threshold: threshold, stack: stack, f: f}
+	}
+}
+
+// WithAggregatorOverflow sets what a listener does when the aggregator it forwards to is full:
+// OverflowBlock, the default, waits for the main loop, OverflowDrop discards the new message,
+// OverflowDropOldest discards the oldest waiting and OverflowError discards the new message,
+// reporting ErrAggregatorFull to the error sink. A slow consumer then no longer freezes every listener.
+// Discarded messages are counted in Stats. The aggregators are unbuffered unless sized by WithBuffers,
+// with no buffer a message is only kept if the main loop is waiting for it.
+func WithAggregatorOverflow(policy OverflowPolicy) Option {
+	return func(d *DynamicSelect) {
+		d.aggregatorPolicy = policy
+	}
+}
@@ -0,0 +1,54 @@
+package ds
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WritePrometheus writes the select's Stats in the Prometheus text exposition format,
+// with every metric name prefixed by namespace and labelled by entry.
+// It lets a /metrics handler expose a select without this package depending on a client library.
+func (d *DynamicSelect) WritePrometheus(w io.Writer, namespace string) error {
+	bw := bufio.NewWriter(w)
+	stats := d.Stats()
+
+	fmt.Fprintf(bw, "# HELP %s_handled_total Handler calls that have returned.\n", namespace)
+	fmt.Fprintf(bw, "# TYPE %s_handled_total counter\n", namespace)
+	for _, s := range stats {
+		fmt.Fprintf(bw, "%s_handled_total{entry=%q} %d\n", namespace, strconv.Itoa(s.Index), s.Handled)
+	}
+
+	if d.latencyStats {
+		writePrometheusHistogram(bw, namespace+"_queue_latency_seconds", "Time from a message being heard to its handler starting.", stats, func(s EntryStats) HistogramSnapshot {
+			return s.QueueLatency
+		})
+		writePrometheusHistogram(bw, namespace+"_handler_latency_seconds", "Time spent in handlers.", stats, func(s EntryStats) HistogramSnapshot {
+			return s.HandlerLatency
+		})
+	}
+
+	return bw.Flush()
+}
+
+func writePrometheusHistogram(w io.Writer, name, help string, stats []EntryStats, pick func(EntryStats) HistogramSnapshot) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+
+	for _, s := range stats {
+		h := pick(s)
+		entry := strconv.Itoa(s.Index)
+
+		// Prometheus buckets are cumulative.
+		cumulative := uint64(0)
+		for _, b := range h.Buckets {
+			cumulative += b.Count
+			fmt.Fprintf(w, "%s_bucket{entry=%q,le=%q} %d\n", name, entry, strconv.FormatFloat(b.UpperBound.Seconds(), 'g', -1, 64), cumulative)
+		}
+
+		fmt.Fprintf(w, "%s_bucket{entry=%q,le=\"+Inf\"} %d\n", name, entry, h.Count)
+		fmt.Fprintf(w, "%s_sum{entry=%q} %s\n", name, entry, strconv.FormatFloat(h.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count{entry=%q} %d\n", name, entry, h.Count)
+	}
+}
@@ -0,0 +1,78 @@
+package ds
+
+import (
+	"fmt"
+)
+
+// ReplaceHandler swaps the handler of the entry at index for h while the select runs, without
+// rebuilding the select. Messages heard from then on go to h, routed by its Blocking, Priority and
+// Level; those already on their way still reach the old handler. The handler of an entry built by
+// Batched is passed a []interface{}, as BatchHandlerEntry.Func is. Entries built by Typed can't be
+// swapped, their handler is fixed to their type. Like Load, it only refuses what the select can't
+// run; the advice Validate gives about h is left to the caller.
+func (d *DynamicSelect) ReplaceHandler(index int, h HandlerEntry) error {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	return d.replaceHandler(index, h)
+}
+
+// replaceHandler is ReplaceHandler. The caller must hold loadMu.
+func (d *DynamicSelect) replaceHandler(index int, h HandlerEntry) error {
+	return d.rebind(index, func(e ChannelEntry) (ChannelEntry, error) {
+		if e.typed != nil {
+			return e, fmt.Errorf("%w: entry %d", ErrTypedHandler, index)
+		}
+
+		e.Handler = h
+		if err := checkLoadable([]ChannelEntry{e}); err != nil {
+			return e, err
+		}
+
+		e.Handler, e.handler = d.bind(index, e, h)
+		return e, nil
+	})
+}
+
+// Routing is how messages reach an entry's handler, see HandlerEntry for the meaning of each field.
+type Routing struct {
+	Blocking bool
+	Priority bool
+	Level    int
+}
+
+// Reconfigure changes how messages reach the handler of the entry at index while the select runs.
+// The listener routes the next message it hears by r; those already on their way arrive as
+// they were routed. As with ReplaceHandler, routes Validate would advise against are accepted.
+func (d *DynamicSelect) Reconfigure(index int, r Routing) error {
+	d.loadMu.Lock()
+	defer d.loadMu.Unlock()
+
+	return d.reconfigure(index, r)
+}
+
+// reconfigure is Reconfigure. The caller must hold loadMu.
+func (d *DynamicSelect) reconfigure(index int, r Routing) error {
+	return d.rebind(index, func(e ChannelEntry) (ChannelEntry, error) {
+		e.Handler.Blocking, e.Handler.Priority, e.Handler.Level = r.Blocking, r.Priority, r.Level
+		return e, checkLoadable([]ChannelEntry{e})
+	})
+}
+
+// rebind updates the handler bound to the entry at index, leaving it be if update fails.
+// The caller must hold loadMu.
+func (d *DynamicSelect) rebind(index int, update func(e ChannelEntry) (ChannelEntry, error)) error {
+	if index < 0 || index >= len(d.channels) {
+		return fmt.Errorf("%w: no entry at index %d", ErrNoEntry, index)
+	}
+
+	e, err := update(d.channels[index])
+	if err != nil {
+		return err
+	}
+
+	e.bound.Store(&binding{Handler: e.Handler, handler: e.handler})
+	d.channels[index] = e
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package ds
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReplaceHandler(t *testing.T) {
+	heard := make(chan string, 4)
+	c := make(chan interface{})
+
+	replacing := NewDynamicSelect(func() {}, []ChannelEntry{
+		{Channel: c, Handler: HandlerEntry{Func: func(i interface{}) { heard <- "old" }}},
+	})
+	replacingReady := make(chan interface{})
+	go replacing.Forever(replacingReady)
+	<-replacingReady
+	defer replacing.Kill()
+
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-heard:
+			if got != want {
+				t.Errorf("Expected the %s handler, heard the %s one", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("No handler heard the message.")
+		}
+	}
+
+	c <- 1
+	expect("old")
+
+	// Swapping from the dispatcher to the main loop is picked up by the running listener.
+	err := replacing.ReplaceHandler(0, HandlerEntry{Func: func(i interface{}) { heard <- "new" }, Blocking: true})
+	if err != nil {
+		t.Fatalf("Failed to replace the handler: %v", err)
+	}
+
+	c <- 2
+	expect("new")
+
+	if !replacing.Channels()[0].Handler.Blocking {
+		t.Errorf("Expected Channels to report the new handler")
+	}
+
+	if err := replacing.ReplaceHandler(1, HandlerEntry{Func: noopHandler}); !errors.Is(err, ErrNoEntry) {
+		t.Errorf("Expected ErrNoEntry replacing the handler of a missing entry, got: %v", err)
+	}
+
+	// Priority without Blocking is only advice, the select runs it as a plain handler, as Load would.
+	err = replacing.ReplaceHandler(0, HandlerEntry{Func: func(i interface{}) { heard <- "advised" }, Priority: true})
+	if err != nil {
+		t.Fatalf("Failed to replace the handler with one Validate advises against: %v", err)
+	}
+
+	c <- 3
+	expect("advised")
+}
+
+func TestReconfigure(t *testing.T) {
+	heard := make(chan interface{})
+	c := make(chan int)
+
+	reconfiguring := NewDynamicSelect(func() {}, []ChannelEntry{
+		Typed(c, TypedHandlerEntry[int]{Func: func(x int) { heard <- x }}, OnCloseEntry{}),
+	})
+
+	// Blocking typed entries skip middleware, so it tells the two routes apart.
+	var dispatched atomic.Int32
+	reconfiguring.Use(func(next HandlerFunc) HandlerFunc {
+		return func(i interface{}) {
+			dispatched.Add(1)
+			next(i)
+		}
+	})
+
+	reconfiguringReady := make(chan interface{})
+	go reconfiguring.Forever(reconfiguringReady)
+	<-reconfiguringReady
+	defer reconfiguring.Kill()
+
+	routes := []Routing{{}, {Blocking: true, Priority: true}, {Blocking: false}}
+	expected := []int32{1, 1, 2}
+	for k, r := range routes {
+		if k == 0 {
+			if err := reconfiguring.ReplaceHandler(0, HandlerEntry{Func: noopHandler}); !errors.Is(err, ErrTypedHandler) {
+				t.Errorf("Expected ErrTypedHandler replacing the handler of a typed entry, got: %v", err)
+			}
+		}
+		if k > 0 {
+			if err := reconfiguring.Reconfigure(0, r); err != nil {
+				t.Fatalf("Failed to reconfigure: %v", err)
+			}
+		}
+
+		c <- k
+		select {
+		case <-heard:
+		case <-time.After(time.Second):
+			t.Fatalf("The handler did not hear message %d.", k)
+		}
+
+		if n := dispatched.Load(); n != expected[k] {
+			t.Errorf("Expected %d dispatched messages after routing by %+v, got %d", expected[k], r, n)
+		}
+	}
+
+	// A negative Level is only advice, the select handles it in the regular tier.
+	if err := reconfiguring.Reconfigure(0, Routing{Level: -1, Blocking: true}); err != nil {
+		t.Fatalf("Failed to reconfigure to a route Validate advises against: %v", err)
+	}
+
+	c <- len(routes)
+	select {
+	case <-heard:
+	case <-time.After(time.Second):
+		t.Fatalf("The handler did not hear a message routed to a negative Level.")
+	}
+}
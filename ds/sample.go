@@ -0,0 +1,28 @@
+package ds
+
+import "math/rand/v2"
+
+// skip reports whether sampling leaves a message heard for the entry unhandled, counting it
+// and returning its credit if so.
+func (e ChannelEntry) skip() bool {
+	if e.stats == nil {
+		return false
+	}
+
+	switch {
+	case e.SampleEvery > 1:
+		if (e.stats.sampled.Add(1)-1)%uint64(e.SampleEvery) == 0 {
+			return false
+		}
+	case e.SampleRate > 0 && e.SampleRate < 1:
+		if rand.Float64() < e.SampleRate {
+			return false
+		}
+	default:
+		return false
+	}
+
+	e.stats.skipped.Add(1)
+	e.Credit.release()
+	return true
+}
@@ -0,0 +1,90 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampleEvery(t *testing.T) {
+	heard := make(chan interface{}, 10)
+	c := make(chan interface{})
+
+	sampling := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel:     c,
+		Handler:     HandlerEntry{Func: func(i interface{}) { heard <- i }, Blocking: true},
+		SampleEvery: 3,
+	}})
+	samplingReady := make(chan interface{})
+	go sampling.Forever(samplingReady)
+	<-samplingReady
+	defer sampling.Kill()
+
+	for i := 0; i < 7; i++ {
+		c <- i
+	}
+
+	for _, want := range []int{0, 3, 6} {
+		select {
+		case got := <-heard:
+			if got != want {
+				t.Errorf("Expected to handle %d, handled %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected to handle %d", want)
+		}
+	}
+
+	time.Sleep(time.Second / 20)
+	if stats := sampling.Stats()[0]; stats.Skipped != 4 || stats.Handled != 3 {
+		t.Errorf("Expected 3 handled and 4 skipped, found %d and %d", stats.Handled, stats.Skipped)
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	c := make(chan interface{})
+
+	sampling := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel:    c,
+		Handler:    HandlerEntry{Func: func(i interface{}) {}, Blocking: true},
+		SampleRate: 0.25,
+	}})
+	samplingReady := make(chan interface{})
+	go sampling.Forever(samplingReady)
+	<-samplingReady
+	defer sampling.Kill()
+
+	const sent = 4000
+	for i := 0; i < sent; i++ {
+		c <- i
+	}
+
+	time.Sleep(time.Second / 20)
+	stats := sampling.Stats()[0]
+	if stats.Handled+stats.Skipped != sent {
+		t.Errorf("Expected every message to be handled or skipped, found %d and %d of %d", stats.Handled, stats.Skipped, sent)
+	}
+
+	// A quarter, give or take.
+	if stats.Handled < sent/8 || stats.Handled > sent/2 {
+		t.Errorf("Expected about a quarter of %d messages handled, found %d", sent, stats.Handled)
+	}
+}
+
+func TestSampleValidation(t *testing.T) {
+	h := HandlerEntry{Func: func(i interface{}) {}}
+	c := make(chan interface{})
+
+	for _, e := range []ChannelEntry{
+		{Channel: c, Handler: h, SampleEvery: -1},
+		{Channel: c, Handler: h, SampleRate: 1.5},
+		{Channel: c, Handler: h, SampleEvery: 2, SampleRate: 0.5},
+	} {
+		if err := e.Validate(); err == nil {
+			t.Errorf("Expected sampling of %d every or at %v to fail validation", e.SampleEvery, e.SampleRate)
+		}
+	}
+
+	if err := (ChannelEntry{Channel: c, Handler: h, SampleEvery: 2}).Validate(); err != nil {
+		t.Errorf("Expected sampling to pass validation: %v", err)
+	}
+}
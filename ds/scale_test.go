@@ -0,0 +1,157 @@
+package ds
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLazyListeners(t *testing.T) {
+	handled := make(chan interface{}, 10)
+	entries := make([]ChannelEntry, 100)
+	for i := range entries {
+		entries[i] = ChannelEntry{
+			Channel: make(chan interface{}, 1),
+			Handler: HandlerEntry{
+				Func: func(x interface{}) {
+					handled <- x
+				},
+				Blocking: true,
+			},
+			OnClose: OnCloseEntry{
+				Func: func() {},
+			},
+		}
+	}
+
+	lazyReady := make(chan interface{})
+	selectMgr := NewDynamicSelect(func() {}, entries, WithLazyListeners(), WithListenerGroups(50))
+	go selectMgr.Forever(lazyReady)
+	<-lazyReady
+	time.Sleep(time.Second / 100)
+
+	// The main loop and two groups.
+	if n := selectMgr.Footprint().Goroutines; n != 3 {
+		t.Errorf("Expected idle entries to share 2 listeners, found %d goroutines", n)
+	}
+
+	for n := 0; n < 3; n++ {
+		entries[7].Channel <- n
+		if x := <-handled; x != n {
+			t.Errorf("Expected to hear %d from a lazy entry, heard %v", n, x)
+		}
+	}
+
+	if n := selectMgr.Footprint().Goroutines; n != 4 {
+		t.Errorf("Expected an active entry to get its own listener, found %d goroutines", n)
+	}
+
+	close(entries[7].Channel)
+	close(entries[8].Channel)
+	time.Sleep(time.Second / 10)
+
+	chs := selectMgr.Channels()
+	if !chs[7].IsClosed || !chs[8].IsClosed || chs[9].IsClosed {
+		t.Errorf("Lazy entries reported the wrong closed status.")
+	}
+
+	selectMgr.Kill()
+}
+
+func TestLazyListenerPanic(t *testing.T) {
+	entries := []ChannelEntry{{
+		Channel: make(chan interface{}, 1),
+		Handler: HandlerEntry{Func: func(x interface{}) {}, Blocking: true},
+		Transform: func(x interface{}) interface{} {
+			panic(x)
+		},
+	}}
+
+	lazyReady := make(chan interface{})
+	selectMgr := NewDynamicSelect(func() {}, entries, WithLazyListeners())
+	go selectMgr.Forever(lazyReady)
+	<-lazyReady
+	defer selectMgr.Kill()
+
+	// The panic is recovered by the promoted listener, which closes the entry.
+	entries[0].Channel <- "boom"
+	for {
+		select {
+		case ev := <-selectMgr.Events():
+			if ev.Kind != EventHandlerPanicked {
+				continue
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("The promoted listener's panic went unreported.")
+		}
+		break
+	}
+
+	if !selectMgr.IsAlive() {
+		t.Errorf("Expected the select to outlive a listener's panic.")
+	}
+}
+
+// The fan-in benchmarks send each message to the next of n entries in turn.
+// Run them with -benchtime 100000x or more so every entry is heard from, e.g.
+//
+//	go test -run xxx -bench FanIn -benchtime 200000x ./ds
+func BenchmarkFanIn(b *testing.B) {
+	modes := []struct {
+		name string
+		opts []Option
+	}{
+		{"dedicated", nil},
+		{"groups", []Option{WithListenerGroups(256)}},
+		{"lazy", []Option{WithLazyListeners()}},
+		{"lazy-batched", []Option{WithLazyListeners(), WithBatchSize(64), WithBuffers(Buffers{Aggregator: 64})}},
+	}
+
+	for _, n := range []int{10000, 100000} {
+		for _, mode := range modes {
+			b.Run(fmt.Sprintf("%s-%d", mode.name, n), func(b *testing.B) {
+				benchmarkFanIn(b, n, mode.opts)
+			})
+		}
+	}
+}
+
+func benchmarkFanIn(b *testing.B, n int, opts []Option) {
+	handled := make(chan struct{}, 1024)
+	entries := make([]ChannelEntry, n)
+	for i := range entries {
+		entries[i] = ChannelEntry{
+			Channel: make(chan interface{}, 1),
+			Handler: HandlerEntry{
+				Func: func(x interface{}) {
+					handled <- struct{}{}
+				},
+				Blocking: true,
+			},
+			OnClose: OnCloseEntry{
+				Func: func() {},
+			},
+		}
+	}
+
+	benchReady := make(chan interface{})
+	selectMgr := NewDynamicSelect(func() {}, entries, opts...)
+	go selectMgr.Forever(benchReady)
+	<-benchReady
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	go func() {
+		for i := 0; i < b.N; i++ {
+			entries[i%n].Channel <- unit
+		}
+	}()
+
+	for i := 0; i < b.N; i++ {
+		<-handled
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(selectMgr.Footprint().Goroutines), "goroutines")
+	selectMgr.Kill()
+}
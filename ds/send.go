@@ -0,0 +1,74 @@
+package ds
+
+import (
+	"fmt"
+)
+
+// Send passes msg to the entry at index as if it had been sent on the entry's channel, so it takes
+// the same path to the handler, Blocking, Priority and all. It blocks until the entry's listener
+// hears it, or reports an error if the select halts, the entry is closed or msg is of the wrong type
+// for an entry built by Typed. Send suits tests and local producers that shouldn't own the channel.
+func (d *DynamicSelect) Send(index int, msg interface{}) error {
+	d.loadMu.Lock()
+	if index < 0 || index >= len(d.channels) {
+		d.loadMu.Unlock()
+		return fmt.Errorf("%w: no entry at index %d", ErrNoEntry, index)
+	}
+	e := d.channels[index]
+	d.loadMu.Unlock()
+
+	return d.inject(e, fmt.Sprintf("entry %d", index), msg)
+}
+
+// SendNamed is Send for the named entry.
+func (d *DynamicSelect) SendNamed(name string, msg interface{}) error {
+	e, _, ok := d.Entry(name)
+	if !ok {
+		return fmt.Errorf("%w: no entry named %q", ErrNoEntry, name)
+	}
+
+	return d.inject(e, fmt.Sprintf("entry %q", name), msg)
+}
+
+// inject sends msg on the entry's channel, described as entry in errors.
+func (d *DynamicSelect) inject(e ChannelEntry, entry string, msg interface{}) (err error) {
+	if err := d.runningErr(); err != nil {
+		return fmt.Errorf("%w, %s can't be sent to", err, entry)
+	}
+
+	if e.IsClosed {
+		return fmt.Errorf("%w: %s", ErrEntryClosed, entry)
+	}
+
+	// The channel may close as we send.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %s", ErrEntryClosed, entry)
+		}
+	}()
+
+	sent := false
+	if e.typed != nil {
+		sent, err = e.typed.send(msg, d.done, e.stop)
+		if err != nil {
+			return err
+		}
+	} else {
+		select {
+		case e.Channel <- msg:
+			sent = true
+		case <-d.done:
+		case <-e.stop:
+		}
+	}
+
+	if !sent {
+		if err := d.runningErr(); err != nil {
+			return fmt.Errorf("%w, %s can't be sent to", err, entry)
+		}
+
+		return fmt.Errorf("%w: %s is no longer listened to", ErrEntryClosed, entry)
+	}
+
+	return nil
+}
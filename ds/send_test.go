@@ -0,0 +1,72 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSend(t *testing.T) {
+	heard := make(chan interface{}, 2)
+	typedHeard := make(chan int, 1)
+
+	entries := []ChannelEntry{
+		{
+			Name:    "priority",
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{Func: func(i interface{}) { heard <- i }, Blocking: true, Priority: true},
+		},
+		Typed(make(chan int), TypedHandlerEntry[int]{Func: func(x int) { typedHeard <- x }, Blocking: true}, OnCloseEntry{}),
+	}
+
+	sending := NewDynamicSelect(func() {}, entries)
+
+	if sending.Send(0, "early") == nil {
+		t.Errorf("Sent to a select that isn't running.")
+	}
+
+	sendingReady := make(chan interface{})
+	go sending.Forever(sendingReady)
+	<-sendingReady
+
+	if err := sending.Send(0, "by index"); err != nil {
+		t.Errorf("Could not send by index: %s", err.Error())
+	}
+
+	if err := sending.SendNamed("priority", "by name"); err != nil {
+		t.Errorf("Could not send by name: %s", err.Error())
+	}
+
+	if err := sending.Send(1, 7); err != nil {
+		t.Errorf("Could not send to a typed entry: %s", err.Error())
+	}
+
+	if sending.Send(1, "seven") == nil {
+		t.Errorf("Sent a message of the wrong type to a typed entry.")
+	}
+
+	if sending.Send(2, unit) == nil || sending.SendNamed("missing", unit) == nil {
+		t.Errorf("Sent to an entry that doesn't exist.")
+	}
+
+	for _, expected := range []string{"by index", "by name"} {
+		select {
+		case msg := <-heard:
+			if msg != expected {
+				t.Errorf("Expected %s, heard %v", expected, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %s to be heard.", expected)
+		}
+	}
+
+	if <-typedHeard != 7 {
+		t.Errorf("Typed entry did not hear what was sent.")
+	}
+
+	sending.Kill()
+	sending.Wait()
+
+	if sending.Send(0, "late") == nil {
+		t.Errorf("Sent to a killed select.")
+	}
+}
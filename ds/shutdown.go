@@ -0,0 +1,138 @@
+package ds
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Component is one stage of a pipeline, stopped as part of a ShutdownPlan.
+type Component struct {
+	Name string
+
+	// DependsOn names the components this one receives from. They are stopped before it,
+	// so nothing is sent to it once it stops.
+	DependsOn []string
+
+	// Stop must not return until the component will send nothing more downstream.
+	// It is a good place to close the component's output channels.
+	Stop func()
+}
+
+// SelectComponent describes a DynamicSelect as a Component. Stopping it kills the select
+// and waits for its handlers to return, then runs closeOutputs, if set.
+func SelectComponent(name string, d *DynamicSelect, closeOutputs func(), dependsOn ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Stop: func() {
+			d.Kill()
+			d.Wait()
+			if closeOutputs != nil {
+				closeOutputs()
+			}
+		},
+	}
+}
+
+// ShutdownPlan stops a set of components in dependency order: producers before transformers
+// before sinks. Killing the selects of a layered pipeline in no particular order lets a stage
+// outlive its consumer, or see its inputs closed, mid send.
+type ShutdownPlan struct {
+	generations [][]Component
+}
+
+// NewShutdownPlan orders the components into generations, each depending only on earlier ones.
+// Unknown dependencies, duplicate names and cycles are errors.
+func NewShutdownPlan(components ...Component) (*ShutdownPlan, error) {
+	byName := map[string]Component{}
+	for _, c := range components {
+		if _, dup := byName[c.Name]; dup {
+			return nil, fmt.Errorf("duplicate component %q", c.Name)
+		}
+
+		if c.Stop == nil {
+			return nil, fmt.Errorf("component %q has no Stop func", c.Name)
+		}
+
+		byName[c.Name] = c
+	}
+
+	// waiting counts the unstopped dependencies of each component, dependents inverts DependsOn.
+	waiting := map[string]int{}
+	dependents := map[string][]string{}
+	for _, c := range components {
+		for _, dep := range c.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("component %q depends on unknown component %q", c.Name, dep)
+			}
+
+			waiting[c.Name]++
+			dependents[dep] = append(dependents[dep], c.Name)
+		}
+	}
+
+	next := []string{}
+	for _, c := range components {
+		if waiting[c.Name] == 0 {
+			next = append(next, c.Name)
+		}
+	}
+
+	p := &ShutdownPlan{}
+	planned := 0
+	for len(next) > 0 {
+		sort.Strings(next)
+
+		generation := make([]Component, len(next))
+		after := []string{}
+		for i, name := range next {
+			generation[i] = byName[name]
+			for _, dependent := range dependents[name] {
+				waiting[dependent]--
+				if waiting[dependent] == 0 {
+					after = append(after, dependent)
+				}
+			}
+		}
+
+		p.generations = append(p.generations, generation)
+		planned += len(generation)
+		next = after
+	}
+
+	if planned != len(components) {
+		return nil, fmt.Errorf("component dependencies form a cycle")
+	}
+
+	return p, nil
+}
+
+// Generations reports the names of the components in each generation, in stopping order.
+func (p *ShutdownPlan) Generations() [][]string {
+	names := make([][]string, len(p.generations))
+	for i, g := range p.generations {
+		for _, c := range g {
+			names[i] = append(names[i], c.Name)
+		}
+	}
+
+	return names
+}
+
+// Shutdown stops each generation in turn, the components of one generation concurrently.
+// A generation is only started once every component of the previous one has stopped.
+func (p *ShutdownPlan) Shutdown() {
+	for _, g := range p.generations {
+		var wg sync.WaitGroup
+		for _, c := range g {
+			wg.Add(1)
+			go func(c Component) {
+				defer wg.Done()
+				c.Stop()
+			}(c)
+		}
+
+		wg.Wait()
+	}
+}
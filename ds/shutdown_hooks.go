@@ -0,0 +1,41 @@
+package ds
+
+import (
+	"sort"
+)
+
+// shutdownHook is a callback registered by OnShutdown.
+type shutdownHook struct {
+	priority int
+	f        func()
+}
+
+// OnShutdown registers f to be called once the select is killed, before its listeners are
+// waited on. Hooks are called in order of priority, lowest first, and in the order they were
+// registered within a priority. The onKillAction given to NewDynamicSelect is a hook of priority 0,
+// so "flush metrics, then close the DB, then log" might be priorities -1, 0 and 1.
+// Hooks are called at the end of every run, and may be registered at any time.
+func (d *DynamicSelect) OnShutdown(priority int, f func()) {
+	if f == nil {
+		return
+	}
+
+	d.hooksMu.Lock()
+	defer d.hooksMu.Unlock()
+
+	d.hooks = append(d.hooks, shutdownHook{priority: priority, f: f})
+	sort.SliceStable(d.hooks, func(i, j int) bool {
+		return d.hooks[i].priority < d.hooks[j].priority
+	})
+}
+
+// runShutdownHooks calls the hooks registered by OnShutdown, in order.
+func (d *DynamicSelect) runShutdownHooks() {
+	d.hooksMu.Lock()
+	hooks := append([]shutdownHook(nil), d.hooks...)
+	d.hooksMu.Unlock()
+
+	for _, h := range hooks {
+		h.f()
+	}
+}
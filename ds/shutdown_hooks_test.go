@@ -0,0 +1,26 @@
+package ds
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestOnShutdown(t *testing.T) {
+	order := []string{}
+	hooked := NewDynamicSelect(func() { order = append(order, "close db") }, []ChannelEntry{})
+
+	hooked.OnShutdown(1, func() { order = append(order, "log") })
+	hooked.OnShutdown(-1, func() { order = append(order, "flush metrics") })
+	hooked.OnShutdown(1, func() { order = append(order, "log again") })
+
+	hookedReady := make(chan interface{})
+	go hooked.Forever(hookedReady)
+	<-hookedReady
+
+	hooked.Kill()
+	hooked.Wait()
+
+	if fmt.Sprint(order) != "[flush metrics close db log log again]" {
+		t.Errorf("Expected the hooks to run by priority, then registration, ran %v", order)
+	}
+}
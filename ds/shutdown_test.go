@@ -0,0 +1,121 @@
+package ds
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdownPlanOrder(t *testing.T) {
+	mu := sync.Mutex{}
+	stopped := []string{}
+	stop := func(name string) func() {
+		return func() {
+			mu.Lock()
+			stopped = append(stopped, name)
+			mu.Unlock()
+		}
+	}
+
+	p, err := NewShutdownPlan(
+		Component{Name: "sink", DependsOn: []string{"transform", "audit"}, Stop: stop("sink")},
+		Component{Name: "transform", DependsOn: []string{"producer"}, Stop: stop("transform")},
+		Component{Name: "audit", DependsOn: []string{"producer"}, Stop: stop("audit")},
+		Component{Name: "producer", Stop: stop("producer")},
+	)
+	if err != nil {
+		t.Fatalf("Could not plan shutdown: %s", err.Error())
+	}
+
+	expected := [][]string{{"producer"}, {"audit", "transform"}, {"sink"}}
+	if !reflect.DeepEqual(p.Generations(), expected) {
+		t.Errorf("Expected generations %v, found %v", expected, p.Generations())
+	}
+
+	p.Shutdown()
+	if stopped[0] != "producer" || stopped[3] != "sink" {
+		t.Errorf("Components stopped out of order: %v", stopped)
+	}
+}
+
+func TestShutdownPlanErrors(t *testing.T) {
+	noop := func() {}
+	cases := map[string][]Component{
+		"cycle": {
+			{Name: "a", DependsOn: []string{"b"}, Stop: noop},
+			{Name: "b", DependsOn: []string{"a"}, Stop: noop},
+		},
+		"unknown component": {
+			{Name: "a", DependsOn: []string{"missing"}, Stop: noop},
+		},
+		"duplicate component": {
+			{Name: "a", Stop: noop},
+			{Name: "a", Stop: noop},
+		},
+		"no Stop func": {
+			{Name: "a"},
+		},
+	}
+
+	for expected, components := range cases {
+		_, err := NewShutdownPlan(components...)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected an error reporting %s, found: %v", expected, err)
+		}
+	}
+}
+
+// A transformer relays into a sink's channel with a non-Blocking handler. Stopping the
+// transformer first, and closing its output once it is done, must never panic on the send.
+func TestShutdownPlanPipeline(t *testing.T) {
+	in := make(chan interface{})
+	out := make(chan interface{})
+
+	sinkHeard := make(chan interface{}, 100)
+	sink := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: out,
+		Handler: HandlerEntry{Func: func(i interface{}) { sinkHeard <- i }, Blocking: true},
+	}})
+
+	transform := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: in,
+		Handler: HandlerEntry{Func: func(i interface{}) {
+			time.Sleep(time.Millisecond)
+			out <- i
+		}},
+	}})
+
+	for _, d := range []*DynamicSelect{sink, transform} {
+		r := make(chan interface{})
+		go d.Forever(r)
+		<-r
+	}
+
+	for i := 0; i < 20; i++ {
+		in <- i
+	}
+
+	sinkAlive := false
+	p, err := NewShutdownPlan(
+		SelectComponent("sink", sink, nil, "transform"),
+		SelectComponent("transform", transform, func() {
+			sinkAlive = sink.IsAlive()
+			close(out)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Could not plan shutdown: %s", err.Error())
+	}
+
+	p.Shutdown()
+
+	if !sinkAlive {
+		t.Errorf("Sink was stopped before the transformer it depends on.")
+	}
+
+	if sink.IsAlive() {
+		t.Errorf("Sink was not stopped.")
+	}
+}
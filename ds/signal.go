@@ -0,0 +1,95 @@
+package ds
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// SignalEntry returns a ChannelEntry whose Channel receives the given signals, as os.Signal values,
+// registered with signal.Notify. With no signals given, all incoming signals are relayed.
+// Set its Handler and OnClose before loading it. Signals are caught from when the select first
+// listens to the entry until it is killed, the registration is then stopped and the channel closed.
+func SignalEntry(sigs ...os.Signal) ChannelEntry {
+	c := make(chan interface{})
+	return ChannelEntry{Channel: c, signals: &signalRelay{sigs: sigs, out: c}}
+}
+
+// signalRelay passes the signals of an entry built by SignalEntry to its channel.
+type signalRelay struct {
+	sigs []os.Signal
+	out  chan interface{}
+	once sync.Once
+}
+
+// relaySignals registers the relay's signals and passes them on until the select is killed,
+// then stops the registration and closes the relay's channel. Only the first call does anything.
+func (d *DynamicSelect) relaySignals(r *signalRelay) {
+	r.once.Do(func() {
+		notified := make(chan os.Signal, 1)
+		done := d.done
+		signal.Notify(notified, r.sigs...)
+
+		d.spawn(func() {
+			defer close(r.out)
+			defer signal.Stop(notified)
+
+			for {
+				select {
+				case <-done:
+					return
+				case sig := <-notified:
+					select {
+					case r.out <- sig:
+					case <-done:
+						return
+					}
+				}
+			}
+		})
+	})
+}
+
+// SignalError is the kill reason of a select killed by a signal, see WithSignalKill.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("DynamicSelect received %s", e.Signal)
+}
+
+// WithSignalKill kills the select when one of the given signals arrives while it runs, SIGINT and
+// SIGTERM if none are given. The kill reason is a *SignalError. Signals stop being caught once the
+// select halts.
+func WithSignalKill(sigs ...os.Signal) Option {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	return func(d *DynamicSelect) {
+		d.killSignals = sigs
+	}
+}
+
+// watchSignals kills the select on the first of its kill signals, until done is closed.
+func (d *DynamicSelect) watchSignals(done chan interface{}) {
+	if len(d.killSignals) == 0 {
+		return
+	}
+
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, d.killSignals...)
+
+	d.spawn(func() {
+		defer signal.Stop(caught)
+
+		select {
+		case sig := <-caught:
+			d.KillWithReason(&SignalError{Signal: sig})
+		case <-done:
+		}
+	})
+}
@@ -0,0 +1,65 @@
+//go:build unix
+
+package ds
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalEntry(t *testing.T) {
+	heard := make(chan interface{}, 1)
+	entry := SignalEntry(syscall.SIGUSR1)
+	entry.Handler = HandlerEntry{Func: func(i interface{}) { heard <- i }, Blocking: true}
+
+	signalled := NewDynamicSelect(func() {}, []ChannelEntry{entry})
+	signalledReady := make(chan interface{})
+	go signalled.Forever(signalledReady)
+	<-signalledReady
+	defer signalled.Kill()
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+
+	select {
+	case sig := <-heard:
+		if sig != syscall.SIGUSR1 {
+			t.Errorf("Expected SIGUSR1, heard %v", sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Signal was not heard.")
+	}
+
+	// Killing the select stops the registration, then closes the entry's channel.
+	signalled.Kill()
+	select {
+	case _, open := <-entry.Channel:
+		if open {
+			t.Errorf("Expected the entry's channel to be closed once killed.")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("The entry's relay outlived the select.")
+	}
+}
+
+func TestSignalKill(t *testing.T) {
+	killed := NewDynamicSelect(func() {}, []ChannelEntry{}, WithSignalKill(syscall.SIGUSR2))
+	killedReady := make(chan interface{})
+	go killed.Forever(killedReady)
+	<-killedReady
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+
+	select {
+	case <-killed.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Signal did not kill the select.")
+	}
+
+	var sigErr *SignalError
+	if !errors.As(killed.KillReason(), &sigErr) || sigErr.Signal != syscall.SIGUSR2 {
+		t.Errorf("Expected the signal as the kill reason, found %v", killed.KillReason())
+	}
+}
@@ -0,0 +1,169 @@
+package ds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Spec declares a DynamicSelect's entries and tuning, so a service's channel topology can
+// live in configuration. Handlers are referred to by name and looked up in a Registry.
+// The json tags are mirrored as yaml tags, so a YAML decoder of your choosing can fill a Spec too.
+type Spec struct {
+	Entries []EntrySpec `json:"entries" yaml:"entries"`
+
+	// OnKill names a Registry action run as the kill action.
+	OnKill string `json:"on_kill,omitempty" yaml:"on_kill,omitempty"`
+
+	// BatchSize, Shards and PriorityLevels map to WithBatchSize, WithShards and WithPriorityLevels when positive.
+	BatchSize      int `json:"batch_size,omitempty" yaml:"batch_size,omitempty"`
+	Shards         int `json:"shards,omitempty" yaml:"shards,omitempty"`
+	PriorityLevels int `json:"priority_levels,omitempty" yaml:"priority_levels,omitempty"`
+}
+
+// EntrySpec declares a single ChannelEntry. BuildFromSpec creates its channel.
+type EntrySpec struct {
+	// Name identifies the entry and must be unique within the Spec.
+	Name string `json:"name" yaml:"name"`
+
+	// Handler names a Registry handler, OnClose a Registry action.
+	Handler string `json:"handler" yaml:"handler"`
+	OnClose string `json:"on_close,omitempty" yaml:"on_close,omitempty"`
+
+	// Buffer sizes the entry's channel.
+	Buffer int `json:"buffer,omitempty" yaml:"buffer,omitempty"`
+
+	Blocking        bool `json:"blocking,omitempty" yaml:"blocking,omitempty"`
+	Priority        bool `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Level           int  `json:"level,omitempty" yaml:"level,omitempty"`
+	OnCloseBlocking bool `json:"on_close_blocking,omitempty" yaml:"on_close_blocking,omitempty"`
+
+	// RateLimit maps to ChannelEntry.RateLimit.
+	RateLimit float64 `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+
+	// Tags are free-form labels, returned with the built select for the caller's own use.
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// Registry resolves the names used in a Spec.
+type Registry struct {
+	Handlers map[string]func(i interface{})
+	Actions  map[string]func()
+}
+
+// Built is what BuildFromSpec constructs. Its maps are keyed by entry name.
+type Built struct {
+	Select *DynamicSelect
+
+	// Channels holds the channel created for each entry, for producers to send on.
+	Channels map[string]chan interface{}
+
+	// Index holds each entry's index within Select.Channels.
+	Index map[string]int
+
+	Tags map[string][]string
+}
+
+// ParseSpec decodes a JSON Spec, rejecting unknown fields.
+func ParseSpec(r io.Reader) (Spec, error) {
+	spec := Spec{}
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("could not parse DynamicSelect spec: %w", err)
+	}
+
+	return spec, nil
+}
+
+// BuildFromSpec constructs a DynamicSelect, and a channel per entry, from a Spec.
+// Every unknown name and duplicate entry is reported together, along with the problems found
+// by ValidateEntries. Further options are applied after those the Spec implies.
+func BuildFromSpec(spec Spec, registry Registry, opts ...Option) (*Built, error) {
+	errs := []error{}
+	built := &Built{
+		Channels: map[string]chan interface{}{},
+		Index:    map[string]int{},
+		Tags:     map[string][]string{},
+	}
+
+	onKill := func() {}
+	if spec.OnKill != "" {
+		if f, ok := registry.Actions[spec.OnKill]; ok {
+			onKill = f
+		} else {
+			errs = append(errs, fmt.Errorf("on_kill: unknown action %q", spec.OnKill))
+		}
+	}
+
+	entries := make([]ChannelEntry, 0, len(spec.Entries))
+	for i, es := range spec.Entries {
+		if es.Name == "" {
+			errs = append(errs, fmt.Errorf("entry %d: has no name", i))
+		} else if _, dup := built.Index[es.Name]; dup {
+			errs = append(errs, fmt.Errorf("entry %d: duplicate name %q", i, es.Name))
+		}
+
+		handler, ok := registry.Handlers[es.Handler]
+		if !ok {
+			errs = append(errs, fmt.Errorf("entry %q: unknown handler %q", es.Name, es.Handler))
+		}
+
+		var onClose func()
+		if es.OnClose != "" {
+			if onClose, ok = registry.Actions[es.OnClose]; !ok {
+				errs = append(errs, fmt.Errorf("entry %q: unknown on_close action %q", es.Name, es.OnClose))
+			}
+		}
+
+		c := make(chan interface{}, es.Buffer)
+		built.Channels[es.Name] = c
+		built.Index[es.Name] = i
+		built.Tags[es.Name] = es.Tags
+
+		entries = append(entries, ChannelEntry{
+			Name:    es.Name,
+			Channel: c,
+			Handler: HandlerEntry{
+				Func:     handler,
+				Blocking: es.Blocking,
+				Priority: es.Priority,
+				Level:    es.Level,
+			},
+			OnClose: OnCloseEntry{
+				Func:     onClose,
+				Blocking: es.OnCloseBlocking,
+			},
+			RateLimit: es.RateLimit,
+		})
+	}
+
+	// Unknown handlers are already reported.
+	if len(errs) == 0 {
+		if err := ValidateEntries(entries); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	specOpts := []Option{}
+	if spec.BatchSize > 0 {
+		specOpts = append(specOpts, WithBatchSize(spec.BatchSize))
+	}
+
+	if spec.Shards > 0 {
+		specOpts = append(specOpts, WithShards(spec.Shards))
+	}
+
+	if spec.PriorityLevels > 0 {
+		specOpts = append(specOpts, WithPriorityLevels(spec.PriorityLevels))
+	}
+
+	built.Select = NewDynamicSelect(onKill, entries, append(specOpts, opts...)...)
+	return built, nil
+}
@@ -0,0 +1,109 @@
+package ds
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSpec = `{
+	"on_kill": "killed",
+	"batch_size": 4,
+	"entries": [
+		{"name": "orders", "handler": "record", "buffer": 2, "blocking": true, "tags": ["billing"]},
+		{"name": "alerts", "handler": "record", "on_close": "closed", "blocking": true, "priority": true},
+		{"name": "metrics", "handler": "record", "rate_limit": 1000}
+	]
+}`
+
+func TestBuildFromSpec(t *testing.T) {
+	spec, err := ParseSpec(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatalf("Could not parse spec: %s", err.Error())
+	}
+
+	heard := make(chan interface{}, 3)
+	killed, closed := make(chan struct{}), make(chan struct{})
+	registry := Registry{
+		Handlers: map[string]func(i interface{}){
+			"record": func(i interface{}) { heard <- i },
+		},
+		Actions: map[string]func(){
+			"killed": func() { close(killed) },
+			"closed": func() { close(closed) },
+		},
+	}
+
+	built, err := BuildFromSpec(spec, registry)
+	if err != nil {
+		t.Fatalf("Could not build from spec: %s", err.Error())
+	}
+
+	if cap(built.Channels["orders"]) != 2 {
+		t.Errorf("Expected the orders channel to be buffered by 2.")
+	}
+
+	if built.Index["metrics"] != 2 || built.Tags["orders"][0] != "billing" {
+		t.Errorf("Built select does not describe its entries.")
+	}
+
+	specReady := make(chan interface{})
+	go built.Select.Forever(specReady)
+	<-specReady
+
+	for _, name := range []string{"orders", "alerts", "metrics"} {
+		built.Channels[name] <- name
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-heard:
+		case <-time.After(time.Second):
+			t.Fatalf("Built select did not hear all entries.")
+		}
+	}
+
+	close(built.Channels["alerts"])
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("The registry's on_close action was not used.")
+	}
+
+	built.Select.Kill()
+	select {
+	case <-killed:
+	case <-time.After(time.Second):
+		t.Fatalf("The registry's on_kill action was not used.")
+	}
+}
+
+func TestBuildFromBadSpec(t *testing.T) {
+	_, err := ParseSpec(strings.NewReader(`{"entries": [], "unknown": 1}`))
+	if err == nil {
+		t.Errorf("Spec with unknown fields was parsed.")
+	}
+
+	spec := Spec{
+		OnKill: "missing",
+		Entries: []EntrySpec{
+			{Name: "a", Handler: "nope"},
+			{Name: "a", Handler: "ok", OnClose: "missing"},
+		},
+	}
+
+	registry := Registry{
+		Handlers: map[string]func(i interface{}){"ok": func(i interface{}) {}},
+	}
+
+	_, err = BuildFromSpec(spec, registry)
+	if err == nil {
+		t.Fatalf("Bad spec was built.")
+	}
+
+	for _, expected := range []string{`unknown action "missing"`, `unknown handler "nope"`, `duplicate name "a"`, `unknown on_close action "missing"`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error to report %s, found: %s", expected, err.Error())
+		}
+	}
+}
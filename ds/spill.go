@@ -0,0 +1,356 @@
+package ds
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Spill lets an entry's listener keep reading from a bursty producer while its handler falls
+// behind, see ChannelEntry.Spill. Messages read but not yet routed wait in memory, and once
+// Threshold are waiting, further messages are written to a file until the backlog has drained.
+// The file only relieves pressure, it is removed once the listener exits, and messages still
+// waiting when the select or entry is killed are dropped.
+type Spill struct {
+	// Threshold is how many messages wait in memory before further messages spill to disk.
+	Threshold int
+
+	// Dir is where the spill file is created, os.TempDir if empty.
+	Dir string
+
+	// Encode and Decode convert messages to and from bytes. If nil, messages are gob encoded,
+	// which needs their concrete types registered with gob.Register.
+	Encode func(x interface{}) ([]byte, error)
+	Decode func(b []byte) (interface{}, error)
+}
+
+// ErrSpillFailed is carried, wrapped in a HandlerError, for a message a spilling entry lost.
+var ErrSpillFailed = errors.New("failed to spill a message to disk")
+
+// spool queues an entry's messages between its channel and its listener, in memory up to the
+// threshold and on disk beyond it. Once anything is on disk, further messages follow it there
+// until it has been read back, so they stay in order.
+type spool struct {
+	threshold int
+	encode    func(x interface{}) ([]byte, error)
+	decode    func(b []byte) (interface{}, error)
+	ready     chan struct{}
+
+	mu     sync.Mutex
+	memory []interface{}
+	file   *os.File
+
+	// onDisk counts the messages written and not yet read back from readAt.
+	onDisk int
+	readAt int64
+
+	// ended is set once the channel has closed for good, closed once the spool is discarded.
+	ended, closed bool
+
+	// source is the channel the spool is filled from, replaced when the entry reopens.
+	source chan interface{}
+}
+
+func newSpool(s *Spill) (*spool, error) {
+	file, err := os.CreateTemp(s.Dir, "goconquer-spill-*")
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &spool{
+		threshold: s.Threshold,
+		encode:    s.Encode,
+		decode:    s.Decode,
+		ready:     make(chan struct{}, 1),
+		file:      file,
+	}
+
+	if sp.encode == nil {
+		sp.encode = gobEncode
+	}
+
+	if sp.decode == nil {
+		sp.decode = gobDecode
+	}
+
+	return sp, nil
+}
+
+func gobEncode(x interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(&x)
+	return b.Bytes(), err
+}
+
+func gobDecode(b []byte) (interface{}, error) {
+	var x interface{}
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&x)
+	return x, err
+}
+
+// push queues x, reporting whether it spilled to disk.
+func (s *spool) push(x interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.signal()
+
+	if s.closed {
+		return false, os.ErrClosed
+	}
+
+	if s.onDisk == 0 && len(s.memory) < s.threshold {
+		s.memory = append(s.memory, x)
+		return false, nil
+	}
+
+	b, err := s.encode(x)
+	if err != nil {
+		return false, err
+	}
+
+	record := binary.BigEndian.AppendUint32(nil, uint32(len(b)))
+	if _, err := s.file.Write(append(record, b...)); err != nil {
+		return false, err
+	}
+
+	s.onDisk++
+	return true, nil
+}
+
+// pop takes the oldest message queued, reporting false if there is none. On an error it reports
+// how many messages were lost, the one that failed to decode, or all those on disk if the file
+// could not be read.
+func (s *spool) pop() (interface{}, bool, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.memory) > 0 {
+		x := s.memory[0]
+		s.memory[0] = nil
+		s.memory = s.memory[1:]
+		return x, true, 0, nil
+	}
+
+	if s.onDisk == 0 || s.closed {
+		return nil, false, 0, nil
+	}
+
+	b, err := s.read()
+	if err != nil {
+		lost := s.onDisk
+		s.onDisk = 0
+		return nil, false, lost, errors.Join(err, s.rewind())
+	}
+
+	s.onDisk--
+	if s.onDisk == 0 {
+		if err := s.rewind(); err != nil {
+			return nil, false, 1, err
+		}
+	}
+
+	x, err := s.decode(b)
+	if err != nil {
+		return nil, false, 1, err
+	}
+
+	return x, true, 0, nil
+}
+
+// read reads back the next message written to the file. The caller must hold mu.
+func (s *spool) read() ([]byte, error) {
+	var size [4]byte
+	if _, err := s.file.ReadAt(size[:], s.readAt); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := s.file.ReadAt(b, s.readAt+int64(len(size))); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	s.readAt += int64(len(size) + len(b))
+	return b, nil
+}
+
+// rewind empties the file once everything written has been read back. The caller must hold mu.
+func (s *spool) rewind() error {
+	s.readAt = 0
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+
+	_, err := s.file.Seek(0, io.SeekStart)
+	return err
+}
+
+// reopened records the channel the entry reopened with.
+func (s *spool) reopened(c chan interface{}) {
+	s.mu.Lock()
+	s.source = c
+	s.mu.Unlock()
+}
+
+// channel returns the channel the spool is filled from.
+func (s *spool) channel() chan interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.source
+}
+
+// end records that the channel has closed for good.
+func (s *spool) end() {
+	s.mu.Lock()
+	s.ended = true
+	s.mu.Unlock()
+	s.signal()
+}
+
+// finished reports whether the channel has closed for good and everything queued has been taken.
+func (s *spool) finished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ended && len(s.memory) == 0 && s.onDisk == 0
+}
+
+// signal wakes the relay waiting on the spool, if any.
+func (s *spool) signal() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// discard removes the file, returning how many messages were still queued.
+func (s *spool) discard() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0
+	}
+
+	s.closed = true
+	s.file.Close()
+	os.Remove(s.file.Name())
+
+	return len(s.memory) + s.onDisk
+}
+
+// spill interposes a spool between the entry at index i's channel and its listener: a reader
+// drains the channel into the spool and a relay feeds the listener from it, as fast as it
+// routes. It returns the relay to listen to and the spool, or the entry's channel and nil if the
+// spool can't be made. The entry keeps its channel, the relay is the listener's alone.
+func (d *DynamicSelect) spill(i int, e ChannelEntry) (chan interface{}, *spool) {
+	s, err := newSpool(e.Spill)
+	if err != nil {
+		d.reportError(&HandlerError{Index: i, Name: e.Name, Err: fmt.Errorf("%w, listening without: %w", ErrSpillFailed, err)})
+		return e.Channel, nil
+	}
+
+	relay := make(chan interface{})
+	s.source = e.Channel
+	d.spawn(func() { d.fillSpool(i, e, s) })
+	d.spawn(func() { d.relaySpool(i, e, s, relay) })
+
+	return relay, s
+}
+
+// fillSpool reads the entry's channel into the spool until it closes for good, or the select or
+// entry is killed.
+func (d *DynamicSelect) fillSpool(i int, e ChannelEntry, s *spool) {
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-e.stop:
+			return
+		case x, ok := <-e.Channel:
+			if !ok {
+				if c := d.reopen(i, e); c != nil {
+					e.Channel = c
+					s.reopened(c)
+					continue
+				}
+
+				s.end()
+				return
+			}
+
+			spilled, err := s.push(x)
+			if err != nil {
+				d.reportError(&HandlerError{Index: i, Name: e.Name, Message: x, Err: fmt.Errorf("%w: %w", ErrSpillFailed, err)})
+				d.spillLost(i, e, x)
+				continue
+			}
+
+			if spilled {
+				e.stats.spill()
+			}
+		}
+	}
+}
+
+// relaySpool passes the spool's messages to the listener in order, closing relay once the
+// channel has closed for good and everything has been passed on. The spool is discarded, and
+// anything still queued dropped, once the select or entry is killed.
+func (d *DynamicSelect) relaySpool(i int, e ChannelEntry, s *spool, relay chan interface{}) {
+	defer func() {
+		for n := s.discard(); n > 0; n-- {
+			d.spillLost(i, e, nil)
+		}
+	}()
+
+	for {
+		x, ok, lost, err := s.pop()
+		if err != nil {
+			d.reportError(&HandlerError{Index: i, Name: e.Name, Err: fmt.Errorf("%w, %d lost reading back: %w", ErrSpillFailed, lost, err)})
+			for ; lost > 0; lost-- {
+				d.spillLost(i, e, nil)
+			}
+
+			continue
+		}
+
+		if !ok {
+			if s.finished() {
+				close(relay)
+				return
+			}
+
+			select {
+			case <-s.ready:
+				continue
+			case <-d.done:
+				return
+			case <-e.stop:
+				return
+			}
+		}
+
+		select {
+		case relay <- x:
+		case <-d.done:
+			d.spillLost(i, e, x)
+			return
+		case <-e.stop:
+			d.spillLost(i, e, x)
+			return
+		}
+	}
+}
+
+// spillLost releases what a message of the entry at index i holds once a spool has lost it,
+// x if it was read.
+func (d *DynamicSelect) spillLost(i int, e ChannelEntry, x interface{}) {
+	e.Credit.release()
+	e.stats.drop()
+	d.shed(i, e, x, DropSpill)
+}
@@ -0,0 +1,134 @@
+package ds
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpool(t *testing.T) {
+	s, err := newSpool(&Spill{Threshold: 2, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to make a spool: %v", err)
+	}
+	defer s.discard()
+
+	spilled := 0
+	for i := 0; i < 5; i++ {
+		onDisk, err := s.push(i)
+		if err != nil {
+			t.Fatalf("Failed to push %d: %v", i, err)
+		}
+
+		if onDisk {
+			spilled++
+		}
+	}
+
+	if spilled != 3 {
+		t.Errorf("Expected the 3 messages past the threshold to spill, %d did", spilled)
+	}
+
+	// Once memory has room again, messages still follow those on disk.
+	popped := []interface{}{}
+	for len(popped) < 3 {
+		x, ok, _, err := s.pop()
+		if err != nil || !ok {
+			t.Fatalf("Failed to pop: %v", err)
+		}
+		popped = append(popped, x)
+	}
+
+	if onDisk, _ := s.push(5); !onDisk {
+		t.Errorf("Expected a message pushed behind a spilled one to spill too")
+	}
+
+	for {
+		x, ok, _, err := s.pop()
+		if err != nil {
+			t.Fatalf("Failed to pop: %v", err)
+		}
+
+		if !ok {
+			break
+		}
+		popped = append(popped, x)
+	}
+
+	if fmt.Sprint(popped) != "[0 1 2 3 4 5]" {
+		t.Errorf("Expected the messages in order [0 1 2 3 4 5], popped %v", popped)
+	}
+
+	if info, err := s.file.Stat(); err != nil || info.Size() != 0 {
+		t.Errorf("Expected the file to be emptied once read back")
+	}
+}
+
+func TestSpill(t *testing.T) {
+	dir := t.TempDir()
+	heard := make(chan interface{}, 20)
+	release := make(chan struct{})
+	c := make(chan interface{})
+
+	spilling := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Channel: c,
+		Handler: HandlerEntry{
+			Func: func(i interface{}) {
+				<-release
+				heard <- i
+			},
+			Blocking: true,
+		},
+		Spill: &Spill{Threshold: 2, Dir: dir},
+	}})
+	spillingReady := make(chan interface{})
+	go spilling.Forever(spillingReady)
+	<-spillingReady
+
+	// The handler is held up, yet the producer is never blocked for long.
+	for i := 0; i < 10; i++ {
+		select {
+		case c <- i:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the spilling listener to keep reading, blocked on %d", i)
+		}
+	}
+
+	time.Sleep(time.Second / 20)
+	if stats := spilling.Stats()[0]; stats.Spilled == 0 {
+		t.Errorf("Expected messages to spill to disk while the handler was held up")
+	}
+
+	close(release)
+	for want := 0; want < 10; want++ {
+		select {
+		case got := <-heard:
+			if got != want {
+				t.Errorf("Expected to handle %d, handled %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected to handle %d", want)
+		}
+	}
+
+	close(c)
+	time.Sleep(time.Second / 20)
+	if !spilling.Channels()[0].IsClosed {
+		t.Errorf("Expected the entry to close once its channel closed and the spill drained")
+	}
+
+	if spilling.Channels()[0].Channel != c {
+		t.Errorf("Expected the entry to keep its own channel, not the spool's relay")
+	}
+
+	spilling.Kill()
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("Expected the spill file to be removed, found %v", files)
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected the spill directory to be left: %v", err)
+	}
+}
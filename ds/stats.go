@@ -0,0 +1,161 @@
+package ds
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EntryStats are the counters kept for one ChannelEntry.
+type EntryStats struct {
+	// Index of the entry, as in Channels.
+	Index int
+
+	// Handled counts the handler calls that have returned.
+	Handled uint64
+
+	// Dropped counts the messages discarded by the aggregator overflow policy, or lost by a
+	// spilling entry, see Drops.
+	Dropped uint64
+
+	// Skipped counts the messages left unhandled by sampling, see ChannelEntry.SampleEvery.
+	Skipped uint64
+
+	// Spilled counts the messages written to disk, see ChannelEntry.Spill.
+	Spilled uint64
+
+	// Duplicates counts the messages suppressed as repeats, see ChannelEntry.DedupeKey.
+	Duplicates uint64
+
+	// QueueLatency records the time from a listener hearing a message to its handler starting,
+	// and HandlerLatency the time the handler took. Both are empty unless WithLatencyStats is used.
+	QueueLatency   HistogramSnapshot
+	HandlerLatency HistogramSnapshot
+}
+
+// entryStats is shared by every copy of the ChannelEntry it is attached to at load.
+type entryStats struct {
+	handled atomic.Uint64
+	dropped atomic.Uint64
+	skipped atomic.Uint64
+	repeats atomic.Uint64
+	spilled atomic.Uint64
+
+	// sampled counts the messages sampling has considered, see ChannelEntry.SampleEvery.
+	sampled atomic.Uint64
+
+	// listening is set while a listener hears the entry, handling counts handler calls in progress.
+	// Both identify what holds up a shut down.
+	listening atomic.Bool
+	handling  atomic.Int32
+
+	// lastHeard is when a message for the entry was last heard, in Unix nanoseconds, see Heartbeat.
+	lastHeard atomic.Int64
+
+	// nil unless latency stats are enabled.
+	queueLatency   *Histogram
+	handlerLatency *Histogram
+}
+
+func newEntryStats(latency bool) *entryStats {
+	s := &entryStats{}
+	if latency {
+		s.queueLatency = &Histogram{}
+		s.handlerLatency = &Histogram{}
+	}
+
+	return s
+}
+
+// begin records the time a message heard at enqueued spent waiting, returning the handler's start.
+func (s *entryStats) begin(enqueued time.Time) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+
+	s.handling.Add(1)
+	if s.queueLatency == nil {
+		return time.Time{}
+	}
+
+	start := time.Now()
+	s.queueLatency.Record(start.Sub(enqueued))
+	return start
+}
+
+// end records a handler that began at start returning.
+func (s *entryStats) end(start time.Time) {
+	if s == nil {
+		return
+	}
+
+	s.handling.Add(-1)
+	s.handled.Add(1)
+	if s.handlerLatency != nil {
+		s.handlerLatency.Record(time.Since(start))
+	}
+}
+
+// drop records a message of the entry being discarded.
+func (s *entryStats) drop() {
+	if s != nil {
+		s.dropped.Add(1)
+	}
+}
+
+// spill records a message of the entry being written to disk.
+func (s *entryStats) spill() {
+	if s != nil {
+		s.spilled.Add(1)
+	}
+}
+
+// listen records a listener hearing the entry.
+func (s *entryStats) listen() {
+	if s != nil {
+		s.listening.Store(true)
+	}
+}
+
+// enqueued is the time a message is heard, if anything will measure it.
+func (d *DynamicSelect) enqueued() time.Time {
+	if !d.latencyStats {
+		return time.Time{}
+	}
+
+	return time.Now()
+}
+
+// Stats reports the counters kept for every entry, in the order of Channels.
+func (d *DynamicSelect) Stats() []EntryStats {
+	d.loadMu.Lock()
+	entries := make([]ChannelEntry, len(d.channels))
+	copy(entries, d.channels)
+	d.loadMu.Unlock()
+
+	stats := make([]EntryStats, len(entries))
+	for i, e := range entries {
+		stats[i] = e.statsAt(i)
+	}
+
+	return stats
+}
+
+// statsAt reports the counters kept for the entry, found at index i.
+func (e ChannelEntry) statsAt(i int) EntryStats {
+	stats := EntryStats{Index: i}
+	if e.stats == nil {
+		return stats
+	}
+
+	stats.Handled = e.stats.handled.Load()
+	stats.Dropped = e.stats.dropped.Load()
+	stats.Skipped = e.stats.skipped.Load()
+	stats.Duplicates = e.stats.repeats.Load()
+	stats.Spilled = e.stats.spilled.Load()
+	if e.stats.queueLatency != nil {
+		stats.QueueLatency = e.stats.queueLatency.Snapshot()
+		stats.HandlerLatency = e.stats.handlerLatency.Snapshot()
+	}
+
+	return stats
+}
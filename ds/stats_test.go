@@ -0,0 +1,55 @@
+package ds
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	defer reset()
+
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{lesserChannel, unblockingChannel}, WithLatencyStats())
+	go selectMgr.Forever(ready)
+	<-ready
+
+	for i := 0; i < 3; i++ {
+		lesserChannel.Channel <- i
+		unblockingChannel.Channel <- i
+	}
+
+	time.Sleep(time.Second / 10)
+
+	stats := selectMgr.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("Expected stats for 2 entries, found %d", len(stats))
+	}
+
+	for _, s := range stats {
+		if s.Handled != 3 {
+			t.Errorf("Expected entry %d to have handled 3 messages, found %d", s.Index, s.Handled)
+		}
+
+		if s.QueueLatency.Count != 3 || s.HandlerLatency.Count != 3 {
+			t.Errorf("Expected entry %d to have 3 latency recordings, found %d and %d", s.Index, s.QueueLatency.Count, s.HandlerLatency.Count)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := selectMgr.WritePrometheus(&b, "test_select"); err != nil {
+		t.Fatalf("Could not write Prometheus metrics: %s", err.Error())
+	}
+
+	for _, expected := range []string{
+		`test_select_handled_total{entry="0"} 3`,
+		`test_select_queue_latency_seconds_bucket{entry="1",le="+Inf"} 3`,
+		`test_select_handler_latency_seconds_count{entry="0"} 3`,
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("Expected Prometheus output to contain %s", expected)
+		}
+	}
+
+	selectMgr.Kill()
+}
@@ -0,0 +1,83 @@
+package ds
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krhoda/goconquer/clock"
+	"github.com/krhoda/goconquer/exbo"
+)
+
+// Producer feeds messages to send until ctx is done, or it fails. It must give up sending once
+// ctx is done, as nothing will be listening.
+type Producer func(ctx context.Context, send chan<- interface{}) error
+
+// Supervise adds an entry, named name if it isn't empty, whose handler is passed whatever producer
+// sends. Whenever producer returns or panics it is restarted, after a backoff managed by an
+// exbo.ExpoBackoffManager built from opts, until the select is killed. Its errors, and panics as a
+// *PanicError, are passed to the error sink, see WithErrorSink, with an Index of -1.
+// The entry's channel is closed once the select is killed and producer has returned.
+// It may be called before or while the select runs. Unless opts has a Clock of its own, the
+// backoff is timed by the select's, see WithClock.
+func (d *DynamicSelect) Supervise(name string, producer Producer, opts exbo.Opts, handler HandlerEntry) error {
+	if opts.CooldownTick <= 0 {
+		return fmt.Errorf("supervisor cooldown tick: %w, was %s", ErrBadInterval, opts.CooldownTick)
+	}
+
+	if _, real := d.clock.(clock.Real); !real && opts.Clock == nil {
+		opts.Clock = d.clock
+	}
+
+	backoff, err := exbo.NewExpoBackoffManager(opts)
+	if err != nil {
+		return err
+	}
+
+	out := make(chan interface{})
+	if err := d.add(ChannelEntry{Name: name, Channel: out, Handler: handler}); err != nil {
+		return err
+	}
+
+	done := d.done
+	d.spawn(func() { d.supervise(name, producer, backoff, out, done) })
+	return nil
+}
+
+// supervise runs producer until done is closed, backing off between restarts, then closes out.
+func (d *DynamicSelect) supervise(name string, producer Producer, backoff *exbo.ExpoBackoffManager, out chan interface{}, done chan interface{}) {
+	defer close(out)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d.spawn(func() { backoff.Run() })
+	<-backoff.Ready
+
+	// Killing the select cuts short the producer and any backoff.
+	d.spawn(func() {
+		<-done
+		cancel()
+		backoff.Stop()
+	})
+
+	for {
+		if err := runProducer(ctx, producer, out); err != nil {
+			d.reportError(&HandlerError{Index: -1, Name: name, Err: err})
+		}
+
+		if ctx.Err() != nil || backoff.Wait() != nil {
+			return
+		}
+	}
+}
+
+// runProducer runs producer once, returning a panic as a *PanicError.
+func runProducer(ctx context.Context, producer Producer, out chan<- interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r}
+		}
+	}()
+
+	return producer(ctx, out)
+}
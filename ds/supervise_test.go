@@ -0,0 +1,87 @@
+package ds
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/krhoda/goconquer/exbo"
+)
+
+func TestSupervise(t *testing.T) {
+	heard := make(chan interface{}, 8)
+	failures := make(chan *HandlerError, 8)
+
+	supervising := NewDynamicSelect(func() {}, []ChannelEntry{}, WithErrorSink(func(err *HandlerError) {
+		failures <- err
+	}))
+
+	runs := 0
+	producer := func(ctx context.Context, send chan<- interface{}) error {
+		runs++
+		switch runs {
+		case 1:
+			send <- "first"
+			return errors.New("connection lost")
+		case 2:
+			panic("bad frame")
+		default:
+			select {
+			case send <- "recovered":
+			case <-ctx.Done():
+			}
+
+			<-ctx.Done()
+			return nil
+		}
+	}
+
+	opts := exbo.Opts{Min: time.Millisecond, Max: time.Millisecond * 4, CooldownTick: time.Second, CooldownSize: time.Millisecond}
+	err := supervising.Supervise("feed", producer, opts, HandlerEntry{Func: func(i interface{}) { heard <- i }, Blocking: true})
+	if err != nil {
+		t.Fatalf("Failed to supervise: %v", err)
+	}
+
+	feed, _, _ := supervising.Entry("feed")
+
+	supervisingReady := make(chan interface{})
+	go supervising.Forever(supervisingReady)
+	<-supervisingReady
+
+	for _, want := range []string{"first", "recovered"} {
+		select {
+		case got := <-heard:
+			if got != want {
+				t.Errorf("Expected %s, heard %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Never heard %s", want)
+		}
+	}
+
+	failure := <-failures
+	if failure.Name != "feed" || failure.Err.Error() != "connection lost" {
+		t.Errorf("Expected the producer's error to be reported, got %v", failure)
+	}
+
+	var panicked *PanicError
+	if failure := <-failures; !errors.As(failure, &panicked) {
+		t.Errorf("Expected the producer's panic to be reported, got %v", failure)
+	}
+
+	supervising.Kill()
+	supervising.Wait()
+	select {
+	case _, ok := <-feed.Channel:
+		if ok {
+			t.Errorf("Expected nothing more to be sent once the select was killed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the supervised entry's channel to close once the select was killed")
+	}
+
+	if err := supervising.Supervise("bad", producer, exbo.Opts{Min: time.Second, Max: time.Millisecond, CooldownTick: time.Second}, HandlerEntry{}); !errors.Is(err, exbo.ErrIncoherentOpts) {
+		t.Errorf("Expected incoherent backoff options to be refused")
+	}
+}
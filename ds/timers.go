@@ -0,0 +1,81 @@
+package ds
+
+import (
+	"fmt"
+	"time"
+)
+
+// AddTicker adds an entry whose handler is passed the time every interval, as a time.Ticker ticks,
+// until the select is killed. The ticker is then stopped and the entry's channel closed.
+// It may be called before or while the select runs.
+func (d *DynamicSelect) AddTicker(interval time.Duration, handler HandlerEntry) error {
+	if interval <= 0 {
+		return fmt.Errorf("ticker %w, was %s", ErrBadInterval, interval)
+	}
+
+	t := d.clock.NewTicker(interval)
+	return d.add(ChannelEntry{
+		Channel: d.relayTime(t.C(), t.Stop, false),
+		Handler: handler,
+	})
+}
+
+// AddAfter adds an entry whose handler is passed the time once delay has passed, as a time.Timer fires.
+// The entry's channel is closed once it has, or once the select is killed.
+// It may be called before or while the select runs.
+func (d *DynamicSelect) AddAfter(delay time.Duration, handler HandlerEntry) error {
+	t := d.clock.NewTimer(delay)
+	return d.add(ChannelEntry{
+		Channel: d.relayTime(t.C(), func() { t.Stop() }, true),
+		Handler: handler,
+	})
+}
+
+// relayTime passes the times heard on c to the returned channel until the select is killed,
+// or just the first if once is set, then calls stop and closes the channel.
+func (d *DynamicSelect) relayTime(c <-chan time.Time, stop func(), once bool) chan interface{} {
+	out := make(chan interface{})
+	done := d.done
+
+	d.spawn(func() {
+		defer close(out)
+		defer stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case t := <-c:
+				select {
+				case out <- t:
+				case <-done:
+					return
+				}
+
+				if once {
+					return
+				}
+			}
+		}
+	})
+
+	return out
+}
+
+// add loads an entry into a running select, or adds it to the initial channels of one yet to run.
+func (d *DynamicSelect) add(e ChannelEntry) error {
+	if d.running.Load() || !d.IsAlive() {
+		_, err := d.Load([]ChannelEntry{e})
+		return err
+	}
+
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	d.loadMu.Lock()
+	d.channels = append(d.channels, d.attach(len(d.channels), e))
+	d.loadMu.Unlock()
+
+	return nil
+}
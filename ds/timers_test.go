@@ -0,0 +1,59 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimerEntries(t *testing.T) {
+	ticks := make(chan interface{}, 8)
+	fired := make(chan interface{}, 1)
+
+	timed := NewDynamicSelect(func() {}, []ChannelEntry{})
+
+	// Added before running...
+	err := timed.AddTicker(time.Second/50, HandlerEntry{Func: func(i interface{}) {
+		select {
+		case ticks <- i:
+		default:
+		}
+	}, Blocking: true})
+	if err != nil {
+		t.Fatalf("Could not add ticker: %s", err.Error())
+	}
+
+	timedReady := make(chan interface{})
+	go timed.Forever(timedReady)
+	<-timedReady
+
+	// ...and while running.
+	err = timed.AddAfter(time.Second/50, HandlerEntry{Func: func(i interface{}) { fired <- i }, Blocking: true})
+	if err != nil {
+		t.Fatalf("Could not add timer: %s", err.Error())
+	}
+
+	time.Sleep(time.Second / 5)
+	if len(ticks) < 3 {
+		t.Errorf("Expected the ticker to tick repeatedly, ticked %d times", len(ticks))
+	}
+
+	if _, ok := (<-fired).(time.Time); !ok {
+		t.Errorf("Expected the timer to pass the time.")
+	}
+
+	if !timed.Channels()[1].IsClosed {
+		t.Errorf("Expected a fired timer's entry to be closed.")
+	}
+
+	timed.Kill()
+	timed.Wait()
+
+	if _, open := <-timed.Channels()[0].Channel; open {
+		t.Errorf("Expected the ticker's channel to be closed once killed.")
+	}
+
+	if err := timed.AddTicker(0, HandlerEntry{}); !errors.Is(err, ErrBadInterval) {
+		t.Errorf("Expected ErrBadInterval adding a ticker without an interval, got: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package ds
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+	"sync"
+)
+
+// entryTrace is the runtime/trace task of an entry, begun by its first handler call while
+// tracing and ended as its listener exits, so each run of the listener is a task of its own.
+type entryTrace struct {
+	name string
+
+	mu   sync.Mutex
+	ctx  context.Context
+	task *trace.Task
+}
+
+func newEntryTrace(i int, name string) *entryTrace {
+	if name == "" {
+		name = fmt.Sprintf("%d", i)
+	}
+
+	return &entryTrace{name: "ds entry " + name}
+}
+
+// context returns the Context of the entry's task, beginning it under parent if need be.
+func (t *entryTrace) context(parent context.Context) context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.task == nil {
+		t.ctx, t.task = trace.NewTask(parent, t.name)
+	}
+
+	return t.ctx
+}
+
+// end ends the entry's task, if one was begun.
+func (t *entryTrace) end() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.task != nil {
+		t.task.End()
+		t.ctx, t.task = nil, nil
+	}
+}
+
+// traced wraps f so that, while runtime/trace is enabled, each call is a region of the entry's task.
+func (d *DynamicSelect) traced(t *entryTrace, f HandlerFunc) HandlerFunc {
+	if t == nil {
+		return f
+	}
+
+	return func(x interface{}) {
+		if !trace.IsEnabled() {
+			f(x)
+			return
+		}
+
+		defer trace.StartRegion(t.context(d.Context()), "ds handler").End()
+		f(x)
+	}
+}
@@ -0,0 +1,38 @@
+package ds
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	handled := make(chan interface{})
+	orders := make(chan interface{})
+	traced := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Name:    "orders",
+		Channel: orders,
+		Handler: HandlerEntry{Func: func(i interface{}) { handled <- i }},
+	}})
+	tracedReady := make(chan interface{})
+	go traced.Forever(tracedReady)
+	<-tracedReady
+
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("Tracing unavailable: %v", err)
+	}
+
+	orders <- 1
+	<-handled
+
+	traced.Kill()
+	traced.Wait()
+	trace.Stop()
+
+	for _, name := range []string{"ds entry orders", "ds handler"} {
+		if !bytes.Contains(buf.Bytes(), []byte(name)) {
+			t.Errorf("Expected the trace to hold %q", name)
+		}
+	}
+}
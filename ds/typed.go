@@ -0,0 +1,157 @@
+package ds
+
+import (
+	"fmt"
+)
+
+// TypedHandlerEntry is the well-typed counterpart of HandlerEntry, see HandlerEntry for the
+// meaning of Blocking, Priority and Level.
+type TypedHandlerEntry[T any] struct {
+	Func     func(x T)
+	Blocking bool
+	Priority bool
+	Level    int
+}
+
+// typedSource is implemented by the typed half of entries built with Typed.
+type typedSource interface {
+	// listen proxies the typed channel for the entry at index i until it closes or the select halts,
+	// reporting whether the channel closed.
+	listen(d *DynamicSelect, i int, e ChannelEntry) bool
+
+	// handleNext calls the handler with the oldest message forwarded by listen.
+	handleNext()
+
+	// discardNext drops the oldest message forwarded by listen.
+	discardNext()
+
+	// send passes x, which must be of the entry's type, to the typed channel unless done or stop
+	// close first, reporting whether it was sent.
+	send(x interface{}, done chan interface{}, stop chan struct{}) (bool, error)
+
+	// route passes x towards the handler as if listen had heard it, reporting false if x
+	// is not of the entry's type or the select halted.
+	route(d *DynamicSelect, i int, e ChannelEntry, x interface{}) bool
+}
+
+// Typed builds a ChannelEntry around a well-typed channel and handler.
+// Messages heard on c by a Blocking entry are handed to the handler through a typed internal
+// queue rather than being boxed into an interface, so it allocates nothing per message. Those of a
+// non-Blocking entry are boxed on their way to the dispatcher, as an untyped entry's are.
+// The returned entry's Handler.Func accepts an interface and asserts it to T, for callers that
+// invoke it directly. A nil handler Func discards messages.
+func Typed[T any](c chan T, handler TypedHandlerEntry[T], onClose OnCloseEntry) ChannelEntry {
+	if handler.Func == nil {
+		handler.Func = func(x T) {}
+	}
+
+	t := &typedChannel[T]{
+		channel: c,
+		handler: handler.Func,
+		queue:   make(chan T, 1),
+	}
+
+	return ChannelEntry{
+		Handler: HandlerEntry{
+			Func: func(i interface{}) {
+				handler.Func(i.(T))
+			},
+			Blocking: handler.Blocking,
+			Priority: handler.Priority,
+			Level:    handler.Level,
+		},
+		OnClose: onClose,
+		typed:   t,
+	}
+}
+
+type typedChannel[T any] struct {
+	channel chan T
+	handler func(x T)
+
+	// queue holds the message a forwarded wrapper refers to, the wrapper itself carries no target.
+	queue chan T
+}
+
+func (t *typedChannel[T]) listen(d *DynamicSelect, i int, e ChannelEntry) bool {
+	for {
+		if !d.IsAlive() {
+			return false
+		}
+
+		select {
+		case <-d.done:
+			return false
+
+		case <-e.stop:
+			return false
+
+		case x, ok := <-t.channel:
+			if !ok {
+				return true
+			}
+
+			if !t.forward(d, i, e, x) {
+				return false
+			}
+		}
+	}
+}
+
+// forward passes x towards the handler, reporting false if the select halted.
+func (t *typedChannel[T]) forward(d *DynamicSelect, i int, e ChannelEntry, x T) bool {
+	e = e.current()
+	e.stats.hear(d.clock.Now())
+
+	if !e.Handler.Blocking {
+		if !d.acquireSlot(e) {
+			return false
+		}
+
+		d.dispatch.submit(d.job(e, x))
+		return true
+	}
+
+	// Queue first, so the message is waiting by the time the main loop hears the wrapper.
+	select {
+	case t.queue <- x:
+	case <-d.done:
+		return false
+	}
+
+	d.forward(i, e, nil)
+	return true
+}
+
+func (t *typedChannel[T]) route(d *DynamicSelect, i int, e ChannelEntry, x interface{}) bool {
+	v, ok := x.(T)
+	if !ok {
+		return false
+	}
+
+	return t.forward(d, i, e, v)
+}
+
+func (t *typedChannel[T]) handleNext() {
+	t.handler(<-t.queue)
+}
+
+func (t *typedChannel[T]) discardNext() {
+	<-t.queue
+}
+
+func (t *typedChannel[T]) send(x interface{}, done chan interface{}, stop chan struct{}) (bool, error) {
+	v, ok := x.(T)
+	if !ok {
+		return false, fmt.Errorf("a %T can't be sent to an entry of %T", x, v)
+	}
+
+	select {
+	case t.channel <- v:
+		return true, nil
+	case <-done:
+		return false, nil
+	case <-stop:
+		return false, nil
+	}
+}
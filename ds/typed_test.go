@@ -0,0 +1,124 @@
+package ds
+
+import (
+	"testing"
+	"time"
+)
+
+type typedPayload struct {
+	A, B int64
+}
+
+func TestTyped(t *testing.T) {
+	heard := make(chan int, 10)
+	closed := make(chan struct{})
+
+	c := make(chan int, 10)
+	entry := Typed(c, TypedHandlerEntry[int]{
+		Func: func(x int) {
+			heard <- x
+		},
+		Blocking: true,
+	}, OnCloseEntry{
+		Func: func() {
+			close(closed)
+		},
+		Blocking: true,
+	})
+
+	typedReady := make(chan interface{})
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{entry})
+	go selectMgr.Forever(typedReady)
+	<-typedReady
+	defer selectMgr.Kill()
+
+	for i := 0; i < 10; i++ {
+		c <- i
+	}
+	close(c)
+
+	for i := 0; i < 10; i++ {
+		select {
+		case v := <-heard:
+			if v != i {
+				t.Errorf("Typed messages were heard out of order, %d was heard %d", v, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 10 typed messages to be heard, heard %d", i)
+		}
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("Typed entry did not call OnClose.")
+	}
+
+	for k := 0; k < 100 && !selectMgr.Channels()[0].IsClosed; k++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if !selectMgr.Channels()[0].IsClosed {
+		t.Errorf("Typed entry was not reported closed.")
+	}
+}
+
+func BenchmarkLegacyPayload(b *testing.B) {
+	handled := make(chan struct{})
+	c := make(chan interface{})
+	entry := ChannelEntry{
+		Channel: c,
+		Handler: HandlerEntry{
+			Func: func(i interface{}) {
+				handled <- struct{}{}
+			},
+			Blocking: true,
+		},
+		OnClose: OnCloseEntry{
+			Func: func() {},
+		},
+	}
+
+	benchReady := make(chan interface{})
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{entry})
+	go selectMgr.Forever(benchReady)
+	<-benchReady
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c <- typedPayload{A: int64(i), B: int64(i)}
+		<-handled
+	}
+	b.StopTimer()
+
+	selectMgr.Kill()
+}
+
+func BenchmarkTypedPayload(b *testing.B) {
+	handled := make(chan struct{})
+	c := make(chan typedPayload)
+	entry := Typed(c, TypedHandlerEntry[typedPayload]{
+		Func: func(x typedPayload) {
+			handled <- struct{}{}
+		},
+		Blocking: true,
+	}, OnCloseEntry{
+		Func: func() {},
+	})
+
+	benchReady := make(chan interface{})
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{entry})
+	go selectMgr.Forever(benchReady)
+	<-benchReady
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c <- typedPayload{A: int64(i), B: int64(i)}
+		<-handled
+	}
+	b.StopTimer()
+
+	selectMgr.Kill()
+}
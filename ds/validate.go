@@ -0,0 +1,187 @@
+package ds
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EntryError describes a problem with one ChannelEntry.
+type EntryError struct {
+	// Index of the entry within the slice validated.
+	Index int
+
+	// Field is the offending field, e.g. "Handler.Func".
+	Field string
+
+	Problem string
+}
+
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("entry %d: %s: %s", e.Index, e.Field, e.Problem)
+}
+
+// ValidationError collects every EntryError found in a set of entries.
+type ValidationError struct {
+	Entries []*EntryError
+}
+
+func (v *ValidationError) Error() string {
+	msgs := make([]string, len(v.Entries))
+	for i, e := range v.Entries {
+		msgs[i] = e.Error()
+	}
+
+	return fmt.Sprintf("%d invalid ChannelEntry field(s): %s", len(v.Entries), strings.Join(msgs, "; "))
+}
+
+// Unwrap exposes each EntryError to errors.As.
+func (v *ValidationError) Unwrap() []error {
+	errs := make([]error, len(v.Entries))
+	for i, e := range v.Entries {
+		errs[i] = e
+	}
+
+	return errs
+}
+
+// Validate reports every problem with the entry as a *ValidationError, or nil if there are none.
+// It is stricter than the select itself: a nil Handler.Func is tolerated at runtime but almost
+// always a mistake.
+func (e ChannelEntry) Validate() error {
+	return ValidateEntries([]ChannelEntry{e})
+}
+
+// ValidateEntries validates each entry, see ChannelEntry.Validate, and that no two share a Name,
+// returning every problem found as a *ValidationError, or nil if there are none.
+func ValidateEntries(entries []ChannelEntry) error {
+	v := &ValidationError{}
+	names := map[string]int{}
+	for i, e := range entries {
+		v.Entries = append(v.Entries, e.problems(i)...)
+
+		if e.Name == "" {
+			continue
+		}
+
+		if first, dup := names[e.Name]; dup {
+			v.Entries = append(v.Entries, &EntryError{Index: i, Field: "Name", Problem: fmt.Sprintf("duplicates the name of entry %d", first)})
+			continue
+		}
+		names[e.Name] = i
+	}
+
+	if len(v.Entries) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// checkLoadable reports, as a *ValidationError, only the problems the select cannot run with:
+// a nil Channel, or two entries sharing a Name. The rest of ValidateEntries' problems are advice,
+// the select runs such entries as documented, so Load leaves them to Validate.
+func checkLoadable(entries []ChannelEntry) error {
+	v := &ValidationError{}
+	names := map[string]int{}
+	for i, e := range entries {
+		if e.Channel == nil && e.typed == nil {
+			v.Entries = append(v.Entries, &EntryError{Index: i, Field: "Channel", Problem: "is nil, its listener would block forever"})
+		}
+
+		if e.Name == "" {
+			continue
+		}
+
+		if first, dup := names[e.Name]; dup {
+			v.Entries = append(v.Entries, &EntryError{Index: i, Field: "Name", Problem: fmt.Sprintf("duplicates the name of entry %d", first)})
+			continue
+		}
+		names[e.Name] = i
+	}
+
+	if len(v.Entries) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+func (e ChannelEntry) problems(i int) []*EntryError {
+	found := []*EntryError{}
+
+	if e.Channel == nil && e.typed == nil {
+		found = append(found, &EntryError{Index: i, Field: "Channel", Problem: "is nil, its listener would block forever"})
+	}
+
+	if e.Handler.Func == nil && e.Handler.FuncContext == nil && e.Handler.FuncErr == nil && e.Handler.FuncOut == nil {
+		found = append(found, &EntryError{Index: i, Field: "Handler.Func", Problem: "is nil, messages would be discarded"})
+	}
+
+	if e.Handler.Output != nil && e.Handler.FuncOut == nil {
+		found = append(found, &EntryError{Index: i, Field: "Handler.Output", Problem: "has no effect without Handler.FuncOut"})
+	}
+
+	if e.Handler.Priority && !e.Handler.Blocking {
+		found = append(found, &EntryError{Index: i, Field: "Handler.Priority", Problem: "has no effect unless Handler.Blocking is set"})
+	}
+
+	if e.batch != nil && e.batch.size < 1 && e.batch.interval <= 0 {
+		found = append(found, &EntryError{Index: i, Field: "Batch", Problem: "has neither a Size nor a FlushInterval, its batch would never be delivered"})
+	}
+
+	if e.Reopen != nil && (e.typed != nil || e.batch != nil) {
+		found = append(found, &EntryError{Index: i, Field: "Reopen", Problem: "has no effect on entries built by Typed or Batched"})
+	}
+
+	if (e.SampleEvery > 1 || e.SampleRate > 0) && (e.typed != nil || e.batch != nil) {
+		found = append(found, &EntryError{Index: i, Field: "SampleEvery", Problem: "has no effect on entries built by Typed or Batched"})
+	}
+
+	if e.SampleEvery < 0 {
+		found = append(found, &EntryError{Index: i, Field: "SampleEvery", Problem: "is negative"})
+	}
+
+	if e.SampleRate < 0 || e.SampleRate > 1 {
+		found = append(found, &EntryError{Index: i, Field: "SampleRate", Problem: "is not a fraction between 0 and 1"})
+	} else if e.SampleRate > 0 && e.SampleEvery > 1 {
+		found = append(found, &EntryError{Index: i, Field: "SampleRate", Problem: "is set along with SampleEvery, only SampleEvery applies"})
+	}
+
+	if e.DedupeKey != nil && (e.typed != nil || e.batch != nil) {
+		found = append(found, &EntryError{Index: i, Field: "DedupeKey", Problem: "has no effect on entries built by Typed or Batched"})
+	}
+
+	if e.DedupeKey != nil && e.DedupeTTL <= 0 {
+		found = append(found, &EntryError{Index: i, Field: "DedupeTTL", Problem: "is not positive, no repeat would be suppressed"})
+	}
+
+	if e.Spill != nil && (e.typed != nil || e.batch != nil) {
+		found = append(found, &EntryError{Index: i, Field: "Spill", Problem: "has no effect on entries built by Typed or Batched"})
+	} else if e.Spill != nil && e.Spill.Threshold < 0 {
+		found = append(found, &EntryError{Index: i, Field: "Spill.Threshold", Problem: "is negative"})
+	}
+
+	if e.Journal < 0 {
+		found = append(found, &EntryError{Index: i, Field: "Journal", Problem: "is negative"})
+	} else if e.Journal > 0 && e.typed != nil {
+		found = append(found, &EntryError{Index: i, Field: "Journal", Problem: "has no effect on entries built by Typed"})
+	}
+
+	if e.Transform != nil && e.typed != nil {
+		found = append(found, &EntryError{Index: i, Field: "Transform", Problem: "has no effect on entries built by Typed"})
+	}
+
+	if e.Handler.MaxConcurrency < 0 {
+		found = append(found, &EntryError{Index: i, Field: "Handler.MaxConcurrency", Problem: "is negative"})
+	} else if e.Handler.MaxConcurrency > 0 && e.Handler.Blocking {
+		found = append(found, &EntryError{Index: i, Field: "Handler.MaxConcurrency", Problem: "has no effect on a Blocking handler, its calls never overlap"})
+	}
+
+	if e.Handler.Level < 0 {
+		found = append(found, &EntryError{Index: i, Field: "Handler.Level", Problem: "is negative"})
+	} else if e.Handler.Level > 0 && !e.Handler.Blocking {
+		found = append(found, &EntryError{Index: i, Field: "Handler.Level", Problem: "has no effect unless Handler.Blocking is set"})
+	}
+
+	return found
+}
@@ -0,0 +1,98 @@
+package ds
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	defer reset()
+
+	if err := lesserChannel.Validate(); err != nil {
+		t.Errorf("A complete entry failed validation: %s", err.Error())
+	}
+
+	bad := []ChannelEntry{
+		lesserChannel,
+		{},
+		{
+			Channel: make(chan interface{}),
+			Handler: HandlerEntry{
+				Func:     func(i interface{}) {},
+				Priority: true,
+			},
+		},
+	}
+
+	err := ValidateEntries(bad)
+	if err == nil {
+		t.Fatalf("Invalid entries passed validation.")
+	}
+
+	var v *ValidationError
+	if !errors.As(err, &v) {
+		t.Fatalf("Expected a *ValidationError, found %T", err)
+	}
+
+	expected := map[string]int{
+		"Channel":          1,
+		"Handler.Func":     1,
+		"Handler.Priority": 2,
+	}
+
+	if len(v.Entries) != len(expected) {
+		t.Errorf("Expected %d problems, found %d: %s", len(expected), len(v.Entries), err.Error())
+	}
+
+	for _, e := range v.Entries {
+		if index, ok := expected[e.Field]; !ok || index != e.Index {
+			t.Errorf("Unexpected problem: %s", e.Error())
+		}
+	}
+
+	var entryErr *EntryError
+	if !errors.As(err, &entryErr) {
+		t.Errorf("Expected an *EntryError to be unwrapped.")
+	}
+
+	if _, err := NewValidatedDynamicSelect(func() {}, bad); err == nil {
+		t.Errorf("Validated constructor accepted invalid entries.")
+	}
+
+	selectMgr := NewDynamicSelect(func() {}, []ChannelEntry{lesserChannel})
+	go selectMgr.Forever(ready)
+	<-ready
+
+	if _, err := selectMgr.Load(bad); err == nil {
+		t.Errorf("Load accepted invalid entries.")
+	}
+
+	if len(selectMgr.Channels()) != 1 {
+		t.Errorf("Invalid entries were loaded.")
+	}
+
+	// Only advised against, a nil Func discards messages and Priority falls back to non-blocking.
+	if _, err := selectMgr.Load(bad[2:]); err != nil {
+		t.Errorf("Load rejected an entry the select can run: %s", err.Error())
+	}
+
+	if _, err := selectMgr.Load([]ChannelEntry{{Channel: make(chan interface{})}}); err != nil {
+		t.Errorf("Load rejected an entry without a handler: %s", err.Error())
+	}
+
+	selectMgr.Kill()
+}
+
+func TestValidateDuplicateNames(t *testing.T) {
+	h := HandlerEntry{Func: func(i interface{}) {}}
+	err := ValidateEntries([]ChannelEntry{
+		{Name: "a", Channel: make(chan interface{}), Handler: h},
+		{Name: "b", Channel: make(chan interface{}), Handler: h},
+		{Name: "a", Channel: make(chan interface{}), Handler: h},
+	})
+
+	var entryErr *EntryError
+	if !errors.As(err, &entryErr) || entryErr.Index != 2 || entryErr.Field != "Name" {
+		t.Errorf("Expected a duplicate name at entry 2, found: %v", err)
+	}
+}
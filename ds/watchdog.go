@@ -0,0 +1,57 @@
+package ds
+
+import (
+	"runtime"
+	"time"
+)
+
+// SlowHandler describes a Blocking handler that has held up the main loop past the watchdog's
+// threshold, see WithSlowHandlerWatchdog.
+type SlowHandler struct {
+	// Index of the entry, as in Channels, and its Name if it has one.
+	Index int
+	Name  string
+
+	// Elapsed is how long the handler had run when it was flagged, it may still be running.
+	Elapsed time.Duration
+
+	// Stack holds the stacks of every goroutine when the handler was flagged, if asked for.
+	Stack []byte
+}
+
+// watchdog flags Blocking handlers running past threshold.
+type watchdog struct {
+	threshold time.Duration
+	stack     bool
+	f         func(slow SlowHandler)
+}
+
+// watchSlow starts watching the Blocking handler of entry i, returning the func that stops watching
+// once it returns.
+func (d *DynamicSelect) watchSlow(i int, e ChannelEntry) func() bool {
+	w, start := d.watchdog, d.clock.Now()
+	timer := d.clock.AfterFunc(w.threshold, func() {
+		slow := SlowHandler{Index: i, Name: e.Name, Elapsed: d.clock.Now().Sub(start)}
+		if w.stack {
+			slow.Stack = allStacks()
+		}
+
+		d.emit(EventHandlerSlow, i, e.Name, nil)
+		w.f(slow)
+	})
+
+	return timer.Stop
+}
+
+// allStacks formats the stacks of every goroutine, growing the buffer until they fit.
+func allStacks() []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+
+		buf = make([]byte, len(buf)*2)
+	}
+}
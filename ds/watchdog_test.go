@@ -0,0 +1,45 @@
+package ds
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowHandlerWatchdog(t *testing.T) {
+	flagged := make(chan SlowHandler, 4)
+	c := make(chan interface{})
+
+	watched := NewDynamicSelect(func() {}, []ChannelEntry{{
+		Name:    "slow",
+		Channel: c,
+		Handler: HandlerEntry{Func: func(i interface{}) {
+			time.Sleep(i.(time.Duration))
+		}, Blocking: true},
+	}}, WithSlowHandlerWatchdog(time.Second/20, true, func(slow SlowHandler) { flagged <- slow }))
+	watchedReady := make(chan interface{})
+	go watched.Forever(watchedReady)
+	<-watchedReady
+	defer watched.Kill()
+
+	c <- time.Millisecond
+	c <- time.Second / 5
+
+	select {
+	case slow := <-flagged:
+		if slow.Name != "slow" || slow.Elapsed < time.Second/20 {
+			t.Errorf("Expected the slow handler to be flagged past the threshold, got %s after %s", slow.Name, slow.Elapsed)
+		}
+
+		if !strings.Contains(string(slow.Stack), "TestSlowHandlerWatchdog") {
+			t.Errorf("Expected the stacks of every goroutine")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the slow handler to be flagged")
+	}
+
+	time.Sleep(time.Second / 5)
+	if len(flagged) > 0 {
+		t.Errorf("Expected only the slow message to be flagged")
+	}
+}
@@ -0,0 +1,260 @@
+package dssim
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/krhoda/goconquer/ds"
+)
+
+// Config describes a generated workload: Steps random loads, sends, channel closes and named kills,
+// chosen from Seed, against a DynamicSelect built with Options, which is then killed.
+type Config struct {
+	Seed    uint64
+	Steps   int
+	Options []ds.Option
+
+	// Entries is how many entries the select starts with, 4 if not positive.
+	Entries int
+
+	// SendTimeout bounds how long a send may wait on an entry before it counts as stalled,
+	// a second if not positive.
+	SendTimeout time.Duration
+}
+
+// Report is the outcome of a Run. Violations lists every invariant broken, see Run.
+type Report struct {
+	Seed uint64
+
+	Loaded  int
+	Sent    int
+	Handled int
+	Closed  int
+
+	Violations []error
+}
+
+// Err joins the violations of the run, nil if there were none.
+func (r Report) Err() error {
+	if len(r.Violations) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("seed %d: %w", r.Seed, errors.Join(r.Violations...))
+}
+
+// Run drives a DynamicSelect through the workload of c and checks the guarantees its documentation
+// gives: every message handled was sent, and is handled at most once; no handler runs once Wait
+// has returned; every entry's OnClose is called exactly once; and sends never stall while the
+// entry is listened to. The same Config generates the same workload.
+func Run(c Config) Report {
+	if c.Entries <= 0 {
+		c.Entries = 4
+	}
+	if c.SendTimeout <= 0 {
+		c.SendTimeout = time.Second
+	}
+
+	s := &sim{
+		rnd:     rand.New(rand.NewPCG(c.Seed, c.Seed)),
+		timeout: c.SendTimeout,
+		sent:    map[int]bool{},
+		handled: map[int]bool{},
+		closes:  map[int]int{},
+		report:  Report{Seed: c.Seed},
+	}
+
+	initial := make([]ds.ChannelEntry, c.Entries)
+	for k := range initial {
+		initial[k] = s.entry()
+	}
+
+	s.d = ds.NewDynamicSelect(func() {}, initial, c.Options...)
+	ready := make(chan interface{})
+	go s.d.Forever(ready)
+	<-ready
+
+	for step := 0; step < c.Steps; step++ {
+		s.step()
+	}
+
+	s.d.Kill()
+	s.d.Wait()
+
+	s.mu.Lock()
+	s.waited = true
+	s.mu.Unlock()
+
+	// Anything sent now must go unhandled.
+	for _, e := range s.open {
+		select {
+		case e.c <- -1:
+		default:
+		}
+	}
+	time.Sleep(c.SendTimeout / 10)
+
+	return s.finish()
+}
+
+// simEntry is an entry of the simulation, its channel still open while it is in sim.open.
+type simEntry struct {
+	id   int
+	name string
+	c    chan interface{}
+}
+
+type sim struct {
+	d       *ds.DynamicSelect
+	rnd     *rand.Rand
+	timeout time.Duration
+
+	// open holds the entries whose channels the simulation has not closed, nor killed.
+	open    []*simEntry
+	lastID  int
+	lastMsg int
+
+	mu      sync.Mutex
+	waited  bool
+	sent    map[int]bool
+	handled map[int]bool
+	closes  map[int]int
+	report  Report
+}
+
+// entry builds a new entry with a random routing, recording its OnClose calls.
+func (s *sim) entry() ds.ChannelEntry {
+	s.lastID++
+	e := &simEntry{id: s.lastID, c: make(chan interface{}, 4)}
+	if s.rnd.IntN(2) == 0 {
+		e.name = fmt.Sprintf("entry-%d", e.id)
+	}
+	s.open = append(s.open, e)
+
+	s.mu.Lock()
+	s.closes[e.id] = 0
+	s.report.Loaded++
+	s.mu.Unlock()
+
+	blocking := s.rnd.IntN(2) == 0
+	return ds.ChannelEntry{
+		Name:    e.name,
+		Channel: e.c,
+		Handler: ds.HandlerEntry{
+			Func:     s.handle,
+			Blocking: blocking,
+			Priority: blocking && s.rnd.IntN(4) == 0,
+		},
+		OnClose: ds.OnCloseEntry{
+			Func:     func() { s.closed(e.id) },
+			Blocking: s.rnd.IntN(2) == 0,
+		},
+	}
+}
+
+// step takes one random action against the select.
+func (s *sim) step() {
+	roll := s.rnd.IntN(10)
+	switch {
+	case roll == 0 || len(s.open) == 0:
+		// Waiting, so the entry can be killed by name at once.
+		if _, err := s.d.LoadWait([]ds.ChannelEntry{s.entry()}); err != nil {
+			s.violate(fmt.Errorf("loading an entry: %w", err))
+		}
+	case roll == 1:
+		e := s.take()
+		close(e.c)
+	case roll == 2:
+		k := s.rnd.IntN(len(s.open))
+		if e := s.open[k]; e.name != "" {
+			s.take(k)
+			if err := s.d.KillNamed(e.name); err != nil {
+				s.violate(fmt.Errorf("killing %s: %w", e.name, err))
+			}
+		}
+	default:
+		s.send(s.open[s.rnd.IntN(len(s.open))])
+	}
+}
+
+// take removes an entry from the open ones, the one at index k if given, or a random one.
+func (s *sim) take(k ...int) *simEntry {
+	i := s.rnd.IntN(len(s.open))
+	if len(k) > 0 {
+		i = k[0]
+	}
+
+	e := s.open[i]
+	s.open = append(s.open[:i], s.open[i+1:]...)
+	return e
+}
+
+func (s *sim) send(e *simEntry) {
+	s.lastMsg++
+	msg := s.lastMsg
+
+	s.mu.Lock()
+	s.sent[msg] = true
+	s.report.Sent++
+	s.mu.Unlock()
+
+	select {
+	case e.c <- msg:
+	case <-time.After(s.timeout):
+		s.violate(fmt.Errorf("sending %d to entry %d stalled", msg, e.id))
+	}
+}
+
+func (s *sim) handle(i interface{}) {
+	msg, _ := i.(int)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.waited:
+		s.report.Violations = append(s.report.Violations, fmt.Errorf("message %v handled after Wait returned", i))
+	case !s.sent[msg]:
+		s.report.Violations = append(s.report.Violations, fmt.Errorf("message %v handled but never sent", i))
+	case s.handled[msg]:
+		s.report.Violations = append(s.report.Violations, fmt.Errorf("message %d handled twice", msg))
+	}
+
+	s.handled[msg] = true
+	s.report.Handled++
+}
+
+func (s *sim) closed(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.waited {
+		s.report.Violations = append(s.report.Violations, fmt.Errorf("entry %d closed after Wait returned", id))
+	}
+
+	s.closes[id]++
+	s.report.Closed++
+}
+
+func (s *sim) violate(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report.Violations = append(s.report.Violations, err)
+}
+
+// finish checks that every entry closed once, and returns the report.
+func (s *sim) finish() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := 1; id <= s.lastID; id++ {
+		if n := s.closes[id]; n != 1 {
+			s.report.Violations = append(s.report.Violations, fmt.Errorf("entry %d closed %d times", id, n))
+		}
+	}
+
+	return s.report
+}
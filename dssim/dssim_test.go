@@ -0,0 +1,27 @@
+package dssim
+
+import (
+	"testing"
+
+	"github.com/krhoda/goconquer/ds"
+)
+
+func TestRun(t *testing.T) {
+	for _, opts := range [][]ds.Option{
+		nil,
+		{ds.WithShards(2)},
+		{ds.WithListenerGroups(4)},
+		{ds.WithReverseClose()},
+	} {
+		for seed := uint64(1); seed <= 10; seed++ {
+			report := Run(Config{Seed: seed, Steps: 200, Options: opts})
+			if err := report.Err(); err != nil {
+				t.Error(err)
+			}
+
+			if report.Sent == 0 || report.Handled == 0 || report.Closed != report.Loaded {
+				t.Errorf("Seed %d: unexpected report %+v", seed, report)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package dstest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/krhoda/goconquer/ds"
+)
+
+// Timeout bounds how long the Await helpers wait before failing the test.
+var Timeout = 5 * time.Second
+
+// Start runs a DynamicSelect of the entries given until the test ends, returning it once it is
+// listening to them. Its onKillAction does nothing.
+func Start(t testing.TB, entries []ds.ChannelEntry, opts ...ds.Option) *ds.DynamicSelect {
+	t.Helper()
+
+	d := ds.NewDynamicSelect(func() {}, entries, opts...)
+	ready := make(chan interface{})
+	go d.Forever(ready)
+	<-ready
+
+	t.Cleanup(func() {
+		d.Kill()
+		d.Wait()
+	})
+
+	return d
+}
+
+// RecordingEntry is a fake entry recording the messages its handler is passed and whether its
+// OnClose was called. Load its Entry, send to its Channel, then wait on it with AwaitHandled and
+// AwaitClosed rather than shared booleans and sleeps.
+type RecordingEntry struct {
+	Channel chan interface{}
+
+	mu       sync.Mutex
+	messages []interface{}
+	reason   ds.CloseReason
+
+	// changed is closed, and replaced, whenever a message is recorded.
+	changed chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// NewRecordingEntry returns a RecordingEntry with an unbuffered Channel.
+func NewRecordingEntry() *RecordingEntry {
+	return &RecordingEntry{
+		Channel: make(chan interface{}),
+		changed: make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Entry returns a ChannelEntry listening to the Channel with a recording handler and OnClose.
+// Its fields may be changed before it is loaded, Blocking or Priority say, but not its handlers.
+func (r *RecordingEntry) Entry() ds.ChannelEntry {
+	return ds.ChannelEntry{
+		Channel: r.Channel,
+		Handler: ds.HandlerEntry{Func: r.record},
+		OnClose: ds.OnCloseEntry{FuncReason: r.close},
+	}
+}
+
+func (r *RecordingEntry) record(x interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, x)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func (r *RecordingEntry) close(reason ds.CloseReason) {
+	r.once.Do(func() {
+		r.mu.Lock()
+		r.reason = reason
+		r.mu.Unlock()
+		close(r.closed)
+	})
+}
+
+// Messages returns the messages handled so far, in the order handled.
+func (r *RecordingEntry) Messages() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]interface{}(nil), r.messages...)
+}
+
+// Closed returns a channel closed once the entry's OnClose has been called.
+func (r *RecordingEntry) Closed() <-chan struct{} {
+	return r.closed
+}
+
+// AwaitHandled waits until the entry has handled at least n messages, returning them all, and
+// fails the test if it does not within Timeout.
+func AwaitHandled(t testing.TB, r *RecordingEntry, n int) []interface{} {
+	t.Helper()
+
+	timeout := time.NewTimer(Timeout)
+	defer timeout.Stop()
+
+	for {
+		r.mu.Lock()
+		handled, changed := len(r.messages), r.changed
+		r.mu.Unlock()
+
+		if handled >= n {
+			return r.Messages()
+		}
+
+		select {
+		case <-changed:
+		case <-timeout.C:
+			t.Fatalf("Expected %d messages handled within %s, %d were", n, Timeout, handled)
+			return nil
+		}
+	}
+}
+
+// AwaitClosed waits until the entry's OnClose has been called, returning why, and fails the
+// test if it is not within Timeout.
+func AwaitClosed(t testing.TB, r *RecordingEntry) ds.CloseReason {
+	t.Helper()
+
+	select {
+	case <-r.closed:
+	case <-time.After(Timeout):
+		t.Fatalf("Expected the entry to close within %s", Timeout)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reason
+}
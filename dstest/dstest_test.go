@@ -0,0 +1,38 @@
+package dstest
+
+import (
+	"testing"
+
+	"github.com/krhoda/goconquer/ds"
+)
+
+func TestRecordingEntry(t *testing.T) {
+	orders, refunds := NewRecordingEntry(), NewRecordingEntry()
+
+	blocking := refunds.Entry()
+	blocking.Handler.Blocking = true
+	Start(t, []ds.ChannelEntry{orders.Entry(), blocking})
+
+	orders.Channel <- "order 1"
+	orders.Channel <- "order 2"
+	refunds.Channel <- "refund"
+
+	if handled := AwaitHandled(t, orders, 2); len(handled) != 2 {
+		t.Errorf("Expected 2 orders handled, found %v", handled)
+	}
+
+	if handled := AwaitHandled(t, refunds, 1); handled[0] != "refund" {
+		t.Errorf("Expected the refund handled, found %v", handled)
+	}
+
+	close(refunds.Channel)
+	if reason := AwaitClosed(t, refunds); reason != ds.ClosedChannel {
+		t.Errorf("Expected the refunds entry closed with its channel, found %s", reason)
+	}
+
+	select {
+	case <-orders.Closed():
+		t.Errorf("Expected the orders entry to stay open.")
+	default:
+	}
+}
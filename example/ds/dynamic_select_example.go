@@ -1,9 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/krhoda/goconquer/ds"
@@ -43,21 +43,21 @@ func main() {
 		},
 	}
 
-	sMgr := ds.NewDynamicSelect(ka, chSl)
+	done := make(chan interface{})
 
-	ready, done := make(chan interface{}), make(chan interface{})
-
-	go func() {
-		k := make(chan os.Signal, 1)
-		<-k
+	// SIGINT or SIGTERM kill the select, which stops the bots.
+	sMgr := ds.NewDynamicSelect(func() {
+		ka()
 		close(done)
-		sMgr.Kill()
-	}()
+	}, chSl, ds.WithSignalKill())
 
 	go bots.MakeStringBot(ch1, done)
 	go bots.MakeMathBot(ch2, done)
 
-	go sMgr.Forever(ready)
+	go func() {
+		err := sMgr.Run(context.Background())
+		log.Printf("Select stopped: %s\n", err)
+	}()
 
 	time.Sleep(time.Second * 5)
 	log.Println("Main thread building Rune Bot...")
@@ -76,9 +76,9 @@ func main() {
 	}
 
 	go func() {
-		err := sMgr.Load(ce3)
+		_, err := sMgr.Load([]ds.ChannelEntry{ce3})
 		if err != nil {
-			log.Println("Error in Load: %s\n", err)
+			log.Printf("Error in Load: %s\n", err)
 		}
 	}()
 
@@ -0,0 +1,90 @@
+package exbo
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/krhoda/goconquer/clock"
+)
+
+// ErrRetryBudget is returned by Wait, and so by Do wrapping the last failure, when the manager's
+// RetryBudget is spent.
+var ErrRetryBudget = errors.New("exbo retry budget exhausted, not retrying")
+
+// RetryBudget bounds how many retries may be made in a period, shared by every manager given it in
+// Opts.Budget, so a widespread outage doesn't multiply the load on a dependency through retries.
+// It is a token bucket: full to start with, and refilled steadily over each period. It keeps to
+// the Opts.Clock of the first manager given it with one, else to the time package.
+type RetryBudget struct {
+	retries float64
+	per     time.Duration
+
+	mu      sync.Mutex
+	clock   Clock
+	clocked bool
+	tokens  float64
+	last    time.Time
+}
+
+// NewRetryBudget returns a budget of retries every per.
+func NewRetryBudget(retries int, per time.Duration) *RetryBudget {
+	return &RetryBudget{
+		retries: float64(retries),
+		per:     per,
+		clock:   clock.Real{},
+		tokens:  float64(retries),
+		last:    time.Now(),
+	}
+}
+
+// use times the budget by c from now on, unless a manager's clock already does.
+func (b *RetryBudget) use(c Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.clocked {
+		return
+	}
+
+	b.clock, b.clocked = c, true
+	b.last = c.Now()
+}
+
+// take spends a retry, reporting false if there are none left.
+func (b *RetryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	if b.per > 0 {
+		b.tokens += b.retries * float64(now.Sub(b.last)) / float64(b.per)
+		if b.tokens > b.retries {
+			b.tokens = b.retries
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// Remaining returns how many retries may be made now.
+func (b *RetryBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tokens := b.tokens
+	if b.per > 0 {
+		tokens += b.retries * float64(b.clock.Now().Sub(b.last)) / float64(b.per)
+	}
+	if tokens > b.retries {
+		tokens = b.retries
+	}
+
+	return int(tokens)
+}
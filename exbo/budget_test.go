@@ -0,0 +1,78 @@
+package exbo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	budget := NewRetryBudget(3, time.Hour)
+	opts := Opts{
+		Min:          time.Microsecond,
+		Max:          time.Millisecond,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Microsecond,
+		Budget:       budget,
+	}
+
+	first := runRetry(t, opts)
+	second := runRetry(t, opts)
+
+	calls := 0
+	err := first.Do(context.Background(), func() error {
+		calls++
+		return errFlaky
+	})
+
+	if !errors.Is(err, ErrRetryBudget) || !errors.Is(err, errFlaky) {
+		t.Errorf("Expected ErrRetryBudget wrapping the last failure, found %v", err)
+	}
+
+	if calls != 4 {
+		t.Errorf("Expected the call and 3 retries, found %d calls", calls)
+	}
+
+	if err := second.Wait(); !errors.Is(err, ErrRetryBudget) {
+		t.Errorf("Expected the budget shared by both managers, found %v", err)
+	}
+
+	if budget.Remaining() != 0 {
+		t.Errorf("Expected the budget spent, found %d", budget.Remaining())
+	}
+}
+
+func TestRetryBudgetRefills(t *testing.T) {
+	budget := NewRetryBudget(2, 10*time.Millisecond)
+	if !budget.take() || !budget.take() {
+		t.Fatalf("Expected a full budget to start with")
+	}
+
+	if budget.take() {
+		t.Errorf("Expected the budget spent")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if budget.Remaining() != 2 {
+		t.Errorf("Expected the budget refilled, no fuller than it began, found %d", budget.Remaining())
+	}
+}
+
+func TestRetryBudgetClock(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	budget := NewRetryBudget(2, time.Hour)
+
+	if _, err := NewExpoBackoffManager(Opts{Min: time.Millisecond, Max: time.Second, Budget: budget, Clock: clock}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !budget.take() || !budget.take() || budget.take() {
+		t.Fatalf("Expected a budget of 2 retries")
+	}
+
+	clock.Advance(time.Hour / 2)
+	if budget.Remaining() != 1 {
+		t.Errorf("Expected half the budget refilled by the manager's clock, found %d", budget.Remaining())
+	}
+}
@@ -0,0 +1,27 @@
+package exbo
+
+import (
+	"time"
+
+	"github.com/krhoda/goconquer/clock"
+)
+
+// Clock is the source of time for a manager's backoff sleeps, cooldown ticks, the times of its
+// State and the refills of its RetryBudget. See Opts.Clock, and ManualClock for tests.
+// Any clock.Clock is one, a ds.Clock among them.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a time.Timer made by a Clock.
+type Timer = clock.Timer
+
+// ManualClock is a Clock whose time only moves when Advance is called, firing the timers due on
+// the way in order, so tests of retry logic need not sleep.
+type ManualClock = clock.Manual
+
+// NewManualClock returns a ManualClock reading start.
+func NewManualClock(start time.Time) *ManualClock {
+	return clock.NewManual(start)
+}
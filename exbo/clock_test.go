@@ -0,0 +1,51 @@
+package exbo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockSleeps(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          time.Hour,
+		Max:          time.Hour,
+		CooldownTick: 2 * time.Hour,
+		CooldownSize: time.Second,
+		Clock:        clock,
+	})
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+	defer ex.Stop()
+
+	waited := make(chan error)
+	go func() { waited <- ex.Wait() }()
+
+	// The cooldown timer, and the sleep.
+	for clock.Waiters() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	clock.Advance(time.Hour)
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Errorf("Unexpected error in Wait: %s", err.Error())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected an hour's backoff to pass with the clock")
+	}
+
+	if ex.Allow() {
+		t.Errorf("Expected no attempt allowed within the backoff")
+	}
+
+	clock.Advance(time.Hour)
+	if !ex.Allow() {
+		t.Errorf("Expected an attempt allowed once the clock passed the backoff")
+	}
+}
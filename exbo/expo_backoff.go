@@ -1,155 +1,576 @@
 package exbo
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/krhoda/goconquer/clock"
 )
 
+// ErrHalted is returned by Wait once the manager has been stopped, rather than after a backoff.
+var ErrHalted = errors.New("ebm recieved a kill command from the calling application, this is not the timeout returning")
+
+// ErrAlreadyRun is returned by Run when it has been called on the manager before.
+var ErrAlreadyRun = errors.New("ebm Run was called more than once, the first call runs the manager")
+
+// ErrIncoherentOpts is returned by NewExpoBackoffManager when Min is greater than Max.
+var ErrIncoherentOpts = errors.New("Incoherent args, Min was greater than Max")
+
+// ErrIncoherentGrowth is returned by NewExpoBackoffManager when Factor or Step would shrink the
+// backoff.
+var ErrIncoherentGrowth = errors.New("Incoherent args, Factor was below 1 or Step was negative")
+
 type Opts struct {
 	Min          time.Duration
 	Max          time.Duration
 	CooldownTick time.Duration
 	CooldownSize time.Duration
+
+	// Factor multiplies the backoff on each Wait, 2 if zero. A Factor of 1 with a Step grows it
+	// linearly.
+	Factor float64
+
+	// Step is added to the backoff on each Wait, after multiplying it by Factor.
+	Step time.Duration
+
+	// HalfLife, if set, cools the backoff down by decay rather than by CooldownSize: each
+	// CooldownTick its excess over Min shrinks as it would halving every HalfLife, reaching Min
+	// once within CooldownSize of it. After a long outage this recovers quickly from a large Max,
+	// and gently near Min.
+	HalfLife time.Duration
+
+	// StateFile, if set, is where the manager keeps its backoff, attempts and their times, loaded
+	// on construction and written on every change, so a CLI or cron job restarting between
+	// attempts carries on backing off. NewKeyedBackoff ignores it.
+	StateFile string
+
+	// Clock, if set, times the manager in place of the time package, a ManualClock in tests say.
+	Clock Clock
+
+	// Idle, if set, cools the backoff down on quiet rather than on a CooldownTick: once no
+	// attempt has been made for Idle it is reset to Min, while an actively failing manager stays
+	// where it is.
+	Idle time.Duration
+
+	// Outcomes leaves the backoff alone on Wait, growing it only on Failure and resetting it on
+	// Success, so a retry loop that succeeds is not pushed to Max.
+	Outcomes bool
+
+	// MaxAttempts bounds how many times Do calls its function, without bound if not positive.
+	MaxAttempts int
+
+	// Budget, if set, bounds the retries made through Wait, and so Do, past which they return
+	// ErrRetryBudget rather than sleeping. One budget may be shared by many managers, it keeps
+	// to the Clock of the first given it with one.
+	Budget *RetryBudget
+
+	// MaxKeys bounds how many keys a KeyedBackoff keeps, 1024 if not positive.
+	MaxKeys int
+
+	// OnChange, if set, is called whenever the backoff changes, with why, to log or alert on a
+	// dependency pushing it to Max say. It is called from the goroutine making the change, which
+	// it holds up.
+	OnChange func(old, new time.Duration, reason Reason)
+}
+
+// Reason is why the backoff changed, as passed to Opts.OnChange.
+type Reason int
+
+const (
+	// ReasonGrowth is a Wait, or a Failure, growing the backoff.
+	ReasonGrowth Reason = iota
+	// ReasonCooldown is the backoff cooling down by CooldownSize or HalfLife, or after Idle.
+	ReasonCooldown
+	// ReasonReset is a Success resetting the backoff to Min.
+	ReasonReset
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ReasonGrowth:
+		return "growth"
+	case ReasonCooldown:
+		return "cooldown"
+	case ReasonReset:
+		return "reset"
+	default:
+		return "unknown"
+	}
 }
 
 type ExpoBackoffManager struct {
 	Ready          chan struct{}
-	alive          bool
-	startReq       chan chan struct{}
+	alive          atomic.Bool
+	started        atomic.Bool
+	startReq       chan sleepReq
 	backoffGuard   chan struct{}
 	currentBackOff time.Duration
+	attempts       int // Guarded by backoffGuard, as are changedAt and lastAttempt.
+	changedAt      time.Time
+	lastAttempt    time.Time // When the last attempt proceeded, its backoff waited out.
 	maxBackOff     time.Duration
 	minBackOff     time.Duration
 	cooldownTick   time.Duration
 	cooldownSize   time.Duration
+	factor         float64
+	step           time.Duration
+	outcomes       bool
+	idle           time.Duration
+	clock          Clock
+	stateFile      string
+	persistMu      sync.Mutex // Orders writes of the state file.
+	decay          float64    // Of the excess over min each cooldown, 0 without a HalfLife.
+	maxAttempts    int
+	budget         *RetryBudget
+	onChange       func(old, new time.Duration, reason Reason)
 	firstReq       bool
 	cooldown       chan struct{}
 	done           chan struct{} // Kill Run.
 	kill           chan struct{} // Kill Routines.
+	stopOnce       sync.Once
+
+	// waitMu guards waiters, the Waits in flight, and stopped. released is closed once the
+	// manager is stopped and every Wait has returned.
+	waitMu   sync.Mutex
+	waiters  int
+	stopped  bool
+	released chan struct{}
 }
 
 func NewExpoBackoffManager(opts Opts) (ex *ExpoBackoffManager, err error) {
 	if opts.Min > opts.Max {
-		err = fmt.Errorf("Incoherent args, Min was greater than Max")
+		err = ErrIncoherentOpts
+		return
+	}
+
+	if opts.Factor == 0 {
+		opts.Factor = 2
+	}
+
+	if opts.Factor < 1 || opts.Step < 0 {
+		err = ErrIncoherentGrowth
 		return
 	}
 
+	c := opts.Clock
+	if c == nil {
+		c = clock.Real{}
+	} else if opts.Budget != nil {
+		opts.Budget.use(c)
+	}
+
 	bg := make(chan struct{}, 1)
-	r := make(chan struct{}, 1)
+	r := make(chan struct{})
 
 	bg <- struct{}{}
 
 	ex = &ExpoBackoffManager{
 		Ready:          r,
-		alive:          true,
-		startReq:       make(chan chan struct{}),
+		startReq:       make(chan sleepReq),
 		backoffGuard:   bg,
 		currentBackOff: opts.Min,
+		changedAt:      c.Now(),
+		clock:          c,
+		stateFile:      opts.StateFile,
 		minBackOff:     opts.Min,
 		maxBackOff:     opts.Max,
 		cooldownTick:   opts.CooldownTick,
 		cooldownSize:   opts.CooldownSize,
+		factor:         opts.Factor,
+		step:           opts.Step,
+		outcomes:       opts.Outcomes,
+		idle:           opts.Idle,
+		decay:          decay(opts.CooldownTick, opts.HalfLife),
+		maxAttempts:    opts.MaxAttempts,
+		budget:         opts.Budget,
+		onChange:       opts.OnChange,
 		firstReq:       true,
 		cooldown:       make(chan struct{}),
 		done:           make(chan struct{}),
 		kill:           make(chan struct{}),
+		released:       make(chan struct{}),
+	}
+
+	ex.alive.Store(true)
+
+	if err = ex.restore(); err != nil {
+		ex = nil
 	}
 
 	return
 }
 
-func (ebm *ExpoBackoffManager) Run() {
-	ebm.alive = true
+// Run runs the manager until it is stopped, closing Ready once it is, so any number of observers
+// may wait on it. It returns why it exited, ErrHalted once stopped, or ErrAlreadyRun, at once, if
+// Run has been called before.
+func (ebm *ExpoBackoffManager) Run() error {
+	if !ebm.started.CompareAndSwap(false, true) {
+		return ErrAlreadyRun
+	}
+
+	ebm.alive.Store(true)
 
 	defer func() {
-		ebm.alive = false
+		ebm.alive.Store(false)
 	}()
 
 	go ebm.runCooldown()
 
-	ebm.Ready <- struct{}{}
+	close(ebm.Ready)
 	for {
 		select {
 		case <-ebm.done:
 			close(ebm.kill)
-			return
-		case sleepChan := <-ebm.startReq:
-			go ebm.handleSleepChan(sleepChan, ebm.kill)
+			return ErrHalted
+		case req := <-ebm.startReq:
+			go ebm.handleSleepChan(req, ebm.kill)
 		case <-ebm.cooldown:
 			if ebm.currentBackOff > ebm.minBackOff {
 				<-ebm.backoffGuard
-				ebm.currentBackOff = ebm.currentBackOff - ebm.cooldownSize
-				if ebm.currentBackOff < ebm.minBackOff {
-					ebm.currentBackOff = ebm.minBackOff
+				next := ebm.cool(ebm.currentBackOff)
+				if ebm.idle > 0 || next <= ebm.minBackOff {
+					next = ebm.minBackOff
+					ebm.attempts = 0
 				}
+				old := ebm.set(next)
 				ebm.backoffGuard <- struct{}{}
+				ebm.notify(old, next, ReasonCooldown)
 			}
 		}
 	}
 }
 
 func (ebm *ExpoBackoffManager) runCooldown() {
+	period := ebm.cooldownTick
+	if ebm.idle > 0 {
+		period = ebm.idle
+	}
+
+	tick := ebm.clock.NewTimer(period)
+	defer tick.Stop()
+
 	for {
 		select {
 		case <-ebm.done:
 			return
-		case <-time.After(ebm.cooldownTick):
+		case <-tick.C():
+			if left := ebm.idleLeft(); left > 0 {
+				tick.Reset(left)
+				continue
+			}
+
 			go func() {
 				ebm.cooldown <- struct{}{}
 			}()
+			tick.Reset(period)
+		}
+	}
+}
+
+// idleLeft returns how long until the manager has been idle for Idle, 0 if it has, or has no Idle.
+func (ebm *ExpoBackoffManager) idleLeft() time.Duration {
+	if ebm.idle <= 0 {
+		return 0
+	}
+
+	<-ebm.backoffGuard
+	defer func() { ebm.backoffGuard <- struct{}{} }()
+	return ebm.lastAttempt.Add(ebm.idle).Sub(ebm.clock.Now())
+}
+
+// sleepTimers recycles the timers used by handleSleepChan, so each Wait doesn't allocate one.
+var sleepTimers = sync.Pool{
+	New: func() interface{} {
+		t := time.NewTimer(time.Hour)
+		t.Stop()
+		return t
+	},
+}
+
+func getSleepTimer(d time.Duration) *time.Timer {
+	t := sleepTimers.Get().(*time.Timer)
+	t.Reset(d)
+	return t
+}
+
+// putSleepTimer stops t, clearing a value that fired but was never read, and returns it to the pool.
+func putSleepTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
 		}
 	}
+	sleepTimers.Put(t)
 }
 
+// Stop stops the manager, every Wait in flight, or made after, returning ErrHalted at once. It may
+// be called more than once.
 func (ebm *ExpoBackoffManager) Stop() {
-	close(ebm.done)
+	ebm.stopOnce.Do(func() {
+		ebm.waitMu.Lock()
+		ebm.stopped = true
+		if ebm.waiters == 0 {
+			close(ebm.released)
+		}
+		ebm.waitMu.Unlock()
+
+		close(ebm.done)
+	})
 }
 
-func (ebm *ExpoBackoffManager) handleSleepChan(sleepChan, kill chan struct{}) {
-	defer close(sleepChan)
+// StopAndWait stops the manager, returning once every Wait in flight has returned.
+func (ebm *ExpoBackoffManager) StopAndWait() {
+	ebm.Stop()
+	<-ebm.released
+}
 
-	<-ebm.backoffGuard
-	timeout := ebm.currentBackOff
-	ebm.currentBackOff = ebm.currentBackOff * 2
-	if ebm.currentBackOff > ebm.maxBackOff {
-		ebm.currentBackOff = ebm.maxBackOff
+// enter counts a Wait in flight, reporting false if the manager is stopped.
+func (ebm *ExpoBackoffManager) enter() bool {
+	ebm.waitMu.Lock()
+	defer ebm.waitMu.Unlock()
+
+	if ebm.stopped {
+		return false
+	}
+
+	ebm.waiters++
+	return true
+}
+
+func (ebm *ExpoBackoffManager) leave() {
+	ebm.waitMu.Lock()
+	defer ebm.waitMu.Unlock()
+
+	ebm.waiters--
+	if ebm.stopped && ebm.waiters == 0 {
+		close(ebm.released)
 	}
+}
+
+// sleepReq asks Run for a backoff, heard on sleep, which is closed without a value if the manager
+// is stopped first. The sleep is abandoned if cancel is closed, cancel may be nil.
+type sleepReq struct {
+	sleep  chan struct{}
+	cancel <-chan struct{}
+	n      int
+}
+
+func (ebm *ExpoBackoffManager) handleSleepChan(req sleepReq, kill chan struct{}) {
+	defer close(req.sleep)
+
+	<-ebm.backoffGuard
+	timeout := weigh(ebm.currentBackOff, req.n)
+	current, next := ebm.attempt(req.n, ebm.clock.Now().Add(timeout))
 	ebm.backoffGuard <- struct{}{}
+	ebm.notify(current, next, ReasonGrowth)
+
+	var slept <-chan time.Time
+	if _, real := ebm.clock.(clock.Real); real {
+		t := getSleepTimer(timeout)
+		defer putSleepTimer(t)
+		slept = t.C
+	} else {
+		t := ebm.clock.NewTimer(timeout)
+		defer t.Stop()
+		slept = t.C()
+	}
 
 	select {
 	case <-kill:
 		return
-	case <-time.After(timeout):
-		sleepChan <- struct{}{}
+	case <-req.cancel:
 		return
+	case <-slept:
+		req.sleep <- struct{}{}
+		return
+	}
+}
+
+// attempt records n attempts proceeding at at, growing the backoff for each unless driven by
+// Outcomes. It returns the backoff before and after. The caller must hold backoffGuard.
+func (ebm *ExpoBackoffManager) attempt(n int, at time.Time) (time.Duration, time.Duration) {
+	current := ebm.currentBackOff
+	ebm.attempts += n
+	ebm.lastAttempt = at
+
+	next := current
+	if !ebm.outcomes {
+		for k := 0; k < n && next < ebm.maxBackOff; k++ {
+			next = ebm.grow(next)
+		}
+		ebm.set(next)
 	}
+
+	return current, next
+}
+
+// weigh returns the sleep of n attempts at once, n times the backoff, as long as that is.
+func weigh(backoff time.Duration, n int) time.Duration {
+	if n > 1 && backoff > math.MaxInt64/time.Duration(n) {
+		return math.MaxInt64
+	}
+
+	return backoff * time.Duration(n)
+}
+
+// decay returns the share of the backoff's excess over Min left after a tick of the given
+// half-life, 0 if there is none.
+func decay(tick, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		return 0
+	}
+
+	return math.Pow(0.5, float64(tick)/float64(halfLife))
+}
+
+// cool returns the backoff following current after a cooldown tick, which may be below Min.
+func (ebm *ExpoBackoffManager) cool(current time.Duration) time.Duration {
+	if ebm.decay == 0 {
+		return current - ebm.cooldownSize
+	}
+
+	excess := time.Duration(float64(current-ebm.minBackOff) * ebm.decay)
+	if excess <= ebm.cooldownSize {
+		return ebm.minBackOff
+	}
+
+	return ebm.minBackOff + excess
+}
+
+// set changes the backoff to next, noting when if it differs, and returns what it was. The caller
+// must hold backoffGuard.
+func (ebm *ExpoBackoffManager) set(next time.Duration) time.Duration {
+	old := ebm.currentBackOff
+	if next != old {
+		ebm.currentBackOff = next
+		ebm.changedAt = ebm.clock.Now()
+	}
+	return old
+}
+
+// notify passes a change of the backoff to OnChange, if it changed and one is set, and persists
+// the manager's state. The caller must not hold backoffGuard, so OnChange may inspect the manager.
+func (ebm *ExpoBackoffManager) notify(old, next time.Duration, reason Reason) {
+	ebm.persist()
+
+	if ebm.onChange != nil && old != next {
+		ebm.onChange(old, next, reason)
+	}
+}
+
+// grow returns the backoff following current, by Factor and Step, no greater than Max.
+func (ebm *ExpoBackoffManager) grow(current time.Duration) time.Duration {
+	next := float64(current)*ebm.factor + float64(ebm.step)
+	if next >= float64(ebm.maxBackOff) {
+		return ebm.maxBackOff
+	}
+
+	return time.Duration(next)
 }
 
 func (ebm *ExpoBackoffManager) Wait() error {
-	if !ebm.alive {
-		return fmt.Errorf("ebm recieved a kill command from the calling application, this is not the timeout returning")
+	return ebm.wait(context.Background(), 1)
+}
+
+// WaitN waits as a batch of n operations, which sleeps n times the backoff and grows it as n Waits
+// would, so backoff pressure scales with the size of the batch. An n below 1 is taken as 1.
+func (ebm *ExpoBackoffManager) WaitN(n int) error {
+	return ebm.wait(context.Background(), n)
+}
+
+// wait is WaitN, returning ctx's error if it is done before the backoff is.
+func (ebm *ExpoBackoffManager) wait(ctx context.Context, n int) error {
+	if n < 1 {
+		n = 1
 	}
 
+	if !ebm.enter() {
+		return ErrHalted
+	}
+	defer ebm.leave()
+
+	if ebm.budget != nil && !ebm.budget.take() {
+		return ErrRetryBudget
+	}
+
+	x := make(chan struct{}, 1)
 	select {
-	case <-ebm.kill:
-		return fmt.Errorf("ebm recieved a kill command from the calling application, this is not the timeout returning")
+	case ebm.startReq <- sleepReq{sleep: x, cancel: ctx.Done(), n: n}:
+	case <-ebm.done:
+		return ErrHalted
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
-	default:
-		x := make(chan struct{}, 1)
-		ebm.startReq <- x
-		_, ok := <-x
+	select {
+	case _, ok := <-x:
 		if !ok {
-			return fmt.Errorf("ebm recieved a kill command from the calling application, this is not the timeout returning")
+			// Closed on cancel as well as on Stop.
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return ErrHalted
 		}
+	case <-ebm.done:
+		return ErrHalted
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
-		return nil
+	return nil
+}
+
+// Allow reports whether an attempt may be made now without waiting, the current backoff having
+// passed since the last attempt proceeded, recording one proceeding now if so. It is for callers
+// that would rather skip work than block on Wait. It reports false once the manager is stopped,
+// or the Budget spent.
+func (ebm *ExpoBackoffManager) Allow() bool {
+	select {
+	case <-ebm.done:
+		return false
+	default:
 	}
 
+	<-ebm.backoffGuard
+	now := ebm.clock.Now()
+	if now.Before(ebm.lastAttempt.Add(ebm.currentBackOff)) || (ebm.budget != nil && !ebm.budget.take()) {
+		ebm.backoffGuard <- struct{}{}
+		return false
+	}
+
+	old, next := ebm.attempt(1, now)
+	ebm.backoffGuard <- struct{}{}
+	ebm.notify(old, next, ReasonGrowth)
+
+	return true
+}
+
+// Failure records a failed attempt, growing the backoff as a Wait would without Outcomes.
+func (ebm *ExpoBackoffManager) Failure() {
+	<-ebm.backoffGuard
+	next := ebm.grow(ebm.currentBackOff)
+	old := ebm.set(next)
+	ebm.backoffGuard <- struct{}{}
+	ebm.notify(old, next, ReasonGrowth)
+}
+
+// Success records a successful attempt, resetting the backoff to Min.
+func (ebm *ExpoBackoffManager) Success() {
+	<-ebm.backoffGuard
+	old := ebm.set(ebm.minBackOff)
+	ebm.attempts = 0
+	ebm.backoffGuard <- struct{}{}
+	ebm.notify(old, ebm.minBackOff, ReasonReset)
 }
 
 // CurrentWaitTime returns the current backoff wait time, if it is minimum, and if it is maximum.
 func (ebm *ExpoBackoffManager) CurrentWaitTime() (time.Duration, bool, bool) {
-	if !ebm.alive {
+	if !ebm.alive.Load() {
 		return ebm.minBackOff, true, false
 	}
 
@@ -161,3 +582,37 @@ func (ebm *ExpoBackoffManager) CurrentWaitTime() (time.Duration, bool, bool) {
 
 	return current, isMin, isMax
 }
+
+// State is a snapshot of the manager's backoff, see ExpoBackoffManager.State.
+type State struct {
+	// Current is the time the next Wait will sleep.
+	Current time.Duration
+	IsMin   bool
+	IsMax   bool
+
+	// Attempts counts the Waits since the backoff was last reset, by Success or by cooling down
+	// to Min.
+	Attempts int
+
+	// Changed is when Current last changed, or the manager was made if it never has.
+	Changed time.Time
+}
+
+// State returns the manager's backoff, with how many attempts have been made since it was reset.
+func (ebm *ExpoBackoffManager) State() State {
+	<-ebm.backoffGuard
+	defer func() { ebm.backoffGuard <- struct{}{} }()
+
+	return State{
+		Current:  ebm.currentBackOff,
+		IsMin:    ebm.currentBackOff == ebm.minBackOff,
+		IsMax:    ebm.currentBackOff == ebm.maxBackOff,
+		Attempts: ebm.attempts,
+		Changed:  ebm.changedAt,
+	}
+}
+
+// Attempts returns how many Waits have been made since the backoff was last reset.
+func (ebm *ExpoBackoffManager) Attempts() int {
+	return ebm.State().Attempts
+}
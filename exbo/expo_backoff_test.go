@@ -1,7 +1,9 @@
 package exbo
 
 import (
+	"errors"
 	"log"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -44,7 +46,7 @@ func TestNew(t *testing.T) {
 	}
 
 	_, err := NewExpoBackoffManager(badOpts)
-	if err == nil {
+	if !errors.Is(err, ErrIncoherentOpts) {
 		t.Errorf("Bad opts were excepted")
 	}
 
@@ -54,6 +56,252 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestGrowth(t *testing.T) {
+	for _, bad := range []Opts{{Min: time.Second, Max: time.Minute, Factor: 0.5}, {Min: time.Second, Max: time.Minute, Step: -time.Second}} {
+		if _, err := NewExpoBackoffManager(bad); !errors.Is(err, ErrIncoherentGrowth) {
+			t.Errorf("Shrinking growth was accepted: %+v", bad)
+		}
+	}
+
+	cases := []struct {
+		opts Opts
+		want []time.Duration
+	}{
+		{Opts{Min: time.Second, Max: time.Minute}, []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}},
+		{Opts{Min: time.Second, Max: time.Minute, Factor: 1.5}, []time.Duration{1500 * time.Millisecond, 2250 * time.Millisecond}},
+		{Opts{Min: time.Second, Max: 10 * time.Second, Factor: 3}, []time.Duration{3 * time.Second, 9 * time.Second, 10 * time.Second}},
+		{Opts{Min: time.Second, Max: time.Minute, Factor: 1, Step: time.Second}, []time.Duration{2 * time.Second, 3 * time.Second, 4 * time.Second}},
+	}
+
+	for _, c := range cases {
+		ex, err := NewExpoBackoffManager(c.opts)
+		if err != nil {
+			t.Fatalf("Good opts were rejected: %+v", c.opts)
+		}
+
+		current := c.opts.Min
+		for _, want := range c.want {
+			if current = ex.grow(current); current != want {
+				t.Errorf("Expected %+v to grow to %s, found %s", c.opts, want, current)
+			}
+		}
+	}
+}
+
+func TestOutcomes(t *testing.T) {
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          time.Microsecond,
+		Max:          time.Millisecond,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Microsecond,
+		Outcomes:     true,
+	})
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+	defer ex.Stop()
+
+	for i := 0; i < 5; i++ {
+		if err := ex.Wait(); err != nil {
+			t.Fatalf("Unexpected error in Wait: %s", err.Error())
+		}
+	}
+
+	if current, isMin, _ := ex.CurrentWaitTime(); !isMin {
+		t.Errorf("Expected Wait to leave the backoff at min, found %s", current)
+	}
+
+	ex.Failure()
+	ex.Failure()
+	if current, _, _ := ex.CurrentWaitTime(); current != 4*time.Microsecond {
+		t.Errorf("Expected two failures to grow the backoff to 4µs, found %s", current)
+	}
+
+	ex.Success()
+	if current, isMin, _ := ex.CurrentWaitTime(); !isMin {
+		t.Errorf("Expected success to reset the backoff, found %s", current)
+	}
+}
+
+func TestState(t *testing.T) {
+	before := time.Now()
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          time.Microsecond,
+		Max:          4 * time.Microsecond,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Microsecond,
+	})
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+	defer ex.Stop()
+
+	state := ex.State()
+	if state.Attempts != 0 || !state.IsMin || state.IsMax || state.Changed.Before(before) {
+		t.Errorf("Unexpected state of a new manager: %+v", state)
+	}
+
+	for i := 0; i < 3; i++ {
+		ex.Wait()
+	}
+
+	state = ex.State()
+	if state.Attempts != 3 || ex.Attempts() != 3 {
+		t.Errorf("Expected 3 attempts, found %d", state.Attempts)
+	}
+
+	if state.Current != 4*time.Microsecond || !state.IsMax || state.IsMin {
+		t.Errorf("Expected the backoff at max, found %+v", state)
+	}
+
+	ex.Success()
+	if state := ex.State(); state.Attempts != 0 || !state.IsMin {
+		t.Errorf("Expected success to reset the attempts, found %+v", state)
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	type change struct {
+		old, new time.Duration
+		reason   Reason
+	}
+
+	var mu sync.Mutex
+	changes := []change{}
+
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          time.Microsecond,
+		Max:          2 * time.Microsecond,
+		CooldownTick: 20 * time.Millisecond,
+		CooldownSize: time.Microsecond,
+		OnChange: func(old, new time.Duration, reason Reason) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, change{old, new, reason})
+		},
+	})
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+	defer ex.Stop()
+
+	ex.Wait()
+	for _, isMin, _ := ex.CurrentWaitTime(); !isMin; _, isMin, _ = ex.CurrentWaitTime() {
+		time.Sleep(time.Millisecond)
+	}
+	ex.Failure()
+	ex.Success()
+
+	want := []change{
+		{time.Microsecond, 2 * time.Microsecond, ReasonGrowth},
+		{2 * time.Microsecond, time.Microsecond, ReasonCooldown},
+		{time.Microsecond, 2 * time.Microsecond, ReasonGrowth},
+		{2 * time.Microsecond, time.Microsecond, ReasonReset},
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, found %+v", len(want), changes)
+	}
+
+	for k := range want {
+		if changes[k] != want[k] {
+			t.Errorf("Expected change %d to be %+v, found %+v", k, want[k], changes[k])
+		}
+	}
+
+	if ReasonCooldown.String() != "cooldown" {
+		t.Errorf("Unexpected reason name")
+	}
+}
+
+func TestAllow(t *testing.T) {
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          100 * time.Millisecond,
+		Max:          time.Hour,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+
+	if !ex.Allow() {
+		t.Fatalf("Expected the first attempt allowed")
+	}
+
+	if ex.Allow() {
+		t.Errorf("Expected an attempt within the backoff refused")
+	}
+
+	// The backoff grew to 200ms with the first attempt.
+	time.Sleep(120 * time.Millisecond)
+	if ex.Allow() {
+		t.Errorf("Expected an attempt within the grown backoff refused")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if !ex.Allow() {
+		t.Errorf("Expected an attempt after the backoff allowed")
+	}
+
+	if attempts := ex.Attempts(); attempts != 2 {
+		t.Errorf("Expected 2 attempts recorded, found %d", attempts)
+	}
+
+	ex.Stop()
+	time.Sleep(500 * time.Millisecond)
+	if ex.Allow() {
+		t.Errorf("Expected no attempts allowed once stopped")
+	}
+}
+
+func TestWaitN(t *testing.T) {
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          10 * time.Millisecond,
+		Max:          time.Second,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+	defer ex.Stop()
+
+	start := time.Now()
+	if err := ex.WaitN(5); err != nil {
+		t.Fatalf("Unexpected error in WaitN: %s", err.Error())
+	}
+
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("Expected a batch of 5 to wait 5 backoffs, waited %s", waited)
+	}
+
+	if state := ex.State(); state.Current != 320*time.Millisecond || state.Attempts != 5 {
+		t.Errorf("Expected the backoff grown as by 5 Waits, found %+v", state)
+	}
+
+	if weigh(time.Hour, math.MaxInt32) != math.MaxInt64 {
+		t.Errorf("Expected an overlong batch to wait as long as can be")
+	}
+}
+
 func TestWait(t *testing.T) {
 	ex, err := NewExpoBackoffManager(testFastOpts)
 	if err != nil {
@@ -98,7 +346,7 @@ func TestWaitAndStop(t *testing.T) {
 		defer close(x)
 		x <- struct{}{}
 		err := ex.Wait()
-		if err == nil {
+		if !errors.Is(err, ErrHalted) {
 			t.Errorf("Did not hear forced error")
 		}
 	}()
@@ -108,6 +356,87 @@ func TestWaitAndStop(t *testing.T) {
 	<-x
 }
 
+func TestStopReleasesWaiters(t *testing.T) {
+	ex, err := NewExpoBackoffManager(testSlowOpts)
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+
+	const waiters = 10
+	var released sync.WaitGroup
+	released.Add(waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			defer released.Done()
+			if err := ex.Wait(); !errors.Is(err, ErrHalted) {
+				t.Errorf("Expected ErrHalted, found %v", err)
+			}
+		}()
+	}
+
+	for {
+		ex.waitMu.Lock()
+		n := ex.waiters
+		ex.waitMu.Unlock()
+		if n == waiters {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		ex.StopAndWait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected StopAndWait to return once the waiters were released")
+	}
+
+	// By now every Wait has returned.
+	released.Wait()
+
+	ex.Stop()
+	ex.StopAndWait()
+	if err := ex.Wait(); !errors.Is(err, ErrHalted) {
+		t.Errorf("Expected Wait after Stop to be halted, found %v", err)
+	}
+}
+
+func TestRunLifecycle(t *testing.T) {
+	ex, err := NewExpoBackoffManager(testSlowOpts)
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	exited := make(chan error)
+	go func() { exited <- ex.Run() }()
+
+	// Ready is closed, so every observer hears it.
+	<-ex.Ready
+	<-ex.Ready
+
+	if err := ex.Run(); !errors.Is(err, ErrAlreadyRun) {
+		t.Errorf("Expected a second Run refused, found %v", err)
+	}
+
+	ex.Stop()
+	select {
+	case err := <-exited:
+		if !errors.Is(err, ErrHalted) {
+			t.Errorf("Expected Run to return ErrHalted once stopped, found %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Run to return once stopped")
+	}
+}
+
 func TestIncreaseBackoff(t *testing.T) {
 	ex, err := NewExpoBackoffManager(testUpOpts)
 	if err != nil {
@@ -161,8 +490,29 @@ func TestIncreaseBackoff(t *testing.T) {
 	ex.Stop()
 }
 
+// awaitWaitTime waits for the backoff of ex to reach want, advancing clock a second at a time if
+// given one, and reports whether it did.
+func awaitWaitTime(ex *ExpoBackoffManager, clock *ManualClock, want time.Duration) bool {
+	for k := 0; k < 1000; k++ {
+		if current, _, _ := ex.CurrentWaitTime(); current == want {
+			return true
+		}
+
+		if clock != nil {
+			clock.Advance(time.Second)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	return false
+}
+
 func TestCooldown(t *testing.T) {
-	ex, err := NewExpoBackoffManager(testDownOpts)
+	clock := NewManualClock(time.Now())
+	opts := testDownOpts
+	opts.Clock = clock
+
+	ex, err := NewExpoBackoffManager(opts)
 	if err != nil {
 		t.Errorf("Good opts were rejected")
 	}
@@ -194,6 +544,7 @@ func TestCooldown(t *testing.T) {
 	}
 
 	wg.Wait()
+	awaitWaitTime(ex, nil, testDownOpts.Max)
 
 	current, isMin, isMax = ex.CurrentWaitTime()
 	if current != testDownOpts.Max {
@@ -208,8 +559,8 @@ func TestCooldown(t *testing.T) {
 		t.Errorf("isMin boolean is incorrectly set to true")
 	}
 
-	log.Println("About to sleep for 7 seconds to allow a 6s timer to run...")
-	time.Sleep(time.Second * 7)
+	// Two 3s ticks cool it 10s, to min.
+	awaitWaitTime(ex, clock, testDownOpts.Min)
 
 	current, isMin, isMax = ex.CurrentWaitTime()
 	if current != testDownOpts.Min {
@@ -226,3 +577,98 @@ func TestCooldown(t *testing.T) {
 
 	ex.Stop()
 }
+
+func TestHalfLife(t *testing.T) {
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          time.Second,
+		Max:          time.Hour,
+		CooldownTick: time.Minute,
+		CooldownSize: time.Second,
+		HalfLife:     time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	current := time.Hour + time.Second
+	want := []time.Duration{
+		30*time.Minute + time.Second,
+		15*time.Minute + time.Second,
+		7*time.Minute + 30*time.Second + time.Second,
+	}
+	for _, w := range want {
+		if current = ex.cool(current); current != w {
+			t.Errorf("Expected the excess halved each half-life, to %s, found %s", w, current)
+		}
+	}
+
+	if next := ex.cool(2500 * time.Millisecond); next != time.Second {
+		t.Errorf("Expected the backoff to reach min once within CooldownSize of it, found %s", next)
+	}
+
+	linear, _ := NewExpoBackoffManager(testDownOpts)
+	if next := linear.cool(10 * time.Second); next != 5*time.Second {
+		t.Errorf("Expected the backoff cooled by CooldownSize without a half-life, found %s", next)
+	}
+}
+
+func TestIdle(t *testing.T) {
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          time.Millisecond,
+		Max:          time.Second,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Millisecond,
+		Outcomes:     true,
+		Idle:         100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+	defer ex.Stop()
+
+	ex.Failure()
+	ex.Failure()
+	ex.Failure()
+
+	// Retrying more often than Idle, the backoff stays up.
+	for i := 0; i < 5; i++ {
+		ex.Wait()
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	if current, _, _ := ex.CurrentWaitTime(); current != 8*time.Millisecond {
+		t.Errorf("Expected an active manager to stay backed off, found %s", current)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if current, isMin, _ := ex.CurrentWaitTime(); !isMin {
+		t.Errorf("Expected an idle manager to return to min, found %s", current)
+	}
+}
+
+func BenchmarkWait(b *testing.B) {
+	ex, err := NewExpoBackoffManager(Opts{
+		Min:          time.Nanosecond,
+		Max:          time.Nanosecond,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Nanosecond,
+	})
+	if err != nil {
+		b.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ex.Wait()
+	}
+	b.StopTimer()
+
+	ex.Stop()
+}
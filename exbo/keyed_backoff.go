@@ -0,0 +1,165 @@
+package exbo
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultMaxKeys is how many keys a KeyedBackoff keeps when Opts.MaxKeys is not positive.
+const defaultMaxKeys = 1024
+
+// KeyedBackoff keeps an independent ExpoBackoffManager per key, a host, shard or tenant say, so
+// one misbehaving endpoint doesn't slow the healthy ones. Once it holds more than Opts.MaxKeys keys
+// the least recently used idle one is stopped and forgotten, to start again from Min if used again.
+type KeyedBackoff struct {
+	opts    Opts
+	maxKeys int
+
+	mu      sync.Mutex
+	keys    map[string]*list.Element
+	lru     *list.List // Of *keyedManager, most recently used first.
+	stopped bool
+}
+
+type keyedManager struct {
+	key   string
+	ebm   *ExpoBackoffManager
+	users int
+}
+
+// NewKeyedBackoff returns a KeyedBackoff building each key's manager from opts, or
+// NewExpoBackoffManager's error if opts is incoherent.
+func NewKeyedBackoff(opts Opts) (*KeyedBackoff, error) {
+	// Every key would share it.
+	opts.StateFile = ""
+
+	if _, err := NewExpoBackoffManager(opts); err != nil {
+		return nil, err
+	}
+
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultMaxKeys
+	}
+
+	return &KeyedBackoff{
+		opts:    opts,
+		maxKeys: maxKeys,
+		keys:    map[string]*list.Element{},
+		lru:     list.New(),
+	}, nil
+}
+
+// acquire returns the manager of key, running a new one if it has none, marked most recently used
+// and busy until release. It returns nil once the KeyedBackoff is stopped.
+func (kb *KeyedBackoff) acquire(key string) *keyedManager {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	if kb.stopped {
+		return nil
+	}
+
+	if el, ok := kb.keys[key]; ok {
+		kb.lru.MoveToFront(el)
+		m := el.Value.(*keyedManager)
+		m.users++
+		return m
+	}
+
+	// The opts were checked by NewKeyedBackoff.
+	ebm, _ := NewExpoBackoffManager(kb.opts)
+	go ebm.Run()
+	<-ebm.Ready
+
+	m := &keyedManager{key: key, ebm: ebm, users: 1}
+	kb.keys[key] = kb.lru.PushFront(m)
+	kb.evict()
+
+	return m
+}
+
+func (kb *KeyedBackoff) release(m *keyedManager) {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+	m.users--
+	kb.evict()
+}
+
+// evict stops the least recently used idle managers until no more than maxKeys remain. Managers
+// in use, being waited on say, are kept, even if that leaves more. The caller must hold mu.
+func (kb *KeyedBackoff) evict() {
+	for el := kb.lru.Back(); el != nil && kb.lru.Len() > kb.maxKeys; {
+		prev := el.Prev()
+		if m := el.Value.(*keyedManager); m.users == 0 {
+			kb.lru.Remove(el)
+			delete(kb.keys, m.key)
+			m.ebm.Stop()
+		}
+		el = prev
+	}
+}
+
+// Wait waits out the backoff of key, as ExpoBackoffManager.Wait.
+func (kb *KeyedBackoff) Wait(key string) error {
+	m := kb.acquire(key)
+	if m == nil {
+		return ErrHalted
+	}
+	defer kb.release(m)
+
+	return m.ebm.Wait()
+}
+
+// Failure records a failed attempt against key, as ExpoBackoffManager.Failure.
+func (kb *KeyedBackoff) Failure(key string) {
+	if m := kb.acquire(key); m != nil {
+		m.ebm.Failure()
+		kb.release(m)
+	}
+}
+
+// Success records a successful attempt against key, as ExpoBackoffManager.Success.
+func (kb *KeyedBackoff) Success(key string) {
+	if m := kb.acquire(key); m != nil {
+		m.ebm.Success()
+		kb.release(m)
+	}
+}
+
+// State returns the backoff of key, that of a new manager if the key is not kept.
+func (kb *KeyedBackoff) State(key string) State {
+	kb.mu.Lock()
+	el, ok := kb.keys[key]
+	kb.mu.Unlock()
+
+	if !ok {
+		return State{Current: kb.opts.Min, IsMin: true, IsMax: kb.opts.Min == kb.opts.Max}
+	}
+
+	return el.Value.(*keyedManager).ebm.State()
+}
+
+// Len returns how many keys are kept.
+func (kb *KeyedBackoff) Len() int {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+	return kb.lru.Len()
+}
+
+// Stop stops every key's manager, their Waits returning ErrHalted, as do any made after.
+func (kb *KeyedBackoff) Stop() {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	if kb.stopped {
+		return
+	}
+	kb.stopped = true
+
+	for el := kb.lru.Front(); el != nil; el = el.Next() {
+		el.Value.(*keyedManager).ebm.Stop()
+	}
+	kb.keys = map[string]*list.Element{}
+	kb.lru.Init()
+}
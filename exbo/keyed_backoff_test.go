@@ -0,0 +1,91 @@
+package exbo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var testKeyedOpts = Opts{
+	Min:          time.Microsecond,
+	Max:          time.Millisecond,
+	CooldownTick: time.Hour,
+	CooldownSize: time.Microsecond,
+	Outcomes:     true,
+	MaxKeys:      2,
+}
+
+func TestKeyedBackoff(t *testing.T) {
+	if _, err := NewKeyedBackoff(Opts{Min: time.Hour, Max: time.Second}); !errors.Is(err, ErrIncoherentOpts) {
+		t.Errorf("Bad opts were excepted")
+	}
+
+	kb, err := NewKeyedBackoff(testKeyedOpts)
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+	defer kb.Stop()
+
+	kb.Failure("sick")
+	kb.Failure("sick")
+	if err := kb.Wait("healthy"); err != nil {
+		t.Fatalf("Unexpected error in Wait: %s", err.Error())
+	}
+
+	if state := kb.State("sick"); state.Current != 4*time.Microsecond {
+		t.Errorf("Expected the failing key backed off to 4µs, found %s", state.Current)
+	}
+
+	if state := kb.State("healthy"); !state.IsMin {
+		t.Errorf("Expected the healthy key left at min, found %s", state.Current)
+	}
+
+	// "sick" is now the least recently used, and is forgotten.
+	kb.Success("third")
+	if kb.Len() != 2 {
+		t.Errorf("Expected 2 keys kept, found %d", kb.Len())
+	}
+
+	if state := kb.State("sick"); !state.IsMin {
+		t.Errorf("Expected the evicted key to start again from min, found %s", state.Current)
+	}
+}
+
+func TestKeyedBackoffKeepsBusyKeys(t *testing.T) {
+	opts := testKeyedOpts
+	opts.Min = time.Hour
+	opts.Max = time.Hour
+	opts.MaxKeys = 1
+
+	kb, err := NewKeyedBackoff(opts)
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	waited := make(chan error)
+	go func() { waited <- kb.Wait("busy") }()
+
+	for kb.Len() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	kb.Failure("other")
+	if kb.Len() != 1 {
+		t.Errorf("Expected 1 key kept, found %d", kb.Len())
+	}
+
+	select {
+	case err := <-waited:
+		t.Fatalf("Expected the waited on key kept, its Wait returned %v", err)
+	default:
+	}
+
+	kb.Stop()
+	if err := <-waited; !errors.Is(err, ErrHalted) {
+		t.Errorf("Expected the waiter halted, found %v", err)
+	}
+
+	if err := kb.Wait("busy"); !errors.Is(err, ErrHalted) {
+		t.Errorf("Expected Wait after Stop to be halted, found %v", err)
+	}
+}
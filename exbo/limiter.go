@@ -0,0 +1,29 @@
+package exbo
+
+import "context"
+
+// Limiter is the Wait and Allow of golang.org/x/time/rate's Limiter, the shape middleware
+// accepting a limiter usually asks for.
+type Limiter interface {
+	Wait(ctx context.Context) error
+	Allow() bool
+}
+
+// limiter adapts an ExpoBackoffManager to Limiter.
+type limiter struct {
+	ebm *ExpoBackoffManager
+}
+
+// Limiter returns the manager as a Limiter, its Wait returning ctx's error if it is done before
+// the backoff is, and its Allow the manager's.
+func (ebm *ExpoBackoffManager) Limiter() Limiter {
+	return limiter{ebm: ebm}
+}
+
+func (l limiter) Wait(ctx context.Context) error {
+	return l.ebm.wait(ctx, 1)
+}
+
+func (l limiter) Allow() bool {
+	return l.ebm.Allow()
+}
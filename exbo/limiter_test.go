@@ -0,0 +1,43 @@
+package exbo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	ex := runRetry(t, Opts{
+		Min:          time.Microsecond,
+		Max:          time.Hour,
+		CooldownTick: 2 * time.Hour,
+		CooldownSize: time.Microsecond,
+		Factor:       1,
+	})
+
+	var l Limiter = ex.Limiter()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Unexpected error in Wait: %s", err.Error())
+	}
+
+	time.Sleep(time.Millisecond)
+	if !l.Allow() {
+		t.Errorf("Expected an attempt after the backoff allowed")
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	ex := runRetry(t, testSlowOpts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := ex.Limiter().Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context's error, found %v", err)
+	}
+
+	if ex.Limiter().Allow() {
+		t.Errorf("Expected an attempt within the backoff refused")
+	}
+}
@@ -0,0 +1,96 @@
+package exbo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrMaxAttempts is returned, wrapping the last failure, by Do once it has called its function
+// Opts.MaxAttempts times without success.
+var ErrMaxAttempts = errors.New("exbo gave up retrying after the maximum number of attempts")
+
+// RetryOption configures a call of Do.
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	retryIf func(error) bool
+}
+
+// WithRetryIf has Do retry only the errors retryIf reports true for, returning any other at once.
+func WithRetryIf(retryIf func(error) bool) RetryOption {
+	return func(c *retryConfig) {
+		c.retryIf = retryIf
+	}
+}
+
+// permanentError marks an error Do should not retry.
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+
+func (p *permanentError) Unwrap() error { return p.err }
+
+// Permanent wraps err so Do returns it, unwrapped, at once rather than retrying. It returns nil
+// if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// Do calls f until it succeeds, waiting out the backoff after each failure. It gives up once ctx
+// is done, returning ctx's error, after MaxAttempts, returning ErrMaxAttempts, or once the manager
+// is stopped, returning ErrHalted, each wrapping f's last error. Errors wrapped by Permanent, or
+// rejected by WithRetryIf, are returned at once. With Outcomes it reports each attempt to Failure
+// and Success, otherwise the backoff grows with every Wait as usual.
+func (ebm *ExpoBackoffManager) Do(ctx context.Context, f func() error, opts ...RetryOption) error {
+	c := retryConfig{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(lastErr, err)
+		}
+
+		err := f()
+		if err == nil {
+			if ebm.outcomes {
+				ebm.Success()
+			}
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		if c.retryIf != nil && !c.retryIf(err) {
+			return err
+		}
+		lastErr = err
+
+		if ebm.maxAttempts > 0 && attempt >= ebm.maxAttempts {
+			if ebm.outcomes {
+				ebm.Failure()
+			}
+			return fmt.Errorf("%w: %w", ErrMaxAttempts, err)
+		}
+
+		if werr := ebm.wait(ctx, 1); werr != nil {
+			return fmt.Errorf("%w: %w", werr, err)
+		}
+
+		if ebm.outcomes {
+			ebm.Failure()
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package exbo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("flaky")
+
+func runRetry(t *testing.T, opts Opts) *ExpoBackoffManager {
+	t.Helper()
+
+	ex, err := NewExpoBackoffManager(opts)
+	if err != nil {
+		t.Fatalf("Good opts were rejected")
+	}
+
+	go ex.Run()
+	<-ex.Ready
+	t.Cleanup(ex.Stop)
+
+	return ex
+}
+
+func TestDo(t *testing.T) {
+	ex := runRetry(t, Opts{
+		Min:          time.Microsecond,
+		Max:          time.Millisecond,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Microsecond,
+		Outcomes:     true,
+	})
+
+	calls := 0
+	err := ex.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return errFlaky
+		}
+		return nil
+	})
+
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third call, found %v after %d", err, calls)
+	}
+
+	if state := ex.State(); !state.IsMin || state.Attempts != 0 {
+		t.Errorf("Expected success to reset the backoff, found %+v", state)
+	}
+}
+
+func TestDoMaxAttempts(t *testing.T) {
+	ex := runRetry(t, Opts{
+		Min:          time.Microsecond,
+		Max:          time.Millisecond,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Microsecond,
+		MaxAttempts:  4,
+	})
+
+	calls := 0
+	err := ex.Do(context.Background(), func() error {
+		calls++
+		return errFlaky
+	})
+
+	if !errors.Is(err, ErrMaxAttempts) || !errors.Is(err, errFlaky) {
+		t.Errorf("Expected ErrMaxAttempts wrapping the last failure, found %v", err)
+	}
+
+	if calls != 4 {
+		t.Errorf("Expected 4 calls, found %d", calls)
+	}
+}
+
+func TestDoRetryIf(t *testing.T) {
+	ex := runRetry(t, Opts{
+		Min:          time.Microsecond,
+		Max:          time.Millisecond,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Microsecond,
+	})
+
+	errFatal := errors.New("fatal")
+
+	calls := 0
+	err := ex.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return errFlaky
+		}
+		return errFatal
+	}, WithRetryIf(func(err error) bool { return errors.Is(err, errFlaky) }))
+
+	if err != errFatal || calls != 3 {
+		t.Errorf("Expected the fatal error returned on the third call, found %v after %d", err, calls)
+	}
+
+	calls = 0
+	err = ex.Do(context.Background(), func() error {
+		calls++
+		return Permanent(errFatal)
+	})
+
+	if err != errFatal || calls != 1 {
+		t.Errorf("Expected the permanent error unwrapped on the first call, found %v after %d", err, calls)
+	}
+
+	if Permanent(nil) != nil {
+		t.Errorf("Expected no permanent error of nil")
+	}
+}
+
+func TestDoCancel(t *testing.T) {
+	ex := runRetry(t, testSlowOpts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- ex.Do(ctx, func() error { return errFlaky })
+	}()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the context's error, found %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Do to return promptly once cancelled")
+	}
+
+	if err := ex.Do(ctx, func() error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Do on a done context not to call, found %v", err)
+	}
+
+	// Cancelled between attempts, Do returns the last failure along with the context's error.
+	ctx, cancel = context.WithCancel(context.Background())
+	err := ex.Do(ctx, func() error {
+		cancel()
+		return errFlaky
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errFlaky) {
+		t.Errorf("Expected both the context's error and the last failure, found %v", err)
+	}
+}
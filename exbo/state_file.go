@@ -0,0 +1,95 @@
+package exbo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshot is the backoff state kept in Opts.StateFile.
+type snapshot struct {
+	Current     time.Duration `json:"current"`
+	Attempts    int           `json:"attempts"`
+	Changed     time.Time     `json:"changed"`
+	LastAttempt time.Time     `json:"last_attempt"`
+}
+
+// restore loads the backoff state from the manager's state file, if it has one and it exists,
+// keeping the backoff within Min and Max should they have changed since.
+func (ebm *ExpoBackoffManager) restore() error {
+	if ebm.stateFile == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(ebm.stateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading exbo state file: %w", err)
+	}
+
+	var s snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("reading exbo state file %s: %w", ebm.stateFile, err)
+	}
+
+	ebm.currentBackOff = min(max(s.Current, ebm.minBackOff), ebm.maxBackOff)
+	ebm.attempts = s.Attempts
+	ebm.changedAt = s.Changed
+	ebm.lastAttempt = s.LastAttempt
+	return nil
+}
+
+// persist writes the backoff state to the manager's state file, if it has one, replacing it whole
+// so a crash mid-write leaves the last state. A failure is logged, the backoff carrying on.
+func (ebm *ExpoBackoffManager) persist() {
+	if ebm.stateFile == "" {
+		return
+	}
+
+	ebm.persistMu.Lock()
+	defer ebm.persistMu.Unlock()
+
+	<-ebm.backoffGuard
+	s := snapshot{
+		Current:     ebm.currentBackOff,
+		Attempts:    ebm.attempts,
+		Changed:     ebm.changedAt,
+		LastAttempt: ebm.lastAttempt,
+	}
+	ebm.backoffGuard <- struct{}{}
+
+	if err := writeSnapshot(ebm.stateFile, s); err != nil {
+		log.Printf("exbo could not write its state file: %s", err)
+	}
+}
+
+func writeSnapshot(path string, s snapshot) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,62 @@
+package exbo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backoff.json")
+	opts := Opts{
+		Min:          time.Microsecond,
+		Max:          time.Millisecond,
+		CooldownTick: time.Hour,
+		CooldownSize: time.Microsecond,
+		StateFile:    path,
+	}
+
+	first := runRetry(t, opts)
+	first.Wait()
+	first.Wait()
+	first.Failure()
+
+	second, err := NewExpoBackoffManager(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the state file: %s", err.Error())
+	}
+
+	want := first.State()
+	if got := second.State(); got.Current != 8*time.Microsecond || got.Attempts != 2 || !got.Changed.Equal(want.Changed) {
+		t.Errorf("Expected the state carried over as %+v, found %+v", want, got)
+	}
+
+	opts.Max = 2 * time.Microsecond
+	third, err := NewExpoBackoffManager(opts)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the state file: %s", err.Error())
+	}
+
+	if current, _, isMax := third.CurrentWaitTime(); !isMax {
+		t.Errorf("Expected the loaded backoff kept within a lowered max, found %s", current)
+	}
+}
+
+func TestStateFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	opts := Opts{Min: time.Second, Max: time.Minute, StateFile: filepath.Join(dir, "missing.json")}
+
+	if _, err := NewExpoBackoffManager(opts); err != nil {
+		t.Errorf("Expected a missing state file to start afresh, found %s", err.Error())
+	}
+
+	opts.StateFile = filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(opts.StateFile, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if ex, err := NewExpoBackoffManager(opts); err == nil || ex != nil {
+		t.Errorf("Expected a corrupt state file rejected")
+	}
+}